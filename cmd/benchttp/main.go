@@ -6,18 +6,29 @@ import (
 	"flag"
 	"fmt"
 	"os"
+
+	"github.com/benchttp/runner/output"
 )
 
 // errUsage reports an incorrect usage of the benchttp command.
 var errUsage = errors.New("usage")
 
+// Exit codes of the benchttp command.
+const (
+	exitError            = 1
+	exitThresholdsFailed = 3
+)
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Println(err)
 		if errors.Is(err, errUsage) {
 			flag.Usage()
 		}
-		os.Exit(1)
+		if errors.Is(err, output.ErrThresholdsFailed) {
+			os.Exit(exitThresholdsFailed)
+		}
+		os.Exit(exitError)
 	}
 }
 
@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
+	"fmt"
 
 	"github.com/benchttp/runner/config"
 	"github.com/benchttp/runner/internal/auth"
 	"github.com/benchttp/runner/internal/configfile"
 	"github.com/benchttp/runner/internal/configflags"
+	"github.com/benchttp/runner/internal/datafile"
 	"github.com/benchttp/runner/internal/signals"
 	"github.com/benchttp/runner/output"
 	"github.com/benchttp/runner/requester"
@@ -53,8 +56,14 @@ func (cmd *cmdRun) execute(args []string) error {
 		return err
 	}
 
-	// Retrieve HTTP request for the benchmark generated by the config
-	req, err := cfg.Request.Value()
+	// Retrieve HTTP requests for the benchmark generated by the config
+	endpoints, err := requesterEndpoints(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Load the test data fed to the requests
+	data, err := requesterData(cfg.Data)
 	if err != nil {
 		return err
 	}
@@ -73,7 +82,7 @@ func (cmd *cmdRun) execute(args []string) error {
 	go signals.ListenOSInterrupt(cancel)
 
 	// Run the benchmark
-	ben, err := requester.New(cmd.requesterConfig(cfg)).Run(ctx, req)
+	ben, err := requester.New(cmd.requesterConfig(cfg, data)).RunMix(ctx, endpoints)
 	if err != nil {
 		if errors.Is(err, requester.ErrCanceled) {
 			// context canceled: handle the case of os.Interrupt
@@ -140,18 +149,226 @@ func (cmd *cmdRun) makeConfig(fields []string) (cfg config.Global, err error) {
 	return mergedConfig, mergedConfig.Validate()
 }
 
-// requesterConfig returns a requester.Config generated from cfg.
-func (*cmdRun) requesterConfig(cfg config.Global) requester.Config {
+// requesterConfig returns a requester.Config generated from cfg
+// and the loaded test data.
+func (*cmdRun) requesterConfig(cfg config.Global, data requester.Data) requester.Config {
 	return requester.Config{
 		Requests:       cfg.Runner.Requests,
 		Concurrency:    cfg.Runner.Concurrency,
-		Interval:       cfg.Runner.Interval,
+		Rate:           cfg.Runner.Rate,
+		Stages:         requesterStages(cfg.Runner.Stages),
+		Interval:       requester.Interval(cfg.Runner.Interval),
 		RequestTimeout: cfg.Runner.RequestTimeout,
 		GlobalTimeout:  cfg.Runner.GlobalTimeout,
+		Drain:          cfg.Runner.Drain,
+		Warmup:         requester.Warmup(cfg.Runner.Warmup),
+		BucketInterval: cfg.Runner.BucketInterval,
+		Preflight:      requester.Preflight(cfg.Runner.Preflight),
+		Connections:    requester.Connections(cfg.Runner.Connections),
+		Records:        requester.Records(cfg.Runner.Records),
+		Checks:         requesterChecks(cfg.Checks),
+		Seed:           cfg.Runner.Seed,
+		Data:           data,
 		Silent:         cfg.Output.Silent,
 	}
 }
 
+// requesterData returns a requester.Data holding the rows read from
+// data.File, or a zero requester.Data if no file is set.
+func requesterData(data config.Data) (requester.Data, error) {
+	if data.File == "" {
+		return requester.Data{}, nil
+	}
+	rows, err := datafile.Read(data.File)
+	if err != nil {
+		return requester.Data{}, err
+	}
+	return requester.Data{
+		Rows:  rows,
+		Mode:  data.Mode,
+		OnEnd: data.OnEnd,
+	}, nil
+}
+
+// requesterStages returns a slice of requester.Stage generated from stages.
+func requesterStages(stages []config.Stage) []requester.Stage {
+	if len(stages) == 0 {
+		return nil
+	}
+	out := make([]requester.Stage, len(stages))
+	for i, s := range stages {
+		out[i] = requester.Stage{
+			Duration:    s.Duration,
+			Concurrency: s.Concurrency,
+			Rate:        s.Rate,
+		}
+	}
+	return out
+}
+
+// requesterChecks returns a slice of requester.Check generated from checks.
+func requesterChecks(checks []config.Check) []requester.Check {
+	if len(checks) == 0 {
+		return nil
+	}
+	out := make([]requester.Check, len(checks))
+	for i, c := range checks {
+		out[i] = requester.Check{
+			Name:  c.String(),
+			Kind:  c.Kind,
+			Key:   c.Key,
+			Value: c.Value,
+		}
+	}
+	return out
+}
+
+// requesterEndpoints returns the endpoints run by the requester for cfg:
+// the weighted requests of cfg.Endpoints if set, else a single endpoint
+// running the steps of cfg.Scenario if set, or sending cfg.Request.
+func requesterEndpoints(cfg config.Global) ([]requester.Endpoint, error) {
+	conns := connOptions{
+		tls:     map[string]*tls.Config{},
+		proxies: map[string]*requester.Proxy{},
+		dns:     map[string]*requester.DNS{},
+	}
+
+	if len(cfg.Endpoints) > 0 {
+		endpoints := make([]requester.Endpoint, len(cfg.Endpoints))
+		for i, e := range cfg.Endpoints {
+			step, err := requesterStep(e.Request, conns)
+			if err != nil {
+				return nil, err
+			}
+			endpoints[i] = requester.Endpoint{
+				Name:   e.Name,
+				Weight: e.Weight,
+				Steps:  []requester.Step{step},
+			}
+		}
+		return endpoints, nil
+	}
+
+	if len(cfg.Scenario) == 0 {
+		step, err := requesterStep(cfg.Request, conns)
+		if err != nil {
+			return nil, err
+		}
+		return []requester.Endpoint{{Weight: 1, Steps: []requester.Step{step}}}, nil
+	}
+
+	steps := make([]requester.Step, len(cfg.Scenario))
+	for i, s := range cfg.Scenario {
+		step, err := requesterStep(s.Request, conns)
+		if err != nil {
+			return nil, err
+		}
+		step.Name = s.Name
+		step.Checks = requesterChecks(s.Checks)
+		step.Extract = requesterExtract(s.Extract)
+		steps[i] = step
+	}
+	return []requester.Endpoint{{Weight: 1, Steps: steps}}, nil
+}
+
+// requesterStep returns a requester.Step sending the request r,
+// its connection options being retrieved from conns.
+func requesterStep(r config.Request, conns connOptions) (requester.Step, error) {
+	req, err := r.Value()
+	if err != nil {
+		return requester.Step{}, err
+	}
+	tlsConfig, err := conns.tlsConfig(r.TLS)
+	if err != nil {
+		return requester.Step{}, err
+	}
+	proxy, err := conns.proxy(r.Proxy)
+	if err != nil {
+		return requester.Step{}, err
+	}
+	return requester.Step{
+		Request:    req,
+		Protocol:   r.Protocol,
+		TLS:        tlsConfig,
+		Proxy:      proxy,
+		DNS:        conns.dnsOptions(r.Resolve, r.DNS),
+		UnixSocket: r.UnixSocket,
+		StreamBody: r.Body.Type == config.BodyFile || r.Body.Type == config.BodyMultipart,
+	}, nil
+}
+
+// connOptions caches the connection options generated for the requests,
+// so that requests with identical options share their connections.
+type connOptions struct {
+	tls     map[string]*tls.Config
+	proxies map[string]*requester.Proxy
+	dns     map[string]*requester.DNS
+}
+
+// tlsConfig returns the *tls.Config generated for t, generating it
+// if needed.
+func (c connOptions) tlsConfig(t config.TLS) (*tls.Config, error) {
+	key := fmt.Sprintf("%#v", t)
+	if cfg, ok := c.tls[key]; ok {
+		return cfg, nil
+	}
+	cfg, err := t.Value()
+	if err != nil {
+		return nil, err
+	}
+	c.tls[key] = cfg
+	return cfg, nil
+}
+
+// proxy returns the *requester.Proxy generated for p, generating it
+// if needed. It returns nil if p has no URL.
+func (c connOptions) proxy(p config.Proxy) (*requester.Proxy, error) {
+	key := fmt.Sprintf("%#v", p)
+	if proxy, ok := c.proxies[key]; ok {
+		return proxy, nil
+	}
+	proxyURL, err := p.Value()
+	if err != nil || proxyURL == nil {
+		return nil, err
+	}
+	proxy := &requester.Proxy{URL: proxyURL, NoProxy: p.NoProxy}
+	c.proxies[key] = proxy
+	return proxy, nil
+}
+
+// dnsOptions returns the *requester.DNS generated for resolve and d,
+// generating it if needed. It returns nil if they keep the default
+// resolution, i.e. by the system resolver for every connection.
+func (c connOptions) dnsOptions(resolve map[string]string, d config.DNS) *requester.DNS {
+	if len(resolve) == 0 && d.Server == "" && d.Mode != config.DNSOnce {
+		return nil
+	}
+	key := fmt.Sprintf("%#v %#v", resolve, d)
+	if dns, ok := c.dns[key]; ok {
+		return dns
+	}
+	dns := &requester.DNS{Resolve: resolve, Server: d.Server, Mode: d.Mode}
+	c.dns[key] = dns
+	return dns
+}
+
+// requesterExtract returns a slice of requester.Extract generated
+// from extract.
+func requesterExtract(extract []config.Extract) []requester.Extract {
+	if len(extract) == 0 {
+		return nil
+	}
+	out := make([]requester.Extract, len(extract))
+	for i, e := range extract {
+		out[i] = requester.Extract{
+			Name:   e.Name,
+			Source: e.Source,
+			Key:    e.Key,
+		}
+	}
+	return out
+}
+
 // handleRunInterrupt handles the case when the runner is interrupted.
 func (*cmdRun) handleRunInterrupt() error {
 	v, err := promptf("\nBenchmark interrupted, generate output anyway? (yes/no): ")
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Body types.
+const (
+	// BodyRaw attaches Content as is.
+	BodyRaw = "raw"
+	// BodyFile reads the body from the file at path Content. The file is
+	// streamed for each request rather than loaded in memory.
+	BodyFile = "file"
+	// BodyForm encodes Fields as an URL-encoded form.
+	BodyForm = "form"
+	// BodyMultipart encodes Fields and Files as a multipart form.
+	// The files are streamed for each request rather than loaded in memory.
+	BodyMultipart = "multipart"
+)
+
+// Body represents a request body associated with a type.
+// The type affects the way the content is processed (see BodyRaw,
+// BodyFile, BodyForm, BodyMultipart). An empty type is read as BodyRaw.
+type Body struct {
+	Type    string
+	Content []byte
+
+	// Fields are the form fields of types BodyForm and BodyMultipart.
+	Fields url.Values
+	// Files maps the field names of the file parts of type BodyMultipart
+	// to the paths of the files.
+	Files map[string]string
+}
+
+// NewBody returns a Body initialized with the given type and content.
+func NewBody(typ, content string) Body {
+	return Body{Type: typ, Content: []byte(content)}
+}
+
+// validate returns a non-nil error if the Body is not valid for its Type.
+func (b Body) validate() error {
+	switch b.Type {
+	case "", BodyRaw, BodyForm:
+	case BodyFile:
+		if len(b.Content) == 0 {
+			return fmt.Errorf("%s: missing path", b.Type)
+		}
+	case BodyMultipart:
+		for field, filename := range b.Files {
+			if filename == "" {
+				return fmt.Errorf("%s: missing path for file %q", b.Type, field)
+			}
+		}
+	default:
+		return fmt.Errorf(
+			`unknown type %q, want one of "raw", "file", "form", "multipart"`,
+			b.Type,
+		)
+	}
+	return nil
+}
+
+// attach sets the Body as the body of req, and sets its Content-Type
+// header accordingly unless already set. It returns a non-nil error
+// if a file of the Body cannot be read.
+func (b Body) attach(req *http.Request) error {
+	switch b.Type {
+	case BodyFile:
+		filename := string(b.Content)
+		size, err := fileSize(filename)
+		if err != nil {
+			return err
+		}
+		setStreamedBody(req, size, func() (io.ReadCloser, error) {
+			return os.Open(filename)
+		})
+
+	case BodyForm:
+		setContentType(req, "application/x-www-form-urlencoded")
+		setBody(req, []byte(b.Fields.Encode()))
+
+	case BodyMultipart:
+		// the boundary must be the same for all requests as the
+		// Content-Type header is set once
+		boundary := multipart.NewWriter(io.Discard).Boundary()
+		setContentType(req, "multipart/form-data; boundary="+boundary)
+
+		if len(b.Files) == 0 {
+			var buf bytes.Buffer
+			if err := b.writeMultipart(&buf, boundary, nil); err != nil {
+				return err
+			}
+			setBody(req, buf.Bytes())
+			return nil
+		}
+
+		// compute the size of the body without reading the files
+		var size countWriter
+		if err := b.writeMultipart(&size, boundary, func(_ io.Writer, filename string) error {
+			n, err := fileSize(filename)
+			size += countWriter(n)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		setStreamedBody(req, int64(size), func() (io.ReadCloser, error) {
+			pr, pw := io.Pipe()
+			go func() {
+				pw.CloseWithError(b.writeMultipart(pw, boundary, copyFile))
+			}()
+			return pr, nil
+		})
+
+	default:
+		setBody(req, b.Content)
+	}
+	return nil
+}
+
+// writeMultipart writes the Body to w as a multipart form with the given
+// boundary. The content of each file part is written by writeFile.
+// Fields and files are written in the order of their names.
+func (b Body) writeMultipart(
+	w io.Writer,
+	boundary string,
+	writeFile func(w io.Writer, filename string) error,
+) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(b.Fields))
+	for key := range b.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range b.Fields[key] {
+			if err := mw.WriteField(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(b.Files))
+	for field := range b.Files {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		filename := b.Files[field]
+		part, err := mw.CreateFormFile(field, filepath.Base(filename))
+		if err != nil {
+			return err
+		}
+		if err := writeFile(part, filename); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+// setBody sets b as the body of req, the same way http.NewRequest does
+// for a *bytes.Reader.
+func setBody(req *http.Request, b []byte) {
+	req.ContentLength = int64(len(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		if len(b) == 0 {
+			return http.NoBody, nil
+		}
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	req.Body, _ = req.GetBody()
+}
+
+// setStreamedBody sets the body of req to a body of the given size read
+// from the io.ReadCloser returned by open. A new one is opened for each
+// request body read, on first read.
+func setStreamedBody(req *http.Request, size int64, open func() (io.ReadCloser, error)) {
+	req.ContentLength = size
+	req.GetBody = func() (io.ReadCloser, error) {
+		return &lazyBody{open: open}, nil
+	}
+	req.Body, _ = req.GetBody()
+}
+
+// setContentType sets the Content-Type header of req unless already set.
+func setContentType(req *http.Request, contentType string) {
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+}
+
+// lazyBody is an io.ReadCloser opening its underlying body on first read,
+// so that a request body that is never sent holds no resources.
+type lazyBody struct {
+	open func() (io.ReadCloser, error)
+	body io.ReadCloser
+}
+
+// Read opens the underlying body if needed and reads from it.
+func (b *lazyBody) Read(p []byte) (int, error) {
+	if b.body == nil {
+		body, err := b.open()
+		if err != nil {
+			return 0, err
+		}
+		b.body = body
+	}
+	return b.body.Read(p)
+}
+
+// Close closes the underlying body if it was opened.
+func (b *lazyBody) Close() error {
+	if b.body == nil {
+		return nil
+	}
+	return b.body.Close()
+}
+
+// countWriter is an io.Writer counting the bytes written to it.
+type countWriter int64
+
+// Write adds the length of p to the count.
+func (w *countWriter) Write(p []byte) (int, error) {
+	*w += countWriter(len(p))
+	return len(p), nil
+}
+
+// fileSize returns the size of the regular file filename.
+func fileSize(filename string) (int64, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return 0, fmt.Errorf("body file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return 0, fmt.Errorf("body file: %s: not a regular file", filename)
+	}
+	return info.Size(), nil
+}
+
+// copyFile copies the content of the file filename to w.
+func copyFile(w io.Writer, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,146 @@
+package config_test
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benchttp/runner/config"
+)
+
+func TestRequest_Value_body(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "avatar.png")
+	if err := os.WriteFile(filename, []byte("file content"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("stream file body for each request", func(t *testing.T) {
+		req := mustValue(t, config.Request{
+			Method: "POST",
+			Body:   config.NewBody(config.BodyFile, filename),
+		})
+
+		if req.ContentLength != int64(len("file content")) {
+			t.Errorf("exp content length %d, got %d", len("file content"), req.ContentLength)
+		}
+		for i := 0; i < 2; i++ {
+			body, _ := req.GetBody()
+			if got := readAll(t, body); got != "file content" {
+				t.Errorf("exp %q, got %q", "file content", got)
+			}
+		}
+	})
+
+	t.Run("encode form body", func(t *testing.T) {
+		req := mustValue(t, config.Request{
+			Method: "POST",
+			Body: config.Body{
+				Type:   config.BodyForm,
+				Fields: url.Values{"name": {"alice"}, "tags": {"a", "b c"}},
+			},
+		})
+
+		if exp := "application/x-www-form-urlencoded"; req.Header.Get("Content-Type") != exp {
+			t.Errorf("exp Content-Type %q, got %q", exp, req.Header.Get("Content-Type"))
+		}
+		if exp, got := "name=alice&tags=a&tags=b+c", readAll(t, req.Body); got != exp {
+			t.Errorf("exp %q, got %q", exp, got)
+		}
+	})
+
+	t.Run("encode multipart body with streamed files", func(t *testing.T) {
+		req := mustValue(t, config.Request{
+			Method: "POST",
+			Body: config.Body{
+				Type:   config.BodyMultipart,
+				Fields: url.Values{"name": {"alice"}},
+				Files:  map[string]string{"avatar": filename},
+			},
+		})
+
+		mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/form-data" {
+			t.Fatalf("unexpected Content-Type %q", req.Header.Get("Content-Type"))
+		}
+
+		for i := 0; i < 2; i++ {
+			body, _ := req.GetBody()
+			raw := readAll(t, body)
+			if int64(len(raw)) != req.ContentLength {
+				t.Errorf("exp content length %d, got %d", len(raw), req.ContentLength)
+			}
+
+			form, err := multipart.NewReader(strings.NewReader(raw), params["boundary"]).ReadForm(1 << 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := form.Value["name"]; len(got) != 1 || got[0] != "alice" {
+				t.Errorf("exp field name=alice, got %v", got)
+			}
+			files := form.File["avatar"]
+			if len(files) != 1 || files[0].Filename != "avatar.png" {
+				t.Fatalf("exp file avatar.png, got %v", files)
+			}
+			f, _ := files[0].Open()
+			if got := readAll(t, f); got != "file content" {
+				t.Errorf("exp file content %q, got %q", "file content", got)
+			}
+		}
+	})
+
+	t.Run("keep user Content-Type", func(t *testing.T) {
+		req := mustValue(t, config.Request{
+			Method: "POST",
+			Header: http.Header{"Content-Type": {"text/plain"}},
+			Body:   config.Body{Type: config.BodyForm, Fields: url.Values{"a": {"b"}}},
+		})
+		if exp := "text/plain"; req.Header.Get("Content-Type") != exp {
+			t.Errorf("exp Content-Type %q, got %q", exp, req.Header.Get("Content-Type"))
+		}
+	})
+
+	t.Run("return error for missing files", func(t *testing.T) {
+		missing := filepath.Join(dir, "missing.png")
+		for _, body := range []config.Body{
+			config.NewBody(config.BodyFile, missing),
+			config.NewBody(config.BodyFile, dir),
+			{Type: config.BodyMultipart, Files: map[string]string{"avatar": missing}},
+		} {
+			req, err := config.Request{Method: "POST", Body: body}.WithURL("http://a.b").Value()
+			if err == nil {
+				t.Errorf("%s: exp error, got nil", body.Type)
+			}
+			if req != nil {
+				t.Errorf("%s: exp nil request, got %v", body.Type, req)
+			}
+		}
+	})
+}
+
+// helpers
+
+func mustValue(t *testing.T, r config.Request) *http.Request {
+	t.Helper()
+	req, err := r.WithURL("http://a.b").Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return req
+}
+
+func readAll(t *testing.T, r io.ReadCloser) string {
+	t.Helper()
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Check kinds.
+const (
+	// CheckStatus checks the response status code against a comma-separated
+	// list of codes ("200"), classes ("2xx") or ranges ("200-299").
+	CheckStatus = "status"
+	// CheckHeader checks the response has a header Key. If Value is set,
+	// the header value must be equal to it.
+	CheckHeader = "header"
+	// CheckBody checks the response body contains Value.
+	CheckBody = "body"
+	// CheckBodyRegexp checks the response body matches the regular
+	// expression Value.
+	CheckBodyRegexp = "bodyRegexp"
+	// CheckJSON checks the value found at the JSON path Key in the response
+	// body is equal to the JSON-encoded Value.
+	CheckJSON = "json"
+)
+
+var checkStatusRegexp = regexp.MustCompile(`^([1-5][0-9]{2}|[1-5]xx|[1-5][0-9]{2}-[1-5][0-9]{2})$`)
+
+// Check is a condition a response must meet to be considered successful.
+// Its semantics depend on its Kind (see CheckStatus, CheckHeader, CheckBody,
+// CheckBodyRegexp, CheckJSON).
+type Check struct {
+	Kind  string
+	Key   string
+	Value string
+}
+
+// String returns a human-readable representation of the Check.
+func (c Check) String() string {
+	switch c.Kind {
+	case CheckStatus:
+		return fmt.Sprintf("status %s", c.Value)
+	case CheckHeader:
+		if c.Value == "" {
+			return fmt.Sprintf("header %s", c.Key)
+		}
+		return fmt.Sprintf("header %s == %s", c.Key, c.Value)
+	case CheckBody:
+		return fmt.Sprintf("body contains %q", c.Value)
+	case CheckBodyRegexp:
+		return fmt.Sprintf("body matches %q", c.Value)
+	case CheckJSON:
+		return fmt.Sprintf("json %s == %s", c.Key, c.Value)
+	}
+	return strings.TrimSpace(strings.Join([]string{c.Kind, c.Key, c.Value}, " "))
+}
+
+// validate returns a non-nil error if the Check is not valid for its Kind.
+func (c Check) validate() error {
+	switch c.Kind {
+	case CheckStatus:
+		return validateStatus(c.Value)
+	case CheckHeader:
+		if c.Key == "" {
+			return fmt.Errorf("%s: missing key", c.Kind)
+		}
+	case CheckJSON:
+		if c.Key == "" {
+			return fmt.Errorf("%s: missing key", c.Kind)
+		}
+		if !json.Valid([]byte(c.Value)) {
+			return fmt.Errorf("%s: value is not valid JSON", c.Kind)
+		}
+	case CheckBody:
+		if c.Value == "" {
+			return fmt.Errorf("%s: missing value", c.Kind)
+		}
+	case CheckBodyRegexp:
+		if _, err := regexp.Compile(c.Value); err != nil {
+			return fmt.Errorf("%s: %s", c.Kind, err)
+		}
+	default:
+		return fmt.Errorf(
+			`unknown kind %q, want one of "status", "header", "body", "bodyRegexp", "json"`,
+			c.Kind,
+		)
+	}
+	return nil
+}
+
+// validateStatus returns a non-nil error if spec is not a comma-separated
+// list of status codes, classes or ranges.
+func validateStatus(spec string) error {
+	for _, status := range strings.Split(spec, ",") {
+		if !checkStatusRegexp.MatchString(status) {
+			return fmt.Errorf(`want status codes, classes or ranges (e.g. "200,3xx,400-404"), got %q`, status)
+		}
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package config
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,29 +9,38 @@ import (
 	"time"
 )
 
-// Body represents a request body associated with a type.
-// The type affects the way the content is processed.
-// If Type == "file", Content is read as a filepath to be resolved.
-// If Type == "raw", Content is attached as-is.
-//
-// Note: only "raw" is supported at the moment.
-type Body struct {
-	Type    string
-	Content []byte
-}
-
-// NewBody returns a Body initialized with the given type and content.
-// For now, the only valid value for type is "raw".
-func NewBody(typ, content string) Body {
-	return Body{Type: typ, Content: []byte(content)}
-}
+// Protocols of the requests.
+const (
+	// ProtocolHTTP1 forces HTTP/1.1.
+	ProtocolHTTP1 = "http1"
+	// ProtocolHTTP2 forces HTTP/2 over TLS.
+	ProtocolHTTP2 = "http2"
+	// ProtocolH2C forces HTTP/2 over cleartext TCP, without upgrade.
+	ProtocolH2C = "h2c"
+	// ProtocolAuto uses HTTP/2 if the server supports it over TLS,
+	// HTTP/1.1 otherwise.
+	ProtocolAuto = "auto"
+)
 
 // Request contains the confing options relative to a single request.
+// An empty Protocol is read as ProtocolAuto.
+// Resolve maps addresses "host:port" to the IP address connected to
+// instead of resolving host, the Host header and TLS server name
+// being kept.
+// UnixSocket is the path of a Unix socket the request is sent to instead
+// of the host of its URL, which is kept as the Host header. Resolve and DNS
+// do not apply to it.
 type Request struct {
-	Method string
-	URL    *url.URL
-	Header http.Header
-	Body   Body
+	Method     string
+	URL        *url.URL
+	Header     http.Header
+	Body       Body
+	Protocol   string
+	TLS        TLS
+	Proxy      Proxy
+	Resolve    map[string]string
+	DNS        DNS
+	UnixSocket string
 }
 
 // Value generates a *http.Request based on Request and returns it
@@ -46,11 +54,17 @@ func (r Request) Value() (*http.Request, error) {
 		return nil, errors.New("bad url")
 	}
 
-	req, err := http.NewRequest(r.Method, rawURL, bytes.NewReader(r.Body.Content))
+	req, err := http.NewRequest(r.Method, rawURL, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header = r.Header
+	req.Header = r.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	if err := r.Body.attach(req); err != nil {
+		return nil, err
+	}
 	return req, nil
 }
 
@@ -67,13 +81,135 @@ func (r Request) WithURL(rawURL string) Request {
 	return r
 }
 
+// validate returns the errors of the Request, each field prefixed
+// with prefix.
+func (r Request) validate(prefix string) []error {
+	errs := []error{}
+
+	if r.URL == nil {
+		errs = append(errs, fmt.Errorf("%surl: missing", prefix))
+	} else if _, err := url.ParseRequestURI(r.URL.String()); err != nil {
+		errs = append(errs, fmt.Errorf("%surl (%q): invalid", prefix, r.URL.String()))
+	}
+
+	if err := r.Body.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("%sbody: %s", prefix, err))
+	}
+
+	if err := validateProtocol(r.Protocol); err != nil {
+		errs = append(errs, fmt.Errorf("%sprotocol %s", prefix, err))
+	}
+
+	if err := r.TLS.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("%stls: %s", prefix, err))
+	}
+
+	if err := r.Proxy.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("%sproxy.%s", prefix, err))
+	}
+
+	if err := validateResolve(r.Resolve); err != nil {
+		errs = append(errs, fmt.Errorf("%s%s", prefix, err))
+	}
+
+	if err := r.DNS.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("%sdns.%s", prefix, err))
+	}
+
+	if err := r.validateUnixSocket(); err != nil {
+		errs = append(errs, fmt.Errorf("%s%s", prefix, err))
+	}
+
+	return errs
+}
+
+// validateUnixSocket returns a non-nil error if r is sent to a Unix socket
+// through a proxy, which cannot reach it.
+func (r Request) validateUnixSocket() error {
+	if r.UnixSocket != "" && r.Proxy.URL != "" {
+		return fmt.Errorf("unixSocket (%q): want no proxy", r.UnixSocket)
+	}
+	return nil
+}
+
+// validateProtocol returns a non-nil error if protocol is not a known
+// protocol.
+func validateProtocol(protocol string) error {
+	switch protocol {
+	case "", ProtocolHTTP1, ProtocolHTTP2, ProtocolH2C, ProtocolAuto:
+		return nil
+	}
+	return fmt.Errorf(`(%q): want one of "http1", "http2", "h2c", "auto"`, protocol)
+}
+
+// Stage is a step of a load profile. During a Stage, the load is linearly
+// ramped from the target of the previous Stage to its own target, which
+// is reached at the end of its Duration. The target is Rate if it is set,
+// Concurrency otherwise.
+type Stage struct {
+	Duration    time.Duration
+	Concurrency int
+	Rate        int
+}
+
 // Runner contains options relative to the runner.
 type Runner struct {
 	Requests       int
 	Concurrency    int
-	Interval       time.Duration
+	Rate           int
+	Stages         []Stage
+	Interval       Interval
 	RequestTimeout time.Duration
 	GlobalTimeout  time.Duration
+	Drain          time.Duration
+	Warmup         Warmup
+	BucketInterval time.Duration
+	Preflight      Preflight
+	Seed           int64
+	Connections    Connections
+	Records        Records
+}
+
+// Connection pools.
+const (
+	// ConnPoolShared shares a single pool of connections between
+	// all workers.
+	ConnPoolShared = "shared"
+	// ConnPoolPerWorker dedicates a pool of connections to each worker.
+	ConnPoolPerWorker = "perWorker"
+)
+
+// Connections contains options relative to the connections of the runner.
+// MaxIdle is the maximum number of idle connections kept per host
+// in each pool, 0 meaning as many as the workers using the pool.
+// MaxPerHost is the maximum number of connections per host in each pool,
+// 0 meaning no limit.
+// MaxStreams is the maximum number of concurrent streams of an HTTP/2
+// connection before a new one is opened, 0 meaning the limit set by the
+// server. It only applies to protocols ProtocolHTTP2 and ProtocolH2C.
+type Connections struct {
+	DisableKeepAlive bool
+	MaxIdle          int
+	MaxPerHost       int
+	MaxStreams       int
+	Pool             string
+}
+
+// Record storages.
+const (
+	// RecordStorageMemory keeps the records in memory.
+	RecordStorageMemory = "memory"
+	// RecordStorageDisk writes the records to files, so that the memory
+	// used does not grow with the number of records.
+	RecordStorageDisk = "disk"
+)
+
+// Records contains options relative to the storage of the records.
+// Dir is the directory of the record files in storage RecordStorageDisk,
+// a temporary directory being created if it is empty.
+type Records struct {
+	Storage string
+	Dir     string
 }
 
 // Output contains options relative to the output.
@@ -83,6 +219,35 @@ type Output struct {
 	Template string
 }
 
+// Data read modes.
+const (
+	// DataSequential reads the rows in order, one per iteration.
+	DataSequential = "sequential"
+	// DataRandom reads a random row for each iteration.
+	DataRandom = "random"
+	// DataPerWorker splits the rows between the workers, so that
+	// concurrent iterations never read the same row. Each worker
+	// reads its own rows in order, which requires at least as many
+	// rows as workers.
+	DataPerWorker = "perWorker"
+)
+
+// Data end policies.
+const (
+	// DataWrap starts over from the first row when the data runs out.
+	DataWrap = "wrap"
+	// DataStop ends the run when the data runs out.
+	DataStop = "stop"
+)
+
+// Data contains options relative to the test data file, whose columns
+// are available in the requests using placeholders "${data.<column>}".
+type Data struct {
+	File  string
+	Mode  string
+	OnEnd string
+}
+
 func (o Output) HasStrategy(s OutputStrategy) bool {
 	for _, out := range o.Out {
 		if out == s {
@@ -95,9 +260,14 @@ func (o Output) HasStrategy(s OutputStrategy) bool {
 // Global represents the global configuration of the runner.
 // It must be validated using Global.Validate before usage.
 type Global struct {
-	Request Request
-	Runner  Runner
-	Output  Output
+	Request    Request
+	Runner     Runner
+	Output     Output
+	Data       Data
+	Endpoints  []Endpoint
+	Scenario   []Step
+	Checks     []Check
+	Thresholds []Threshold
 }
 
 // String returns an indented JSON representation of Config
@@ -121,22 +291,111 @@ func (cfg Global) Override(c Global, fields ...string) Global {
 			cfg.overrideHeader(c.Request.Header)
 		case FieldBody:
 			cfg.Request.Body = c.Request.Body
+		case FieldProtocol:
+			cfg.Request.Protocol = c.Request.Protocol
+		case FieldTLSCA:
+			cfg.Request.TLS.CAFile = c.Request.TLS.CAFile
+		case FieldTLSCert:
+			cfg.Request.TLS.CertFile = c.Request.TLS.CertFile
+		case FieldTLSKey:
+			cfg.Request.TLS.KeyFile = c.Request.TLS.KeyFile
+		case FieldInsecure:
+			cfg.Request.TLS.InsecureSkipVerify = c.Request.TLS.InsecureSkipVerify
+		case FieldTLSServerName:
+			cfg.Request.TLS.ServerName = c.Request.TLS.ServerName
+		case FieldTLSMinVersion:
+			cfg.Request.TLS.MinVersion = c.Request.TLS.MinVersion
+		case FieldTLSMaxVersion:
+			cfg.Request.TLS.MaxVersion = c.Request.TLS.MaxVersion
+		case FieldTLSCiphers:
+			cfg.Request.TLS.CipherSuites = c.Request.TLS.CipherSuites
+		case FieldTLSSessionResumption:
+			cfg.Request.TLS.SessionResumption = c.Request.TLS.SessionResumption
+		case FieldProxy:
+			cfg.Request.Proxy.URL = c.Request.Proxy.URL
+		case FieldNoProxy:
+			cfg.Request.Proxy.NoProxy = c.Request.Proxy.NoProxy
+		case FieldResolve:
+			cfg.Request.Resolve = c.Request.Resolve
+		case FieldDNSServer:
+			cfg.Request.DNS.Server = c.Request.DNS.Server
+		case FieldDNSMode:
+			cfg.Request.DNS.Mode = c.Request.DNS.Mode
+		case FieldUnixSocket:
+			cfg.Request.UnixSocket = c.Request.UnixSocket
 		case FieldRequests:
 			cfg.Runner.Requests = c.Runner.Requests
 		case FieldConcurrency:
 			cfg.Runner.Concurrency = c.Runner.Concurrency
+		case FieldRate:
+			cfg.Runner.Rate = c.Runner.Rate
+		case FieldStages:
+			cfg.Runner.Stages = c.Runner.Stages
 		case FieldInterval:
 			cfg.Runner.Interval = c.Runner.Interval
+		case FieldIntervalMode:
+			cfg.Runner.Interval.Mode = c.Runner.Interval.Mode
 		case FieldRequestTimeout:
 			cfg.Runner.RequestTimeout = c.Runner.RequestTimeout
 		case FieldGlobalTimeout:
 			cfg.Runner.GlobalTimeout = c.Runner.GlobalTimeout
+		case FieldDrain:
+			cfg.Runner.Drain = c.Runner.Drain
+		case FieldWarmup:
+			cfg.Runner.Warmup.Requests = c.Runner.Warmup.Requests
+			cfg.Runner.Warmup.Duration = c.Runner.Warmup.Duration
+		case FieldWarmupReport:
+			cfg.Runner.Warmup.Report = c.Runner.Warmup.Report
+		case FieldBucketInterval:
+			cfg.Runner.BucketInterval = c.Runner.BucketInterval
+		case FieldSkipPreflight:
+			cfg.Runner.Preflight.Skip = c.Runner.Preflight.Skip
+		case FieldPreflightURL:
+			cfg.Runner.Preflight.URL = c.Runner.Preflight.URL
+		case FieldPreflightMethod:
+			cfg.Runner.Preflight.Method = c.Runner.Preflight.Method
+		case FieldPreflightStatus:
+			cfg.Runner.Preflight.Status = c.Runner.Preflight.Status
+		case FieldPreflightMaxWait:
+			cfg.Runner.Preflight.MaxWait = c.Runner.Preflight.MaxWait
+		case FieldPreflightBackoff:
+			cfg.Runner.Preflight.Backoff = c.Runner.Preflight.Backoff
+		case FieldSeed:
+			cfg.Runner.Seed = c.Runner.Seed
+		case FieldDisableKeepAlive:
+			cfg.Runner.Connections.DisableKeepAlive = c.Runner.Connections.DisableKeepAlive
+		case FieldMaxIdleConns:
+			cfg.Runner.Connections.MaxIdle = c.Runner.Connections.MaxIdle
+		case FieldMaxConnsPerHost:
+			cfg.Runner.Connections.MaxPerHost = c.Runner.Connections.MaxPerHost
+		case FieldMaxStreams:
+			cfg.Runner.Connections.MaxStreams = c.Runner.Connections.MaxStreams
+		case FieldConnPool:
+			cfg.Runner.Connections.Pool = c.Runner.Connections.Pool
+		case FieldRecordStorage:
+			cfg.Runner.Records.Storage = c.Runner.Records.Storage
+		case FieldRecordDir:
+			cfg.Runner.Records.Dir = c.Runner.Records.Dir
 		case FieldOut:
 			cfg.Output.Out = c.Output.Out
 		case FieldSilent:
 			cfg.Output.Silent = c.Output.Silent
 		case FieldTemplate:
 			cfg.Output.Template = c.Output.Template
+		case FieldDataFile:
+			cfg.Data.File = c.Data.File
+		case FieldDataMode:
+			cfg.Data.Mode = c.Data.Mode
+		case FieldDataOnEnd:
+			cfg.Data.OnEnd = c.Data.OnEnd
+		case FieldEndpoints:
+			cfg.Endpoints = c.Endpoints
+		case FieldScenario:
+			cfg.Scenario = c.Scenario
+		case FieldChecks:
+			cfg.Checks = c.Checks
+		case FieldThresholds:
+			cfg.Thresholds = c.Thresholds
 		}
 	}
 	return cfg
@@ -170,25 +429,71 @@ func (cfg Global) Validate() error { //nolint:gocognit
 		errs = append(errs, err)
 	}
 
-	if cfg.Request.URL == nil {
-		appendError(errors.New("url: missing"))
-	} else if _, err := url.ParseRequestURI(cfg.Request.URL.String()); err != nil {
-		appendError(fmt.Errorf("url (%q): invalid", cfg.Request.URL.String()))
+	// the request is not used if endpoints or a scenario are set
+	if len(cfg.Endpoints) == 0 && len(cfg.Scenario) == 0 {
+		errs = append(errs, cfg.Request.validate("")...)
+	}
+
+	// data options are not used if no data file is set
+	if cfg.Data.File != "" {
+		switch cfg.Data.Mode {
+		case DataSequential, DataRandom, DataPerWorker:
+		default:
+			appendError(fmt.Errorf(
+				`data.mode (%q): want one of "sequential", "random", "perWorker"`,
+				cfg.Data.Mode,
+			))
+		}
+
+		switch cfg.Data.OnEnd {
+		case DataWrap, DataStop:
+		default:
+			appendError(fmt.Errorf(`data.onEnd (%q): want one of "wrap", "stop"`, cfg.Data.OnEnd))
+		}
+	}
+
+	for i, endpoint := range cfg.Endpoints {
+		errs = append(errs, endpoint.validate(i)...)
+	}
+
+	if len(cfg.Endpoints) > 0 && len(cfg.Scenario) > 0 {
+		appendError(errors.New("endpoints: cannot be used with scenario"))
+	}
+
+	for i, step := range cfg.Scenario {
+		errs = append(errs, step.validate(i)...)
 	}
 
 	if cfg.Runner.Requests < 1 && cfg.Runner.Requests != -1 {
 		appendError(fmt.Errorf("requests (%d): want >= 0", cfg.Runner.Requests))
 	}
 
-	if cfg.Runner.Concurrency < 1 || cfg.Runner.Concurrency > cfg.Runner.Requests {
+	if cfg.Runner.Concurrency < 1 ||
+		(cfg.Runner.Requests != -1 && cfg.Runner.Concurrency > cfg.Runner.Requests) {
 		appendError(fmt.Errorf(
 			"concurrency (%d): want > 0 and <= requests (%d)",
 			cfg.Runner.Concurrency, cfg.Runner.Requests,
 		))
 	}
 
-	if cfg.Runner.Interval < 0 {
-		appendError(fmt.Errorf("interval (%d): want >= 0", cfg.Runner.Interval))
+	if cfg.Runner.Rate < 0 {
+		appendError(fmt.Errorf("rate (%d): want >= 0", cfg.Runner.Rate))
+	}
+
+	for i, stage := range cfg.Runner.Stages {
+		if stage.Duration < 1 {
+			appendError(fmt.Errorf("stages[%d].duration (%d): want > 0", i, stage.Duration))
+		}
+		if stage.Concurrency < 0 {
+			appendError(fmt.Errorf("stages[%d].concurrency (%d): want >= 0", i, stage.Concurrency))
+		}
+		if stage.Rate < 0 {
+			appendError(fmt.Errorf("stages[%d].rate (%d): want >= 0", i, stage.Rate))
+		}
+	}
+
+	if err := cfg.Runner.Interval.validate(); err != nil {
+		appendError(fmt.Errorf("interval: %s", err))
 	}
 
 	if cfg.Runner.RequestTimeout < 1 {
@@ -199,6 +504,44 @@ func (cfg Global) Validate() error { //nolint:gocognit
 		appendError(fmt.Errorf("globalTimeout (%d): want > 0", cfg.Runner.GlobalTimeout))
 	}
 
+	if cfg.Runner.Drain < 0 {
+		appendError(fmt.Errorf("drain (%d): want >= 0", cfg.Runner.Drain))
+	}
+
+	if err := cfg.Runner.Warmup.validate(); err != nil {
+		appendError(fmt.Errorf("warmup: %s", err))
+	}
+
+	if cfg.Runner.BucketInterval < 0 {
+		appendError(fmt.Errorf("bucketInterval (%d): want >= 0", cfg.Runner.BucketInterval))
+	}
+
+	if err := cfg.Runner.Preflight.validate(); err != nil {
+		appendError(fmt.Errorf("preflight.%s", err))
+	}
+
+	conns := cfg.Runner.Connections
+	if conns.MaxIdle < 0 {
+		appendError(fmt.Errorf("connections.maxIdle (%d): want >= 0", conns.MaxIdle))
+	}
+	if conns.MaxPerHost < 0 {
+		appendError(fmt.Errorf("connections.maxPerHost (%d): want >= 0", conns.MaxPerHost))
+	}
+	if conns.MaxStreams < 0 {
+		appendError(fmt.Errorf("connections.maxStreams (%d): want >= 0", conns.MaxStreams))
+	}
+	switch conns.Pool {
+	case "", ConnPoolShared, ConnPoolPerWorker:
+	default:
+		appendError(fmt.Errorf(`connections.pool (%q): want one of "shared", "perWorker"`, conns.Pool))
+	}
+
+	switch storage := cfg.Runner.Records.Storage; storage {
+	case "", RecordStorageMemory, RecordStorageDisk:
+	default:
+		appendError(fmt.Errorf(`records.storage (%q): want one of "memory", "disk"`, storage))
+	}
+
 	if out := cfg.Output.Out; len(out) == 0 {
 		appendError(errors.New(`out: missing (want one or many of "benchttp", "json", "stdout")`))
 	} else {
@@ -211,6 +554,18 @@ func (cfg Global) Validate() error { //nolint:gocognit
 		}
 	}
 
+	for i, check := range cfg.Checks {
+		if err := check.validate(); err != nil {
+			appendError(fmt.Errorf("checks[%d] (%q): %s", i, check, err))
+		}
+	}
+
+	for i, threshold := range cfg.Thresholds {
+		if err := threshold.validate(); err != nil {
+			appendError(fmt.Errorf("thresholds[%d] (%q): %s", i, threshold, err))
+		}
+	}
+
 	if len(errs) > 0 {
 		return &InvalidConfigError{errs}
 	}
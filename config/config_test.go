@@ -22,15 +22,32 @@ func TestGlobal_Validate(t *testing.T) {
 				Body: validBody,
 			}.WithURL("https://github.com/benchttp/"),
 			Runner: config.Runner{
-				Requests:       5,
-				Concurrency:    5,
-				Interval:       5,
+				Requests:    5,
+				Concurrency: 5,
+				Rate:        5,
+				Stages: []config.Stage{
+					{Duration: 5, Concurrency: 5},
+					{Duration: 5, Rate: 5},
+					{Duration: 5, Concurrency: 0},
+				},
+				Interval:       config.Interval{Distribution: config.IntervalUniform, Min: 5, Max: 10},
 				RequestTimeout: 5,
 				GlobalTimeout:  5,
 			},
 			Output: config.Output{
 				Out: []config.OutputStrategy{"stdout", "json", "benchttp"},
 			},
+			Checks: []config.Check{
+				{Kind: config.CheckStatus, Value: "200,3xx,400-404"},
+				{Kind: config.CheckHeader, Key: "Content-Type"},
+				{Kind: config.CheckBody, Value: "ok"},
+				{Kind: config.CheckBodyRegexp, Value: "^ok$"},
+				{Kind: config.CheckJSON, Key: "data.id", Value: "42"},
+			},
+			Thresholds: []config.Threshold{
+				{Metric: "p95", Operator: "<", Value: 5},
+				{Metric: "status.5xx", Operator: "==", Value: 0},
+			},
 		}
 		if err := cfg.Validate(); err != nil {
 			t.Errorf("unexpected error: %v", err)
@@ -40,18 +57,43 @@ func TestGlobal_Validate(t *testing.T) {
 	t.Run("return cumulated errors if config is invalid", func(t *testing.T) {
 		cfg := config.Global{
 			Request: config.Request{
-				Body: config.Body{},
+				Body:       config.Body{Type: "bad"},
+				Protocol:   "bad",
+				TLS:        config.TLS{CertFile: "cert.pem"},
+				Proxy:      config.Proxy{URL: "ftp://proxy"},
+				Resolve:    map[string]string{"a.b:443": "bad"},
+				DNS:        config.DNS{Server: "bad", Mode: "bad"},
+				UnixSocket: "/var/run/app.sock", // not valid with a proxy
 			}.WithURL("abc"),
 			Runner: config.Runner{
 				Requests:       -5,
 				Concurrency:    -5,
-				Interval:       -5,
+				Rate:           -5,
+				Stages:         []config.Stage{{Duration: -5, Concurrency: -5, Rate: -5}},
+				Interval:       config.Interval{Duration: -5},
 				RequestTimeout: -5,
 				GlobalTimeout:  -5,
+				Drain:          -5,
+				Warmup:         config.Warmup{Requests: -5},
+				BucketInterval: -5,
+				Preflight:      config.Preflight{Status: "2xx,ok"},
+				Connections:    config.Connections{MaxIdle: -5, MaxPerHost: -5, MaxStreams: -5, Pool: "bad"},
+				Records:        config.Records{Storage: "bad"},
 			},
 			Output: config.Output{
 				Out: []config.OutputStrategy{config.OutputStdout, "bad-output"},
 			},
+			Data: config.Data{File: "data.csv", Mode: "bad", OnEnd: "bad"},
+			Checks: []config.Check{
+				{Kind: config.CheckStatus, Value: "200,600"},
+				{Kind: config.CheckJSON, Value: "42"},
+				{Kind: config.CheckBodyRegexp, Value: "("},
+				{Kind: "bad"},
+			},
+			Thresholds: []config.Threshold{
+				{Metric: "bad", Operator: "<", Value: 5},
+				{Metric: "p95", Operator: "~", Value: float64(5 * time.Millisecond)},
+			},
 		}
 
 		err := cfg.Validate()
@@ -66,15 +108,131 @@ func TestGlobal_Validate(t *testing.T) {
 
 		errs := errInvalid.Errors
 		findErrorOrFail(t, errs, `url (""): invalid`)
+		findErrorOrFail(t, errs, `body: unknown type "bad", want one of "raw", "file", "form", "multipart"`)
+		findErrorOrFail(t, errs, `protocol ("bad"): want one of "http1", "http2", "h2c", "auto"`)
+		findErrorOrFail(t, errs, `tls: cert and key: want both or none`)
+		findErrorOrFail(t, errs, `proxy.url ("ftp://proxy"): want scheme "http", "https" or "socks5"`)
+		findErrorOrFail(t, errs, `resolve["a.b:443"] ("bad"): want an IP address`)
+		findErrorOrFail(t, errs, `dns.server ("bad"): want <ip>:<port>`)
+		findErrorOrFail(t, errs, `unixSocket ("/var/run/app.sock"): want no proxy`)
 		findErrorOrFail(t, errs, `requests (-5): want >= 0`)
 		findErrorOrFail(t, errs, `concurrency (-5): want > 0 and <= requests (-5)`)
-		findErrorOrFail(t, errs, `interval (-5): want >= 0`)
+		findErrorOrFail(t, errs, `rate (-5): want >= 0`)
+		findErrorOrFail(t, errs, `stages[0].duration (-5): want > 0`)
+		findErrorOrFail(t, errs, `stages[0].concurrency (-5): want >= 0`)
+		findErrorOrFail(t, errs, `stages[0].rate (-5): want >= 0`)
+		findErrorOrFail(t, errs, `interval: duration (-5): want >= 0`)
 		findErrorOrFail(t, errs, `requestTimeout (-5): want > 0`)
 		findErrorOrFail(t, errs, `globalTimeout (-5): want > 0`)
+		findErrorOrFail(t, errs, `drain (-5): want >= 0`)
+		findErrorOrFail(t, errs, `warmup: requests (-5): want >= 0`)
+		findErrorOrFail(t, errs, `bucketInterval (-5): want >= 0`)
+		findErrorOrFail(t, errs, `preflight.status: want status codes, classes or ranges (e.g. "200,3xx,400-404"), got "ok"`)
+		findErrorOrFail(t, errs, `connections.maxIdle (-5): want >= 0`)
+		findErrorOrFail(t, errs, `connections.maxPerHost (-5): want >= 0`)
+		findErrorOrFail(t, errs, `connections.maxStreams (-5): want >= 0`)
+		findErrorOrFail(t, errs, `connections.pool ("bad"): want one of "shared", "perWorker"`)
+		findErrorOrFail(t, errs, `records.storage ("bad"): want one of "memory", "disk"`)
 		findErrorOrFail(t, errs, `out ("bad-output"): want one or many of "benchttp", "json", "stdout"`)
+		findErrorOrFail(t, errs, `data.mode ("bad"): want one of "sequential", "random", "perWorker"`)
+		findErrorOrFail(t, errs, `data.onEnd ("bad"): want one of "wrap", "stop"`)
+		findErrorOrFail(t, errs, `checks[0] ("status 200,600"): want status codes, classes or ranges (e.g. "200,3xx,400-404"), got "600"`)
+		findErrorOrFail(t, errs, `checks[1] ("json  == 42"): json: missing key`)
+		findErrorOrFail(t, errs, "checks[2] (\"body matches \\\"(\\\"\"): bodyRegexp: error parsing regexp: missing closing ): `(`")
+		findErrorOrFail(t, errs, `checks[3] ("bad"): unknown kind "bad", want one of "status", "header", "body", "bodyRegexp", "json"`)
+		findErrorOrFail(t, errs, `thresholds[0] ("bad < 5"): unknown metric "bad"`)
+		findErrorOrFail(t, errs, `thresholds[1] ("p95 ~ 5ms"): unknown operator "~"`)
 
 		t.Logf("got error:\n%v", errInvalid)
 	})
+
+	t.Run("validate scenario steps instead of request", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Scenario = []config.Step{
+			{
+				Name:    "login",
+				Request: config.Request{Method: "POST"}.WithURL("https://a.b/login"),
+				Extract: []config.Extract{
+					{Name: "token", Source: config.ExtractJSON, Key: "data.token"},
+					{Name: "id", Source: config.ExtractRegexp, Key: `id=(\d+)`},
+				},
+			},
+			{
+				Name:    "profile",
+				Request: config.Request{}.WithURL("https://a.b/users/${id}?token=${token}"),
+				Checks:  []config.Check{{Kind: config.CheckStatus, Value: "2xx"}},
+			},
+		}
+
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		cfg.Scenario = append(cfg.Scenario, config.Step{
+			Request: config.Request{}.WithURL("abc"),
+			Checks:  []config.Check{{Kind: "bad"}},
+			Extract: []config.Extract{
+				{Name: "a", Source: "bad", Key: "k"},
+				{Name: "b", Source: config.ExtractRegexp, Key: "("},
+				{Name: "c", Source: config.ExtractHeader},
+			},
+		})
+
+		var errInvalid *config.InvalidConfigError
+		if err := cfg.Validate(); !errors.As(err, &errInvalid) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		errs := errInvalid.Errors
+		findErrorOrFail(t, errs, `scenario[2].url (""): invalid`)
+		findErrorOrFail(t, errs, `scenario[2].checks[0] ("bad"): unknown kind "bad", want one of "status", "header", "body", "bodyRegexp", "json"`)
+		findErrorOrFail(t, errs, `scenario[2].extract[0] ("a"): unknown source "bad", want one of "json", "header", "regexp"`)
+		findErrorOrFail(t, errs, "scenario[2].extract[1] (\"b\"): error parsing regexp: missing closing ): `(`")
+		findErrorOrFail(t, errs, `scenario[2].extract[2] ("c"): missing key`)
+		if len(errs) != 5 {
+			t.Errorf("exp 5 errors, got %d:\n%v", len(errs), errInvalid)
+		}
+	})
+
+	t.Run("validate endpoints instead of request", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Endpoints = []config.Endpoint{
+			{Weight: 70, Request: config.Request{}.WithURL("https://a.b/items")},
+			{Weight: 30, Request: config.Request{Method: "POST"}.WithURL("https://a.b/items")},
+		}
+
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		cfg.Endpoints = append(cfg.Endpoints, config.Endpoint{Request: config.Request{Protocol: "bad"}.WithURL("abc")})
+		cfg.Scenario = []config.Step{{Request: config.Request{}.WithURL("https://a.b/login")}}
+
+		var errInvalid *config.InvalidConfigError
+		if err := cfg.Validate(); !errors.As(err, &errInvalid) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		errs := errInvalid.Errors
+		findErrorOrFail(t, errs, `endpoints[2].weight (0): want > 0`)
+		findErrorOrFail(t, errs, `endpoints[2].url (""): invalid`)
+		findErrorOrFail(t, errs, `endpoints[2].protocol ("bad"): want one of "http1", "http2", "h2c", "auto"`)
+		findErrorOrFail(t, errs, `endpoints: cannot be used with scenario`)
+		if len(errs) != 4 {
+			t.Errorf("exp 4 errors, got %d:\n%v", len(errs), errInvalid)
+		}
+	})
+
+	t.Run("accept any concurrency if requests is infinite", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Request = cfg.Request.WithURL("https://github.com/benchttp/")
+		cfg.Runner.Requests = -1
+		cfg.Runner.Concurrency = 10
+
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
 }
 
 func TestGlobal_Override(t *testing.T) {
@@ -82,18 +240,67 @@ func TestGlobal_Override(t *testing.T) {
 		baseCfg := config.Global{}
 		newCfg := config.Global{
 			Request: config.Request{
-				Body: config.Body{},
+				Body:     config.Body{},
+				Protocol: config.ProtocolH2C,
+				TLS: config.TLS{
+					CAFile:             "ca.pem",
+					CertFile:           "cert.pem",
+					KeyFile:            "key.pem",
+					InsecureSkipVerify: true,
+					ServerName:         "a.b",
+					MinVersion:         "1.2",
+					MaxVersion:         "1.3",
+					CipherSuites:       []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+					SessionResumption:  true,
+				},
+				Proxy:      config.Proxy{URL: "http://proxy:3128", NoProxy: []string{"localhost"}},
+				Resolve:    map[string]string{"a.b:443": "10.0.0.1"},
+				DNS:        config.DNS{Server: "10.0.0.53:53", Mode: config.DNSOnce},
+				UnixSocket: "/var/run/app.sock",
 			}.WithURL("http://a.b?p=2"),
 			Runner: config.Runner{
-				Requests:       1,
-				Concurrency:    2,
+				Requests:    1,
+				Concurrency: 2,
+				Rate:        5,
+				Stages:      []config.Stage{{Duration: time.Second, Concurrency: 1}},
+				Interval: config.Interval{
+					Mode:         config.IntervalGlobal,
+					Distribution: config.IntervalNormal,
+					Duration:     time.Second,
+					StdDev:       100 * time.Millisecond,
+				},
 				RequestTimeout: 3 * time.Second,
 				GlobalTimeout:  4 * time.Second,
+				Drain:          6 * time.Second,
+				Warmup:         config.Warmup{Duration: 3 * time.Second, Report: true},
+				BucketInterval: 2 * time.Second,
+				Preflight: config.Preflight{
+					Skip:    true,
+					URL:     "http://a.b/health",
+					Method:  "HEAD",
+					Status:  "2xx",
+					MaxWait: 7 * time.Second,
+					Backoff: 200 * time.Millisecond,
+				},
+				Seed: 5,
+				Connections: config.Connections{
+					DisableKeepAlive: true,
+					MaxIdle:          7,
+					MaxPerHost:       8,
+					MaxStreams:       9,
+					Pool:             config.ConnPoolPerWorker,
+				},
+				Records: config.Records{Storage: config.RecordStorageDisk, Dir: "records"},
 			},
 			Output: config.Output{
 				Out:    []config.OutputStrategy{config.OutputStdout},
 				Silent: true,
 			},
+			Data:       config.Data{File: "data.csv", Mode: config.DataRandom, OnEnd: config.DataStop},
+			Endpoints:  []config.Endpoint{{Name: "endpoint", Weight: 1}},
+			Scenario:   []config.Step{{Name: "step"}},
+			Checks:     []config.Check{{Kind: config.CheckStatus, Value: "2xx"}},
+			Thresholds: []config.Threshold{{Metric: "p95", Operator: "<", Value: 5}},
 		}
 
 		if gotCfg := baseCfg.Override(newCfg); !reflect.DeepEqual(gotCfg, baseCfg) {
@@ -105,29 +312,123 @@ func TestGlobal_Override(t *testing.T) {
 		baseCfg := config.Global{}
 		newCfg := config.Global{
 			Request: config.Request{
-				Body: validBody,
+				Body:     validBody,
+				Protocol: config.ProtocolH2C,
+				TLS: config.TLS{
+					CAFile:             "ca.pem",
+					CertFile:           "cert.pem",
+					KeyFile:            "key.pem",
+					InsecureSkipVerify: true,
+					ServerName:         "a.b",
+					MinVersion:         "1.2",
+					MaxVersion:         "1.3",
+					CipherSuites:       []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+					SessionResumption:  true,
+				},
+				Proxy:      config.Proxy{URL: "http://proxy:3128", NoProxy: []string{"localhost"}},
+				Resolve:    map[string]string{"a.b:443": "10.0.0.1"},
+				DNS:        config.DNS{Server: "10.0.0.53:53", Mode: config.DNSOnce},
+				UnixSocket: "/var/run/app.sock",
 			}.WithURL("http://a.b?p=2"),
 			Runner: config.Runner{
-				Requests:       1,
-				Concurrency:    2,
+				Requests:    1,
+				Concurrency: 2,
+				Rate:        5,
+				Stages:      []config.Stage{{Duration: time.Second, Concurrency: 1}},
+				Interval: config.Interval{
+					Mode:         config.IntervalGlobal,
+					Distribution: config.IntervalNormal,
+					Duration:     time.Second,
+					StdDev:       100 * time.Millisecond,
+				},
 				RequestTimeout: 3 * time.Second,
 				GlobalTimeout:  4 * time.Second,
+				Drain:          6 * time.Second,
+				Warmup:         config.Warmup{Duration: 3 * time.Second, Report: true},
+				BucketInterval: 2 * time.Second,
+				Preflight: config.Preflight{
+					Skip:    true,
+					URL:     "http://a.b/health",
+					Method:  "HEAD",
+					Status:  "2xx",
+					MaxWait: 7 * time.Second,
+					Backoff: 200 * time.Millisecond,
+				},
+				Seed: 5,
+				Connections: config.Connections{
+					DisableKeepAlive: true,
+					MaxIdle:          7,
+					MaxPerHost:       8,
+					MaxStreams:       9,
+					Pool:             config.ConnPoolPerWorker,
+				},
+				Records: config.Records{Storage: config.RecordStorageDisk, Dir: "records"},
 			},
 			Output: config.Output{
 				Out:    []config.OutputStrategy{config.OutputStdout},
 				Silent: true,
 			},
+			Data:       config.Data{File: "data.csv", Mode: config.DataRandom, OnEnd: config.DataStop},
+			Endpoints:  []config.Endpoint{{Name: "endpoint", Weight: 1}},
+			Scenario:   []config.Step{{Name: "step"}},
+			Checks:     []config.Check{{Kind: config.CheckStatus, Value: "2xx"}},
+			Thresholds: []config.Threshold{{Metric: "p95", Operator: "<", Value: 5}},
 		}
 		fields := []string{
 			config.FieldMethod,
 			config.FieldURL,
 			config.FieldRequests,
 			config.FieldConcurrency,
+			config.FieldRate,
+			config.FieldStages,
+			config.FieldInterval,
+			config.FieldIntervalMode,
 			config.FieldRequestTimeout,
 			config.FieldGlobalTimeout,
+			config.FieldDrain,
+			config.FieldWarmup,
+			config.FieldWarmupReport,
+			config.FieldBucketInterval,
+			config.FieldSkipPreflight,
+			config.FieldPreflightURL,
+			config.FieldPreflightMethod,
+			config.FieldPreflightStatus,
+			config.FieldPreflightMaxWait,
+			config.FieldPreflightBackoff,
+			config.FieldSeed,
+			config.FieldDisableKeepAlive,
+			config.FieldMaxIdleConns,
+			config.FieldMaxConnsPerHost,
+			config.FieldMaxStreams,
+			config.FieldConnPool,
+			config.FieldRecordStorage,
+			config.FieldRecordDir,
 			config.FieldBody,
+			config.FieldProtocol,
+			config.FieldTLSCA,
+			config.FieldTLSCert,
+			config.FieldTLSKey,
+			config.FieldInsecure,
+			config.FieldTLSServerName,
+			config.FieldTLSMinVersion,
+			config.FieldTLSMaxVersion,
+			config.FieldTLSCiphers,
+			config.FieldTLSSessionResumption,
+			config.FieldProxy,
+			config.FieldNoProxy,
+			config.FieldResolve,
+			config.FieldDNSServer,
+			config.FieldDNSMode,
+			config.FieldUnixSocket,
 			config.FieldOut,
 			config.FieldSilent,
+			config.FieldDataFile,
+			config.FieldDataMode,
+			config.FieldDataOnEnd,
+			config.FieldEndpoints,
+			config.FieldScenario,
+			config.FieldChecks,
+			config.FieldThresholds,
 		}
 
 		if gotCfg := baseCfg.Override(newCfg, fields...); !reflect.DeepEqual(gotCfg, newCfg) {
@@ -136,6 +437,24 @@ func TestGlobal_Override(t *testing.T) {
 		}
 	})
 
+	t.Run("override the interval as a whole", func(t *testing.T) {
+		baseCfg := config.Global{Runner: config.Runner{Interval: config.Interval{
+			Mode:         config.IntervalGlobal,
+			Distribution: config.IntervalUniform,
+			Min:          time.Second,
+			Max:          2 * time.Second,
+		}}}
+		newCfg := config.Global{Runner: config.Runner{Interval: config.Interval{
+			Distribution: config.IntervalConstant,
+			Duration:     time.Second,
+		}}}
+
+		gotCfg := baseCfg.Override(newCfg, config.FieldInterval)
+		if !reflect.DeepEqual(gotCfg.Runner.Interval, newCfg.Runner.Interval) {
+			t.Errorf("did not override the interval:\nexp %+v\ngot %+v", newCfg.Runner.Interval, gotCfg.Runner.Interval)
+		}
+	})
+
 	t.Run("override header selectively", func(t *testing.T) {
 		testcases := []struct {
 			label     string
@@ -8,23 +8,48 @@ import (
 
 var defaultConfig = Global{
 	Request: Request{
-		Method: "GET",
-		URL:    &url.URL{},
-		Header: http.Header{},
-		Body:   Body{},
+		Method:   "GET",
+		URL:      &url.URL{},
+		Header:   http.Header{},
+		Body:     Body{},
+		Protocol: ProtocolAuto,
+		TLS:      TLS{},
+		Proxy:    Proxy{},
+		DNS:      DNS{Mode: DNSPerConnection},
 	},
 	Runner: Runner{
 		Concurrency:    10,
 		Requests:       100,
-		Interval:       0 * time.Second,
+		Rate:           0,
+		Interval:       Interval{Mode: IntervalPerWorker, Distribution: IntervalConstant},
 		RequestTimeout: 5 * time.Second,
 		GlobalTimeout:  30 * time.Second,
+		Drain:          0 * time.Second,
+		Warmup:         Warmup{},
+		BucketInterval: 1 * time.Second,
+		Preflight:      Preflight{},
+		Connections: Connections{
+			DisableKeepAlive: false,
+			MaxIdle:          0,
+			MaxPerHost:       0,
+			MaxStreams:       0,
+			Pool:             ConnPoolShared,
+		},
+		Records: Records{
+			Storage: RecordStorageMemory,
+			Dir:     "",
+		},
 	},
 	Output: Output{
 		Out:      []OutputStrategy{OutputStdout},
 		Silent:   false,
 		Template: "",
 	},
+	Data: Data{
+		File:  "",
+		Mode:  DataSequential,
+		OnEnd: DataWrap,
+	},
 }
 
 // Default returns a default config that is safe to use.
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+)
+
+// DNS modes.
+const (
+	// DNSPerConnection resolves the host names for every new connection.
+	DNSPerConnection = "perConnection"
+	// DNSOnce resolves each host name once per run.
+	DNSOnce = "once"
+)
+
+// DNS contains the options of the resolution of the host names
+// of a request.
+// Server is the address "ip:port" of the DNS server queried instead of
+// the system resolver. An empty Mode is read as DNSPerConnection.
+type DNS struct {
+	Server string
+	Mode   string
+}
+
+// validate returns a non-nil error if the DNS options are not valid.
+func (d DNS) validate() error {
+	if d.Server != "" {
+		if host, port, err := net.SplitHostPort(d.Server); err != nil ||
+			net.ParseIP(host) == nil || !isPort(port) {
+			return fmt.Errorf("server (%q): want <ip>:<port>", d.Server)
+		}
+	}
+	switch d.Mode {
+	case "", DNSPerConnection, DNSOnce:
+	default:
+		return fmt.Errorf(`mode (%q): want one of "perConnection", "once"`, d.Mode)
+	}
+	return nil
+}
+
+// validateResolve returns a non-nil error if resolve does not map
+// addresses "host:port" to IP addresses.
+func validateResolve(resolve map[string]string) error {
+	addrs := make([]string, 0, len(resolve))
+	for addr := range resolve {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	for _, addr := range addrs {
+		if host, port, err := net.SplitHostPort(addr); err != nil || host == "" || !isPort(port) {
+			return fmt.Errorf("resolve (%q): want <host>:<port>", addr)
+		}
+		if ip := resolve[addr]; net.ParseIP(ip) == nil {
+			return fmt.Errorf("resolve[%q] (%q): want an IP address", addr, ip)
+		}
+	}
+	return nil
+}
+
+// isPort returns true if s is a valid port number.
+func isPort(s string) bool {
+	port, err := strconv.Atoi(s)
+	return err == nil && port > 0 && port <= 65535
+}
@@ -0,0 +1,61 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/benchttp/runner/config"
+)
+
+func TestDNS_validate(t *testing.T) {
+	t.Run("return nil for valid options", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Request = config.Request{
+			Resolve: map[string]string{"a.b:443": "10.0.0.1", "a.b:80": "::1"},
+			DNS:     config.DNS{Server: "[::1]:53", Mode: config.DNSOnce},
+		}.WithURL("https://a.b")
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("return errors for invalid options", func(t *testing.T) {
+		testcases := []struct {
+			resolve map[string]string
+			dns     config.DNS
+			expErr  string
+		}{
+			{
+				resolve: map[string]string{"a.b": "10.0.0.1"},
+				expErr:  `resolve ("a.b"): want <host>:<port>`,
+			},
+			{
+				resolve: map[string]string{"a.b:0": "10.0.0.1"},
+				expErr:  `resolve ("a.b:0"): want <host>:<port>`,
+			},
+			{
+				resolve: map[string]string{"a.b:443": "a.c"},
+				expErr:  `resolve["a.b:443"] ("a.c"): want an IP address`,
+			},
+			{
+				dns:    config.DNS{Server: "10.0.0.53"},
+				expErr: `dns.server ("10.0.0.53"): want <ip>:<port>`,
+			},
+			{
+				dns:    config.DNS{Mode: "always"},
+				expErr: `dns.mode ("always"): want one of "perConnection", "once"`,
+			},
+		}
+
+		for _, tc := range testcases {
+			cfg := config.Default()
+			cfg.Request = config.Request{Resolve: tc.resolve, DNS: tc.dns}.WithURL("https://a.b")
+
+			var errInvalid *config.InvalidConfigError
+			if err := cfg.Validate(); !errors.As(err, &errInvalid) {
+				t.Fatalf("%s: unexpected error: %v", tc.expErr, err)
+			}
+			findErrorOrFail(t, errInvalid.Errors, tc.expErr)
+		}
+	})
+}
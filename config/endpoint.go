@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Endpoint is a request of a weighted mix. Each iteration sends
+// the Request of a single Endpoint, picked with a probability
+// proportional to its Weight.
+type Endpoint struct {
+	Name    string
+	Weight  int
+	Request Request
+}
+
+// validate returns the errors of the Endpoint, prefixed with its index i.
+func (e Endpoint) validate(i int) []error {
+	errs := []error{}
+	prefix := fmt.Sprintf("endpoints[%d]", i)
+
+	if e.Weight < 1 {
+		errs = append(errs, fmt.Errorf("%s.weight (%d): want > 0", prefix, e.Weight))
+	}
+
+	errs = append(errs, e.Request.validate(prefix+".")...)
+
+	return errs
+}
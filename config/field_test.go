@@ -16,12 +16,56 @@ func TestIsField(t *testing.T) {
 		{In: config.FieldBody, Exp: true},
 		{In: config.FieldRequests, Exp: true},
 		{In: config.FieldConcurrency, Exp: true},
+		{In: config.FieldRate, Exp: true},
+		{In: config.FieldStages, Exp: true},
 		{In: config.FieldInterval, Exp: true},
+		{In: config.FieldIntervalMode, Exp: true},
 		{In: config.FieldRequestTimeout, Exp: true},
 		{In: config.FieldGlobalTimeout, Exp: true},
+		{In: config.FieldDrain, Exp: true},
+		{In: config.FieldWarmup, Exp: true},
+		{In: config.FieldWarmupReport, Exp: true},
+		{In: config.FieldBucketInterval, Exp: true},
+		{In: config.FieldSkipPreflight, Exp: true},
+		{In: config.FieldPreflightURL, Exp: true},
+		{In: config.FieldPreflightMethod, Exp: true},
+		{In: config.FieldPreflightStatus, Exp: true},
+		{In: config.FieldPreflightMaxWait, Exp: true},
+		{In: config.FieldPreflightBackoff, Exp: true},
 		{In: config.FieldOut, Exp: true},
 		{In: config.FieldSilent, Exp: true},
 		{In: config.FieldTemplate, Exp: true},
+		{In: config.FieldSeed, Exp: true},
+		{In: config.FieldDisableKeepAlive, Exp: true},
+		{In: config.FieldMaxIdleConns, Exp: true},
+		{In: config.FieldMaxConnsPerHost, Exp: true},
+		{In: config.FieldMaxStreams, Exp: true},
+		{In: config.FieldProtocol, Exp: true},
+		{In: config.FieldTLSCA, Exp: true},
+		{In: config.FieldTLSCert, Exp: true},
+		{In: config.FieldTLSKey, Exp: true},
+		{In: config.FieldInsecure, Exp: true},
+		{In: config.FieldTLSServerName, Exp: true},
+		{In: config.FieldTLSMinVersion, Exp: true},
+		{In: config.FieldTLSMaxVersion, Exp: true},
+		{In: config.FieldTLSCiphers, Exp: true},
+		{In: config.FieldTLSSessionResumption, Exp: true},
+		{In: config.FieldProxy, Exp: true},
+		{In: config.FieldNoProxy, Exp: true},
+		{In: config.FieldResolve, Exp: true},
+		{In: config.FieldDNSServer, Exp: true},
+		{In: config.FieldDNSMode, Exp: true},
+		{In: config.FieldUnixSocket, Exp: true},
+		{In: config.FieldConnPool, Exp: true},
+		{In: config.FieldRecordStorage, Exp: true},
+		{In: config.FieldRecordDir, Exp: true},
+		{In: config.FieldDataFile, Exp: true},
+		{In: config.FieldDataMode, Exp: true},
+		{In: config.FieldDataOnEnd, Exp: true},
+		{In: config.FieldEndpoints, Exp: true},
+		{In: config.FieldScenario, Exp: true},
+		{In: config.FieldChecks, Exp: true},
+		{In: config.FieldThresholds, Exp: true},
 		{In: "notafield", Exp: false},
 	}).Run(t)
 }
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidInterval reports an interval that cannot be parsed.
+var ErrInvalidInterval = errors.New("invalid interval")
+
+// Interval modes.
+const (
+	// IntervalPerWorker pauses each worker after each of its iterations,
+	// as the think time of a user.
+	IntervalPerWorker = "perWorker"
+	// IntervalGlobal spaces the starts of the requests of all workers
+	// by at least a pause.
+	IntervalGlobal = "global"
+)
+
+// Interval distributions.
+const (
+	// IntervalConstant pauses for Duration.
+	IntervalConstant = "constant"
+	// IntervalUniform pauses for a duration drawn uniformly between
+	// Min and Max.
+	IntervalUniform = "uniform"
+	// IntervalExponential pauses for a duration drawn from an exponential
+	// distribution of mean Duration.
+	IntervalExponential = "exponential"
+	// IntervalNormal pauses for a duration drawn from a normal distribution
+	// of mean Duration and standard deviation StdDev, negative draws
+	// pausing for 0.
+	IntervalNormal = "normal"
+)
+
+// Interval contains the options of the pauses between the requests.
+// Their semantics depend on Mode (see IntervalPerWorker, IntervalGlobal)
+// and their durations are drawn from Distribution (see IntervalConstant,
+// IntervalUniform, IntervalExponential, IntervalNormal), an empty
+// Distribution being IntervalConstant.
+type Interval struct {
+	Mode         string
+	Distribution string
+	Duration     time.Duration
+	Min          time.Duration
+	Max          time.Duration
+	StdDev       time.Duration
+}
+
+// ParseInterval parses a raw interval, a duration (e.g. "200ms") or
+// a distribution followed by its durations ("uniform:<min>,<max>",
+// "exponential:<mean>", "normal:<mean>,<stddev>"), and returns it
+// as an Interval, or a non-nil error wrapping ErrInvalidInterval
+// if raw is neither. The Mode of the returned Interval is not set.
+func ParseInterval(raw string) (Interval, error) {
+	if raw == "" {
+		return Interval{}, nil
+	}
+
+	distribution, params := IntervalConstant, raw
+	if i := strings.IndexByte(raw, ':'); i != -1 {
+		distribution, params = raw[:i], raw[i+1:]
+	}
+
+	var durations []time.Duration
+	for _, s := range strings.Split(params, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			durations = nil
+			break
+		}
+		durations = append(durations, d)
+	}
+
+	switch {
+	case len(durations) == 1 && (distribution == IntervalConstant || distribution == IntervalExponential):
+		return Interval{Distribution: distribution, Duration: durations[0]}, nil
+	case len(durations) == 2 && distribution == IntervalUniform:
+		return Interval{Distribution: distribution, Min: durations[0], Max: durations[1]}, nil
+	case len(durations) == 2 && distribution == IntervalNormal:
+		return Interval{Distribution: distribution, Duration: durations[0], StdDev: durations[1]}, nil
+	}
+	return Interval{}, fmt.Errorf(
+		`%w: expect a duration or a distribution (e.g. "uniform:100ms,300ms", "exponential:200ms", "normal:200ms,50ms"), got "%s"`,
+		ErrInvalidInterval, raw,
+	)
+}
+
+// String returns the duration or the distribution of the Interval
+// in the format read by ParseInterval.
+func (i Interval) String() string {
+	switch i.Distribution {
+	case IntervalUniform:
+		return fmt.Sprintf("%s:%s,%s", i.Distribution, i.Min, i.Max)
+	case IntervalExponential:
+		return fmt.Sprintf("%s:%s", i.Distribution, i.Duration)
+	case IntervalNormal:
+		return fmt.Sprintf("%s:%s,%s", i.Distribution, i.Duration, i.StdDev)
+	}
+	return i.Duration.String()
+}
+
+// validate returns a non-nil error if the Interval options are not valid.
+func (i Interval) validate() error {
+	switch i.Mode {
+	case "", IntervalPerWorker, IntervalGlobal:
+	default:
+		return fmt.Errorf(`mode (%q): want one of "perWorker", "global"`, i.Mode)
+	}
+
+	switch i.Distribution {
+	case "", IntervalConstant, IntervalExponential:
+		if i.Duration < 0 {
+			return fmt.Errorf("duration (%d): want >= 0", i.Duration)
+		}
+	case IntervalUniform:
+		if i.Min < 0 {
+			return fmt.Errorf("min (%d): want >= 0", i.Min)
+		}
+		if i.Max < i.Min {
+			return fmt.Errorf("max (%d): want >= min (%d)", i.Max, i.Min)
+		}
+	case IntervalNormal:
+		if i.Duration < 0 {
+			return fmt.Errorf("duration (%d): want >= 0", i.Duration)
+		}
+		if i.StdDev < 0 {
+			return fmt.Errorf("stdDev (%d): want >= 0", i.StdDev)
+		}
+	default:
+		return fmt.Errorf(
+			`distribution (%q): want one of "constant", "uniform", "exponential", "normal"`,
+			i.Distribution,
+		)
+	}
+	return nil
+}
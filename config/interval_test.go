@@ -0,0 +1,61 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benchttp/runner/config"
+)
+
+func TestParseInterval(t *testing.T) {
+	t.Run("return parsed interval", func(t *testing.T) {
+		testcases := []struct {
+			in  string
+			exp config.Interval
+		}{
+			{in: "", exp: config.Interval{}},
+			{
+				in:  "200ms",
+				exp: config.Interval{Distribution: config.IntervalConstant, Duration: 200 * time.Millisecond},
+			},
+			{
+				in:  "uniform:100ms,300ms",
+				exp: config.Interval{Distribution: config.IntervalUniform, Min: 100 * time.Millisecond, Max: 300 * time.Millisecond},
+			},
+			{
+				in:  "exponential:1s",
+				exp: config.Interval{Distribution: config.IntervalExponential, Duration: time.Second},
+			},
+			{
+				in:  "normal:200ms, 50ms",
+				exp: config.Interval{Distribution: config.IntervalNormal, Duration: 200 * time.Millisecond, StdDev: 50 * time.Millisecond},
+			},
+		}
+
+		for _, tc := range testcases {
+			t.Run(tc.in, func(t *testing.T) {
+				got, err := config.ParseInterval(tc.in)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != tc.exp {
+					t.Errorf("\nexp %#v\ngot %#v", tc.exp, got)
+				}
+				if tc.in != "" {
+					if back, _ := config.ParseInterval(got.String()); back != got {
+						t.Errorf("exp String() to parse back to %#v, got %#v", got, back)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("return ErrInvalidInterval if input is invalid", func(t *testing.T) {
+		for _, in := range []string{"soon", "uniform:100ms", "normal:1s,2s,3s", "poisson:1s", "exponential:1x"} {
+			if _, err := config.ParseInterval(in); !errors.Is(err, config.ErrInvalidInterval) {
+				t.Errorf("%q: exp ErrInvalidInterval, got %v", in, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Preflight contains the options of the check sent before the run to
+// make sure the server is ready. Unless Skip is true, the first request
+// of each endpoint is sent once, or a request of Method to URL if it is
+// set. The server is ready if the response has one of the statuses
+// Status, or any status if it is empty. If MaxWait is set, the check is
+// retried until the server is ready or MaxWait is elapsed, waiting
+// Backoff then twice as long between the attempts.
+type Preflight struct {
+	Skip    bool
+	URL     string
+	Method  string
+	Status  string
+	MaxWait time.Duration
+	Backoff time.Duration
+}
+
+// validate returns a non-nil error if the Preflight options are not valid.
+func (p Preflight) validate() error {
+	if p.URL != "" {
+		if u, err := url.ParseRequestURI(p.URL); err != nil || u.Host == "" {
+			return fmt.Errorf("url (%q): want an absolute URL", p.URL)
+		}
+	}
+	if p.Method != "" && p.URL == "" {
+		return fmt.Errorf("method (%q): want url", p.Method)
+	}
+	if p.Status != "" {
+		if err := validateStatus(p.Status); err != nil {
+			return fmt.Errorf("status: %s", err)
+		}
+	}
+	if p.MaxWait < 0 {
+		return fmt.Errorf("maxWait (%d): want >= 0", p.MaxWait)
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("backoff (%d): want >= 0", p.Backoff)
+	}
+	return nil
+}
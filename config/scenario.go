@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Extract sources.
+const (
+	// ExtractJSON extracts the value found at the JSON path Key
+	// in the response body.
+	ExtractJSON = "json"
+	// ExtractHeader extracts the first value of the response header Key.
+	ExtractHeader = "header"
+	// ExtractRegexp extracts the first match of the regular expression Key
+	// in the response body, or its first capturing group if any.
+	ExtractRegexp = "regexp"
+)
+
+// Extract describes a value to extract from a response into a variable
+// Name, that can be used in the request of the next steps of a scenario
+// using placeholder "${Name}".
+type Extract struct {
+	Name   string
+	Source string
+	Key    string
+}
+
+// Step is a single request of a scenario. Its request URL, header
+// and body can use the values extracted by the previous steps.
+type Step struct {
+	Name    string
+	Request Request
+	Checks  []Check
+	Extract []Extract
+}
+
+// validate returns the errors of the Step, prefixed with its index i.
+func (s Step) validate(i int) []error {
+	errs := []error{}
+	prefix := fmt.Sprintf("scenario[%d]", i)
+
+	errs = append(errs, s.Request.validate(prefix+".")...)
+
+	for j, check := range s.Checks {
+		if err := check.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s.checks[%d] (%q): %s", prefix, j, check, err))
+		}
+	}
+
+	for j, extract := range s.Extract {
+		if err := extract.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s.extract[%d] (%q): %s", prefix, j, extract.Name, err))
+		}
+	}
+
+	return errs
+}
+
+// validate returns a non-nil error if the Extract is not valid
+// for its Source.
+func (e Extract) validate() error {
+	if e.Name == "" {
+		return errors.New("missing name")
+	}
+	if e.Key == "" {
+		return errors.New("missing key")
+	}
+	switch e.Source {
+	case ExtractJSON, ExtractHeader:
+	case ExtractRegexp:
+		if _, err := regexp.Compile(e.Key); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf(`unknown source %q, want one of "json", "header", "regexp"`, e.Source)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidThreshold reports a threshold that cannot be parsed.
+var ErrInvalidThreshold = errors.New("invalid threshold")
+
+// Threshold is a pass/fail condition checked against a metric of the
+// benchmark results once it is done, e.g. "p95 < 300ms".
+//
+// Value is expressed in the unit of the Metric:
+//   - nanoseconds for durations: "min", "max", "mean", "stdDev", "p50",
+//     "p75", "p90", "p95", "p99", "p999"
+//   - percents for "errorRate"
+//   - requests per second for "rps"
+//   - a number of requests for counts: "requests", "errors", "dropped",
+//     "late", "status.<code>" (e.g. "status.404" or "status.5xx")
+type Threshold struct {
+	Metric   string
+	Operator string
+	Value    float64
+}
+
+// durationMetrics lists the metrics of a Threshold that are durations.
+var durationMetrics = []string{
+	"min", "max", "mean", "stdDev",
+	"p50", "p75", "p90", "p95", "p99", "p999",
+}
+
+// thresholdOperators lists the accepted operators of a Threshold.
+var thresholdOperators = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+var (
+	thresholdRegexp    = regexp.MustCompile(`^\s*([\w.]+)\s*(<=|>=|==|!=|<|>)\s*(\S+)\s*$`)
+	statusMetricRegexp = regexp.MustCompile(`^status\.[1-5]([0-9]{2}|xx)$`)
+)
+
+// ParseThreshold parses a raw threshold in format "<metric> <operator> <value>"
+// and returns it as a Threshold, or a non-nil error wrapping
+// ErrInvalidThreshold if raw is not a valid threshold.
+func ParseThreshold(raw string) (Threshold, error) {
+	matches := thresholdRegexp.FindStringSubmatch(raw)
+	if matches == nil {
+		return Threshold{}, fmt.Errorf(
+			`%w: expect format "<metric> <operator> <value>", got "%s"`,
+			ErrInvalidThreshold, raw,
+		)
+	}
+
+	metric, operator, rawValue := matches[1], matches[2], matches[3]
+	if !isThresholdMetric(metric) {
+		return Threshold{}, fmt.Errorf("%w: unknown metric %q", ErrInvalidThreshold, metric)
+	}
+
+	value, err := parseThresholdValue(metric, rawValue)
+	if err != nil {
+		return Threshold{}, fmt.Errorf("%w: %s: %s", ErrInvalidThreshold, metric, err)
+	}
+
+	return Threshold{Metric: metric, Operator: operator, Value: value}, nil
+}
+
+// parseThresholdValue parses raw as a value of the given metric.
+func parseThresholdValue(metric, raw string) (float64, error) {
+	switch {
+	case isDurationMetric(metric):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf(`expect a duration, got "%s"`, raw)
+		}
+		return float64(d), nil
+	case metric == "errorRate":
+		if !strings.HasSuffix(raw, "%") {
+			return 0, fmt.Errorf(`expect a percentage, got "%s"`, raw)
+		}
+		return strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+	default:
+		return strconv.ParseFloat(raw, 64)
+	}
+}
+
+// String returns a string representation of the Threshold,
+// in the same format as accepted by ParseThreshold.
+func (t Threshold) String() string {
+	return fmt.Sprintf("%s %s %s", t.Metric, t.Operator, t.FormatValue(t.Value))
+}
+
+// FormatValue returns v as a string in the unit of the Threshold's metric.
+// For readability, durations are rounded to the microsecond and other
+// values to 2 decimals.
+func (t Threshold) FormatValue(v float64) string {
+	if isDurationMetric(t.Metric) {
+		return time.Duration(v).Round(time.Microsecond).String()
+	}
+
+	s := strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	if t.Metric == "errorRate" {
+		return s + "%"
+	}
+	return s
+}
+
+// Check returns true if v satisfies the Threshold.
+func (t Threshold) Check(v float64) bool {
+	switch t.Operator {
+	case "<":
+		return v < t.Value
+	case "<=":
+		return v <= t.Value
+	case ">":
+		return v > t.Value
+	case ">=":
+		return v >= t.Value
+	case "==":
+		return v == t.Value
+	case "!=":
+		return v != t.Value
+	}
+	return false
+}
+
+// validate returns a non-nil error if the Threshold's metric or operator
+// is unknown.
+func (t Threshold) validate() error {
+	if !isThresholdMetric(t.Metric) {
+		return fmt.Errorf("unknown metric %q", t.Metric)
+	}
+	for _, op := range thresholdOperators {
+		if t.Operator == op {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown operator %q", t.Operator)
+}
+
+// isThresholdMetric returns true if metric is an accepted Threshold metric.
+func isThresholdMetric(metric string) bool {
+	switch metric {
+	case "errorRate", "rps", "requests", "errors", "dropped", "late":
+		return true
+	}
+	return isDurationMetric(metric) || statusMetricRegexp.MatchString(metric)
+}
+
+// isDurationMetric returns true if metric is listed in durationMetrics.
+func isDurationMetric(metric string) bool {
+	for _, m := range durationMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benchttp/runner/config"
+)
+
+func TestParseThreshold(t *testing.T) {
+	t.Run("return parsed threshold", func(t *testing.T) {
+		testcases := []struct {
+			in  string
+			exp config.Threshold
+		}{
+			{
+				in:  "p95 < 300ms",
+				exp: config.Threshold{Metric: "p95", Operator: "<", Value: float64(300 * time.Millisecond)},
+			},
+			{
+				in:  "errorRate<=1.5%",
+				exp: config.Threshold{Metric: "errorRate", Operator: "<=", Value: 1.5},
+			},
+			{
+				in:  "  rps > 500 ",
+				exp: config.Threshold{Metric: "rps", Operator: ">", Value: 500},
+			},
+			{
+				in:  "status.5xx == 0",
+				exp: config.Threshold{Metric: "status.5xx", Operator: "==", Value: 0},
+			},
+			{
+				in:  "status.404 != 3",
+				exp: config.Threshold{Metric: "status.404", Operator: "!=", Value: 3},
+			},
+		}
+
+		for _, tc := range testcases {
+			t.Run(tc.in, func(t *testing.T) {
+				got, err := config.ParseThreshold(tc.in)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != tc.exp {
+					t.Errorf("\nexp %#v\ngot %#v", tc.exp, got)
+				}
+			})
+		}
+	})
+
+	t.Run("return ErrInvalidThreshold if input is invalid", func(t *testing.T) {
+		for _, in := range []string{
+			"p95",
+			"p95 ~ 300ms",
+			"p42 < 300ms",
+			"p95 < 300",
+			"errorRate < 1",
+			"rps > many",
+			"status.6xx == 0",
+		} {
+			t.Run(in, func(t *testing.T) {
+				if _, err := config.ParseThreshold(in); !errors.Is(err, config.ErrInvalidThreshold) {
+					t.Errorf("exp ErrInvalidThreshold, got %v", err)
+				}
+			})
+		}
+	})
+}
+
+func TestThreshold_Check(t *testing.T) {
+	testcases := []struct {
+		operator string
+		value    float64
+		exp      bool
+	}{
+		{operator: "<", value: 1, exp: true},
+		{operator: "<", value: 2, exp: false},
+		{operator: "<=", value: 2, exp: true},
+		{operator: ">", value: 2, exp: false},
+		{operator: ">", value: 3, exp: true},
+		{operator: ">=", value: 2, exp: true},
+		{operator: "==", value: 2, exp: true},
+		{operator: "!=", value: 2, exp: false},
+	}
+
+	for _, tc := range testcases {
+		threshold := config.Threshold{Metric: "rps", Operator: tc.operator, Value: 2}
+		if got := threshold.Check(tc.value); got != tc.exp {
+			t.Errorf("%v with %v: exp %v, got %v", threshold, tc.value, tc.exp, got)
+		}
+	}
+}
+
+func TestThreshold_String(t *testing.T) {
+	for _, raw := range []string{"p95 < 300ms", "errorRate <= 1.5%", "status.5xx == 0"} {
+		threshold, err := config.ParseThreshold(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := threshold.String(); got != raw {
+			t.Errorf("exp %q, got %q", raw, got)
+		}
+	}
+}
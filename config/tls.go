@@ -0,0 +1,133 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// tlsVersions maps the accepted TLS versions to their values.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLS contains the TLS options of a request.
+// CAFile is a PEM bundle of the certificate authorities trusted in addition
+// to the system ones. CertFile and KeyFile are the PEM files of a client
+// certificate and its key, sent to servers requiring mutual TLS.
+// MinVersion and MaxVersion are TLS versions in format "1.2".
+// CipherSuites are the names of the cipher suites enabled for TLS 1.0
+// to 1.2, such as "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256".
+// SessionResumption enables resuming the TLS sessions of closed
+// connections, so that new connections skip the full handshake.
+type TLS struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+	ServerName         string
+	MinVersion         string
+	MaxVersion         string
+	CipherSuites       []string
+	SessionResumption  bool
+}
+
+// isZero returns true if no option of the TLS is set.
+func (t TLS) isZero() bool {
+	return t.CAFile == "" && t.CertFile == "" && t.KeyFile == "" &&
+		!t.InsecureSkipVerify && t.ServerName == "" &&
+		t.MinVersion == "" && t.MaxVersion == "" &&
+		len(t.CipherSuites) == 0 && !t.SessionResumption
+}
+
+// validate returns a non-nil error if the TLS options are not valid.
+func (t TLS) validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("cert and key: want both or none")
+	}
+
+	for _, v := range []string{t.MinVersion, t.MaxVersion} {
+		if _, ok := tlsVersions[v]; v != "" && !ok {
+			return fmt.Errorf(`version (%q): want one of "1.0", "1.1", "1.2", "1.3"`, v)
+		}
+	}
+	if t.MinVersion != "" && t.MaxVersion != "" &&
+		tlsVersions[t.MinVersion] > tlsVersions[t.MaxVersion] {
+		return fmt.Errorf("minVersion (%s): want <= maxVersion (%s)", t.MinVersion, t.MaxVersion)
+	}
+
+	for _, name := range t.CipherSuites {
+		if _, ok := cipherSuiteID(name); !ok {
+			return fmt.Errorf("cipherSuites (%q): unknown cipher suite", name)
+		}
+	}
+	return nil
+}
+
+// Value generates a *tls.Config based on TLS and returns it or any
+// non-nil error that occurred reading the files. It returns nil if
+// no option is set, so that the default configuration is used.
+func (t TLS) Value() (*tls.Config, error) {
+	if t.isZero() {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: t.InsecureSkipVerify, //nolint:gosec // explicitly set by the user
+		ServerName:         t.ServerName,
+		MinVersion:         tlsVersions[t.MinVersion],
+		MaxVersion:         tlsVersions[t.MaxVersion],
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls ca: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca: %s: no PEM certificate found", t.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls cert: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	for _, name := range t.CipherSuites {
+		id, _ := cipherSuiteID(name)
+		cfg.CipherSuites = append(cfg.CipherSuites, id)
+	}
+
+	if t.SessionResumption {
+		cfg.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	}
+
+	return cfg, nil
+}
+
+// cipherSuiteID returns the ID of the cipher suite name, or false
+// if it is unknown.
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, s := range suites {
+			if s.Name == name {
+				return s.ID, true
+			}
+		}
+	}
+	return 0, false
+}
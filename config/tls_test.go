@@ -0,0 +1,172 @@
+package config_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benchttp/runner/config"
+)
+
+func TestTLS_Value(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+
+	t.Run("return nil config if no option is set", func(t *testing.T) {
+		cfg, err := config.TLS{}.Value()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg != nil {
+			t.Errorf("exp nil config, got %v", cfg)
+		}
+	})
+
+	t.Run("apply options", func(t *testing.T) {
+		cfg, err := config.TLS{
+			InsecureSkipVerify: true,
+			ServerName:         "a.b",
+			MinVersion:         "1.1",
+			MaxVersion:         "1.2",
+			CipherSuites:       []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			SessionResumption:  true,
+		}.Value()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !cfg.InsecureSkipVerify {
+			t.Error("exp insecure skip verify")
+		}
+		if cfg.ServerName != "a.b" {
+			t.Errorf("exp server name %q, got %q", "a.b", cfg.ServerName)
+		}
+		if cfg.MinVersion != tls.VersionTLS11 || cfg.MaxVersion != tls.VersionTLS12 {
+			t.Errorf("exp versions %x-%x, got %x-%x", tls.VersionTLS11, tls.VersionTLS12, cfg.MinVersion, cfg.MaxVersion)
+		}
+		if exp := tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256; len(cfg.CipherSuites) != 1 || cfg.CipherSuites[0] != exp {
+			t.Errorf("exp cipher suites [%x], got %x", exp, cfg.CipherSuites)
+		}
+		if cfg.ClientSessionCache == nil {
+			t.Error("exp session cache")
+		}
+	})
+
+	t.Run("load ca and client certificate", func(t *testing.T) {
+		cfg, err := config.TLS{CAFile: certFile, CertFile: certFile, KeyFile: keyFile}.Value()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.RootCAs == nil {
+			t.Error("exp root CAs")
+		}
+		if len(cfg.Certificates) != 1 {
+			t.Errorf("exp 1 client certificate, got %d", len(cfg.Certificates))
+		}
+	})
+
+	t.Run("return error for bad files", func(t *testing.T) {
+		missing := filepath.Join(dir, "missing.pem")
+		for _, tlsConfig := range []config.TLS{
+			{CAFile: missing},
+			{CAFile: keyFile},
+			{CertFile: missing, KeyFile: keyFile},
+			{CertFile: certFile, KeyFile: certFile},
+		} {
+			cfg, err := tlsConfig.Value()
+			if err == nil {
+				t.Errorf("%+v: exp error, got nil", tlsConfig)
+			}
+			if cfg != nil {
+				t.Errorf("%+v: exp nil config, got %v", tlsConfig, cfg)
+			}
+		}
+	})
+}
+
+func TestTLS_validate(t *testing.T) {
+	testcases := []struct {
+		tls    config.TLS
+		expErr string
+	}{
+		{
+			tls:    config.TLS{KeyFile: "key.pem"},
+			expErr: `tls: cert and key: want both or none`,
+		},
+		{
+			tls:    config.TLS{MinVersion: "1.4"},
+			expErr: `tls: version ("1.4"): want one of "1.0", "1.1", "1.2", "1.3"`,
+		},
+		{
+			tls:    config.TLS{MinVersion: "1.3", MaxVersion: "1.2"},
+			expErr: `tls: minVersion (1.3): want <= maxVersion (1.2)`,
+		},
+		{
+			tls:    config.TLS{CipherSuites: []string{"bad"}},
+			expErr: `tls: cipherSuites ("bad"): unknown cipher suite`,
+		},
+	}
+
+	for _, tc := range testcases {
+		cfg := config.Default()
+		cfg.Request = config.Request{TLS: tc.tls}.WithURL("https://a.b")
+
+		var errInvalid *config.InvalidConfigError
+		if err := cfg.Validate(); !errors.As(err, &errInvalid) {
+			t.Fatalf("%s: unexpected error: %v", tc.expErr, err)
+		}
+		findErrorOrFail(t, errInvalid.Errors, tc.expErr)
+	}
+}
+
+// helpers
+
+// writeCertificate writes a self-signed certificate and its key as PEM
+// files in dir and returns their paths.
+func writeCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "benchttp test"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, filename, typ string, der []byte) {
+	t.Helper()
+	b := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	if err := os.WriteFile(filename, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
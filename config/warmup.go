@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidWarmup reports a warmup that cannot be parsed.
+var ErrInvalidWarmup = errors.New("invalid warmup")
+
+// Warmup contains the options of the warmup phase, run before the
+// measured run with the same concurrency and rate, or at the target of
+// the first stage if stages are set, so that it starts with warm
+// connections and server. It lasts Requests iterations or Duration,
+// none of them being set disabling it. Its results are excluded from
+// the stats and thresholds, and reported apart if Report is true.
+type Warmup struct {
+	Requests int
+	Duration time.Duration
+	Report   bool
+}
+
+// ParseWarmup parses a raw warmup, a number of requests (e.g. "100")
+// or a duration (e.g. "10s"), and returns it as a Warmup, or a non-nil
+// error wrapping ErrInvalidWarmup if raw is neither.
+func ParseWarmup(raw string) (Warmup, error) {
+	if raw == "" {
+		return Warmup{}, nil
+	}
+	if n, err := strconv.Atoi(raw); err == nil {
+		return Warmup{Requests: n}, nil
+	}
+	if d, err := time.ParseDuration(raw); err == nil {
+		return Warmup{Duration: d}, nil
+	}
+	return Warmup{}, fmt.Errorf(
+		`%w: expect a number of requests or a duration, got "%s"`,
+		ErrInvalidWarmup, raw,
+	)
+}
+
+// String returns the number of requests or the duration of the Warmup,
+// or "0" if it is disabled.
+func (w Warmup) String() string {
+	if w.Duration > 0 {
+		return w.Duration.String()
+	}
+	return strconv.Itoa(w.Requests)
+}
+
+// validate returns a non-nil error if the Warmup options are not valid.
+func (w Warmup) validate() error {
+	switch {
+	case w.Requests < 0:
+		return fmt.Errorf("requests (%d): want >= 0", w.Requests)
+	case w.Duration < 0:
+		return fmt.Errorf("duration (%d): want >= 0", w.Duration)
+	case w.Requests > 0 && w.Duration > 0:
+		return errors.New("want requests or duration, not both")
+	}
+	return nil
+}
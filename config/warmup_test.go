@@ -0,0 +1,42 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benchttp/runner/config"
+)
+
+func TestParseWarmup(t *testing.T) {
+	t.Run("return parsed warmup", func(t *testing.T) {
+		testcases := []struct {
+			in  string
+			exp config.Warmup
+		}{
+			{in: "", exp: config.Warmup{}},
+			{in: "100", exp: config.Warmup{Requests: 100}},
+			{in: "10s", exp: config.Warmup{Duration: 10 * time.Second}},
+		}
+
+		for _, tc := range testcases {
+			t.Run(tc.in, func(t *testing.T) {
+				got, err := config.ParseWarmup(tc.in)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != tc.exp {
+					t.Errorf("\nexp %#v\ngot %#v", tc.exp, got)
+				}
+			})
+		}
+	})
+
+	t.Run("return ErrInvalidWarmup if input is invalid", func(t *testing.T) {
+		for _, in := range []string{"many", "10 s", "1.5"} {
+			if _, err := config.ParseWarmup(in); !errors.Is(err, config.ErrInvalidWarmup) {
+				t.Errorf("%q: exp ErrInvalidWarmup, got %v", in, err)
+			}
+		}
+	})
+}
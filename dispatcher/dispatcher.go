@@ -5,19 +5,37 @@ import (
 	"errors"
 	"fmt"
 	"sync"
-
-	"golang.org/x/sync/semaphore"
+	"sync/atomic"
+	"time"
 )
 
 var ErrInvalidValue = errors.New("invalid value")
 
 type Dispatcher interface {
 	Do(ctx context.Context, maxIter int, callback func()) error
+	// SetNumWorker changes the maximum number of iterations running
+	// at the same time. It can be called while Do is running.
+	SetNumWorker(numWorker int)
+	// SetRate changes the number of iterations started per second.
+	// A rate of 0 switches the Dispatcher back to a closed-loop mode.
+	// It can be called while Do is running.
+	SetRate(rate int)
+	// Dropped returns the number of iterations that could not be started
+	// on schedule because all workers were busy. It is always 0 for
+	// a Dispatcher that is not rate-based.
+	Dropped() int
+	// Late returns the number of iterations started after their scheduled
+	// time, the Dispatcher having fallen behind the rate. It is always 0
+	// for a Dispatcher that is not rate-based.
+	Late() int
 }
 
 type dispatcher struct {
 	numWorker int
-	sem       *semaphore.Weighted
+	limiter   *limiter
+	interval  int64 // time.Duration, accessed atomically
+	dropped   int64
+	late      int64
 }
 
 // New returns a Dispatcher initialized with numWorker.
@@ -25,41 +43,69 @@ func New(numWorker int) Dispatcher {
 	if numWorker < 1 {
 		panic(fmt.Sprintf("invalid numWorker value: must be > 1, got %d", numWorker))
 	}
-	sem := semaphore.NewWeighted(int64(numWorker))
-	return dispatcher{sem: sem, numWorker: numWorker}
+	return &dispatcher{limiter: newLimiter(numWorker), numWorker: numWorker}
+}
+
+// NewRate returns a rate-based Dispatcher initialized with numWorker
+// and rate. Contrary to a Dispatcher returned by New, it starts rate
+// iterations per second on a fixed schedule, regardless of the duration
+// of the previous ones. numWorker is the maximum number of iterations
+// running at the same time.
+func NewRate(numWorker, rate int) Dispatcher {
+	if rate < 1 {
+		panic(fmt.Sprintf("invalid rate value: must be >= 1, got %d", rate))
+	}
+	d := New(numWorker)
+	d.SetRate(rate)
+	return d
 }
 
 // Do concurrently executes callback at most maxIter times or until ctx is done
 // or canceled. Concurrency is handled leveraging the semaphore pattern, which
-// ensures at most Dispatcher.numWorkers goroutines are spawned at the same time.
+// ensures at most numWorker goroutines are spawned at the same time, numWorker
+// being the latest value set via New or SetNumWorker.
+// If a rate is set, iterations are started on a fixed schedule instead of
+// as soon as a worker is available (see schedule).
 // It returns an early ErrInvalidValue if any of the following conditions is met:
 // 	maxIter < 1 and maxIter != -1
 // 	maxIter > numWorker
 // 	callback == nil
 // Else it returns the context error if any or nil.
-func (d dispatcher) Do(ctx context.Context, maxIter int, callback func()) error {
+func (d *dispatcher) Do(ctx context.Context, maxIter int, callback func()) error {
 	if err := d.validate(maxIter, callback); err != nil {
 		return err
 	}
 
 	var (
-		err error
-		wg  sync.WaitGroup
+		err  error
+		wg   sync.WaitGroup
+		next time.Time // next scheduled start in rate mode
 	)
 
 	for i := 0; i < maxIter || maxIter == -1; i++ {
-		wg.Add(1)
+		interval := time.Duration(atomic.LoadInt64(&d.interval))
 
-		if err = d.sem.Acquire(ctx, 1); err != nil {
-			// err is either context.DeadlineExceeded or context.Canceled
-			// which are expected values so we stop the process silently.
-			wg.Done()
-			break
+		if interval == 0 {
+			next = time.Time{}
+			if err = d.limiter.acquire(ctx); err != nil {
+				// err is either context.DeadlineExceeded or context.Canceled
+				// which are expected values so we stop the process silently.
+				break
+			}
+		} else {
+			var started bool
+			if next, started, err = d.schedule(ctx, next, interval); err != nil {
+				break
+			}
+			if !started {
+				continue
+			}
 		}
 
+		wg.Add(1)
 		go func() {
 			defer func() {
-				d.sem.Release(1)
+				d.limiter.release()
 				wg.Done()
 			}()
 			callback()
@@ -70,7 +116,80 @@ func (d dispatcher) Do(ctx context.Context, maxIter int, callback func()) error
 	return err
 }
 
-func (d dispatcher) validate(maxIter int, callback func()) error {
+// schedule waits for the next iteration to be due given the previous
+// scheduled start prev and the current interval. It returns the time
+// the iteration was scheduled at and whether a worker slot was acquired.
+// If all workers are busy when the iteration is due, the iteration is
+// dropped and counted as such: it still counts towards maxIter, so that
+// the schedule is never shifted by a slow callback. If the iteration was
+// already due when schedule is called, it is started at once and counted
+// as late.
+func (d *dispatcher) schedule(
+	ctx context.Context,
+	prev time.Time,
+	interval time.Duration,
+) (next time.Time, started bool, err error) {
+	next = time.Now()
+	late := false
+	if !prev.IsZero() {
+		next = prev.Add(interval)
+		late = time.Until(next) < 0
+	}
+
+	if err := sleepUntil(ctx, next); err != nil {
+		return next, false, err
+	}
+
+	if !d.limiter.tryAcquire() {
+		atomic.AddInt64(&d.dropped, 1)
+		return next, false, nil
+	}
+	if late {
+		atomic.AddInt64(&d.late, 1)
+	}
+	return next, true, nil
+}
+
+// SetNumWorker changes the maximum number of iterations running
+// at the same time. It panics if numWorker < 1.
+func (d *dispatcher) SetNumWorker(numWorker int) {
+	if numWorker < 1 {
+		panic(fmt.Sprintf("invalid numWorker value: must be > 1, got %d", numWorker))
+	}
+	d.limiter.setLimit(numWorker)
+}
+
+// SetRate changes the number of iterations started per second.
+// Rates above one per nanosecond start the iterations one nanosecond
+// apart, the shortest interval. It panics if rate < 0.
+func (d *dispatcher) SetRate(rate int) {
+	if rate < 0 {
+		panic(fmt.Sprintf("invalid rate value: must be >= 0, got %d", rate))
+	}
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Second / time.Duration(rate)
+		if interval < 1 {
+			// an interval of 0 would switch back to the closed-loop mode
+			interval = 1
+		}
+	}
+	atomic.StoreInt64(&d.interval, int64(interval))
+}
+
+// Dropped returns the number of iterations that could not be started
+// on schedule because all workers were busy.
+func (d *dispatcher) Dropped() int {
+	return int(atomic.LoadInt64(&d.dropped))
+}
+
+// Late returns the number of iterations started after their scheduled
+// time.
+func (d *dispatcher) Late() int {
+	return int(atomic.LoadInt64(&d.late))
+}
+
+func (d *dispatcher) validate(maxIter int, callback func()) error {
 	if maxIter < 1 && maxIter != -1 {
 		return fmt.Errorf("%w: maxIter: must be -1 or >= 1, got %d", ErrInvalidValue, maxIter)
 	}
@@ -85,3 +204,23 @@ func (d dispatcher) validate(maxIter int, callback func()) error {
 	}
 	return nil
 }
+
+// sleepUntil blocks until t is reached or ctx is done, in which case
+// it returns the context error. If t is already passed, it returns
+// immediately.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
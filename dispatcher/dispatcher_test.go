@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -45,6 +46,30 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestNewRate(t *testing.T) {
+	t.Run("panic if rate < 1", func(t *testing.T) {
+		for _, rate := range []int{-1, 0} {
+			func(rate int) {
+				expMessage := fmt.Sprintf("invalid rate value: must be >= 1, got %d", rate)
+
+				defer func() {
+					if r := recover(); r != expMessage {
+						t.Errorf("unexpected panic message:\nexp %s\ngot %v", expMessage, r)
+					}
+				}()
+
+				dispatcher.NewRate(1, rate)
+			}(rate)
+		}
+	})
+
+	t.Run("return valid Dispatcher if rate > 0", func(t *testing.T) {
+		if d := dispatcher.NewRate(10, 100); d == nil {
+			t.Error("returned nil Dispatcher")
+		}
+	})
+}
+
 func TestDo(t *testing.T) {
 	t.Run("stop when maxIter is reached", func(t *testing.T) {
 		const (
@@ -257,6 +282,225 @@ func TestDo(t *testing.T) {
 	})
 }
 
+func TestDo_rate(t *testing.T) {
+	t.Run("start iterations on a fixed schedule", func(t *testing.T) {
+		const (
+			numWorker = 10
+			rate      = 100
+			maxIter   = 10
+			interval  = time.Second / rate
+
+			// callbacks last longer than the interval: a closed-loop
+			// dispatcher would be throttled, a rate-based one must not.
+			callbackDuration = 5 * interval
+			margin           = 10 * time.Millisecond
+		)
+
+		var (
+			mu           sync.Mutex
+			elapsedTimes = make([]time.Duration, 0, maxIter)
+		)
+
+		d := dispatcher.NewRate(numWorker, rate)
+
+		start := time.Now()
+		if err := d.Do(context.Background(), maxIter, func() {
+			mu.Lock()
+			elapsedTimes = append(elapsedTimes, time.Since(start))
+			mu.Unlock()
+			time.Sleep(callbackDuration)
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(elapsedTimes) != maxIter {
+			t.Fatalf("iterations: exp %d, got %d", maxIter, len(elapsedTimes))
+		}
+
+		for i, got := range elapsedTimes {
+			if exp := time.Duration(i) * interval; got < exp || got > exp+margin {
+				t.Errorf("iteration %d: exp start at %v, got %v", i, exp, got)
+			}
+		}
+
+		if dropped := d.Dropped(); dropped != 0 {
+			t.Errorf("dropped: exp 0, got %d", dropped)
+		}
+	})
+
+	t.Run("drop iterations when all workers are busy", func(t *testing.T) {
+		const (
+			numWorker = 1
+			rate      = 100
+			maxIter   = 10
+		)
+
+		gotIter := 0
+
+		d := dispatcher.NewRate(numWorker, rate)
+		if err := d.Do(context.Background(), maxIter, func() {
+			gotIter++
+			time.Sleep(25 * time.Millisecond)
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if gotDropped := d.Dropped(); gotDropped == 0 || gotIter+gotDropped != maxIter {
+			t.Errorf(
+				"exp dropped > 0 and iterations + dropped == %d, got %d + %d",
+				maxIter, gotIter, gotDropped,
+			)
+		}
+	})
+
+	t.Run("stay rate-based above one iteration per nanosecond", func(t *testing.T) {
+		const maxIter = 10
+
+		// a closed-loop dispatcher would wait for the worker,
+		// a rate-based one must drop the iterations instead.
+		d := dispatcher.NewRate(1, 2e9)
+		if err := d.Do(context.Background(), maxIter, func() {
+			time.Sleep(10 * time.Millisecond)
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if dropped := d.Dropped(); dropped == 0 {
+			t.Errorf("dropped: exp > 0, got %d", dropped)
+		}
+	})
+
+	t.Run("count iterations started late", func(t *testing.T) {
+		const maxIter = 10
+
+		// iterations due every nanosecond cannot be started on time
+		d := dispatcher.NewRate(maxIter, 2e9)
+		if err := d.Do(context.Background(), maxIter, func() {}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if late, dropped := d.Late(), d.Dropped(); late == 0 || late+dropped > maxIter-1 {
+			t.Errorf("exp late > 0 and late + dropped <= %d, got %d + %d", maxIter-1, late, dropped)
+		}
+	})
+
+	t.Run("stop on context timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := dispatcher.NewRate(1, 100).Do(ctx, -1, func() {})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error:\nexp %v\ngot %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestDo_resize(t *testing.T) {
+	t.Run("apply new numWorker while running", func(t *testing.T) {
+		const (
+			interval = 10 * time.Millisecond
+			maxIter  = 60
+
+			fromNumWorker = 1
+			toNumWorker   = 5
+		)
+
+		var (
+			mu         sync.Mutex
+			running    int
+			maxRunning int
+		)
+
+		d := dispatcher.New(fromNumWorker)
+
+		go func() {
+			time.Sleep(5 * interval)
+			d.SetNumWorker(toNumWorker)
+		}()
+
+		if err := d.Do(context.Background(), maxIter, func() {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(interval)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if maxRunning != toNumWorker {
+			t.Errorf("max concurrent workers: exp %d, got %d", toNumWorker, maxRunning)
+		}
+	})
+
+	t.Run("switch from closed loop to rate mode while running", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		var gotIter, closedLoopIter int64
+
+		d := dispatcher.New(1)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			d.SetRate(50) // 1 iteration every 20ms
+			atomic.StoreInt64(&closedLoopIter, atomic.LoadInt64(&gotIter))
+		}()
+
+		d.Do(ctx, -1, func() { //nolint:errcheck
+			atomic.AddInt64(&gotIter, 1)
+		})
+
+		// closed loop iterations are unbounded, after the switch about
+		// 5 iterations are expected in the remaining 80ms (1 immediate,
+		// then 1 every 20ms), with a margin of 1 for the transition.
+		var (
+			closedIter = atomic.LoadInt64(&closedLoopIter)
+			rateIter   = atomic.LoadInt64(&gotIter) - closedIter
+		)
+		if closedIter < 10 {
+			t.Errorf("closed loop iterations: exp >= 10, got %d", closedIter)
+		}
+		if rateIter > 6 {
+			t.Errorf("rate iterations: exp <= 6, got %d", rateIter)
+		}
+	})
+
+	t.Run("panic on invalid values", func(t *testing.T) {
+		for _, tc := range []struct {
+			label string
+			f     func(d dispatcher.Dispatcher)
+			exp   string
+		}{
+			{
+				label: "numWorker < 1",
+				f:     func(d dispatcher.Dispatcher) { d.SetNumWorker(0) },
+				exp:   "invalid numWorker value: must be > 1, got 0",
+			},
+			{
+				label: "rate < 0",
+				f:     func(d dispatcher.Dispatcher) { d.SetRate(-1) },
+				exp:   "invalid rate value: must be >= 0, got -1",
+			},
+		} {
+			t.Run(tc.label, func(t *testing.T) {
+				defer func() {
+					if r := recover(); r != tc.exp {
+						t.Errorf("unexpected panic message:\nexp %s\ngot %v", tc.exp, r)
+					}
+				}()
+				tc.f(dispatcher.New(1))
+			})
+		}
+	})
+}
+
 func TestValidate(t *testing.T) {
 	testcases := []struct {
 		label     string
@@ -0,0 +1,84 @@
+package dispatcher
+
+import (
+	"context"
+	"sync"
+)
+
+// limiter limits the number of concurrently running workers.
+// Contrary to a semaphore.Weighted, its limit can be changed while
+// workers are running: lowering it does not interrupt running workers
+// but prevents new ones from starting until enough of them are released.
+type limiter struct {
+	mu      sync.Mutex
+	limit   int
+	running int
+	// released is closed and replaced each time a slot may have been
+	// freed, waking up any goroutine waiting in acquire.
+	released chan struct{}
+}
+
+// newLimiter returns a limiter initialized with limit.
+func newLimiter(limit int) *limiter {
+	return &limiter{limit: limit, released: make(chan struct{})}
+}
+
+// acquire blocks until a slot is available or ctx is done, in which case
+// it returns the context error.
+func (l *limiter) acquire(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		l.mu.Lock()
+		if l.running < l.limit {
+			l.running++
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// tryAcquire acquires a slot without blocking. It returns false if no slot
+// is available.
+func (l *limiter) tryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running < l.limit {
+		l.running++
+		return true
+	}
+	return false
+}
+
+// release frees a slot previously acquired.
+func (l *limiter) release() {
+	l.mu.Lock()
+	l.running--
+	l.notify()
+	l.mu.Unlock()
+}
+
+// setLimit sets the maximum number of slots that can be acquired at the
+// same time.
+func (l *limiter) setLimit(limit int) {
+	l.mu.Lock()
+	l.limit = limit
+	l.notify()
+	l.mu.Unlock()
+}
+
+// notify wakes up goroutines waiting in acquire. l.mu must be held.
+func (l *limiter) notify() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
@@ -4,8 +4,11 @@ go 1.17
 
 require (
 	github.com/drykit-go/testx v1.2.0
-	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+	golang.org/x/net v0.17.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
 
-require github.com/drykit-go/cond v0.1.0 // indirect
+require (
+	github.com/drykit-go/cond v0.1.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+)
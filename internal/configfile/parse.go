@@ -1,11 +1,16 @@
 package configfile
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/benchttp/runner/config"
@@ -18,23 +23,44 @@ import (
 type unmarshaledConfig struct {
 	Extends *string `yaml:"extends" json:"extends"`
 
-	Request struct {
-		Method      *string             `yaml:"method" json:"method"`
-		URL         *string             `yaml:"url" json:"url"`
-		QueryParams map[string]string   `yaml:"queryParams" json:"queryParams"`
-		Header      map[string][]string `yaml:"header" json:"header"`
-		Body        *struct {
-			Type    string `yaml:"type" json:"type"`
-			Content string `yaml:"content" json:"content"`
-		} `yaml:"body" json:"body"`
-	} `yaml:"request" json:"request"`
+	Request unmarshaledRequest `yaml:"request" json:"request"`
 
 	Runner struct {
-		Requests       *int    `yaml:"requests" json:"requests"`
-		Concurrency    *int    `yaml:"concurrency" json:"concurrency"`
-		Interval       *string `yaml:"interval" json:"interval"`
-		RequestTimeout *string `yaml:"requestTimeout" json:"requestTimeout"`
-		GlobalTimeout  *string `yaml:"globalTimeout" json:"globalTimeout"`
+		Requests    *int `yaml:"requests" json:"requests"`
+		Concurrency *int `yaml:"concurrency" json:"concurrency"`
+		Rate        *int `yaml:"rate" json:"rate"`
+		Stages      *[]struct {
+			Duration    string `yaml:"duration" json:"duration"`
+			Concurrency int    `yaml:"concurrency" json:"concurrency"`
+			Rate        int    `yaml:"rate" json:"rate"`
+		} `yaml:"stages" json:"stages"`
+		Interval       *unmarshaledInterval `yaml:"interval" json:"interval"`
+		RequestTimeout *string              `yaml:"requestTimeout" json:"requestTimeout"`
+		GlobalTimeout  *string              `yaml:"globalTimeout" json:"globalTimeout"`
+		Drain          *string              `yaml:"drain" json:"drain"`
+		Warmup         interface{}          `yaml:"warmup" json:"warmup"`
+		WarmupReport   *bool                `yaml:"warmupReport" json:"warmupReport"`
+		BucketInterval *string              `yaml:"bucketInterval" json:"bucketInterval"`
+		Preflight      struct {
+			Skip    *bool   `yaml:"skip" json:"skip"`
+			URL     *string `yaml:"url" json:"url"`
+			Method  *string `yaml:"method" json:"method"`
+			Status  *string `yaml:"status" json:"status"`
+			MaxWait *string `yaml:"maxWait" json:"maxWait"`
+			Backoff *string `yaml:"backoff" json:"backoff"`
+		} `yaml:"preflight" json:"preflight"`
+		Seed        *int64 `yaml:"seed" json:"seed"`
+		Connections struct {
+			DisableKeepAlive *bool   `yaml:"disableKeepAlive" json:"disableKeepAlive"`
+			MaxIdle          *int    `yaml:"maxIdle" json:"maxIdle"`
+			MaxPerHost       *int    `yaml:"maxPerHost" json:"maxPerHost"`
+			MaxStreams       *int    `yaml:"maxStreams" json:"maxStreams"`
+			Pool             *string `yaml:"pool" json:"pool"`
+		} `yaml:"connections" json:"connections"`
+		Records struct {
+			Storage *string `yaml:"storage" json:"storage"`
+			Dir     *string `yaml:"dir" json:"dir"`
+		} `yaml:"records" json:"records"`
 	} `yaml:"runner" json:"runner"`
 
 	Output struct {
@@ -42,6 +68,104 @@ type unmarshaledConfig struct {
 		Silent   *bool     `yaml:"silent" json:"silent"`
 		Template *string   `yaml:"template" json:"template"`
 	} `yaml:"output" json:"output"`
+
+	Data struct {
+		File  *string `yaml:"file" json:"file"`
+		Mode  *string `yaml:"mode" json:"mode"`
+		OnEnd *string `yaml:"onEnd" json:"onEnd"`
+	} `yaml:"data" json:"data"`
+
+	Endpoints *[]struct {
+		Name    string             `yaml:"name" json:"name"`
+		Weight  *int               `yaml:"weight" json:"weight"`
+		Request unmarshaledRequest `yaml:"request" json:"request"`
+	} `yaml:"endpoints" json:"endpoints"`
+
+	Scenario *[]struct {
+		Name    string             `yaml:"name" json:"name"`
+		Request unmarshaledRequest `yaml:"request" json:"request"`
+		Checks  *unmarshaledChecks `yaml:"checks" json:"checks"`
+		Extract map[string]struct {
+			JSON   *string `yaml:"json" json:"json"`
+			Header *string `yaml:"header" json:"header"`
+			Regexp *string `yaml:"regexp" json:"regexp"`
+		} `yaml:"extract" json:"extract"`
+	} `yaml:"scenario" json:"scenario"`
+
+	Checks *unmarshaledChecks `yaml:"checks" json:"checks"`
+
+	Thresholds *[]string `yaml:"thresholds" json:"thresholds"`
+}
+
+// unmarshaledRequest is a raw data model for a request in config files.
+type unmarshaledRequest struct {
+	Method      *string             `yaml:"method" json:"method"`
+	URL         *string             `yaml:"url" json:"url"`
+	QueryParams map[string]string   `yaml:"queryParams" json:"queryParams"`
+	Header      map[string][]string `yaml:"header" json:"header"`
+	Body        *unmarshaledBody    `yaml:"body" json:"body"`
+	Protocol    *string             `yaml:"protocol" json:"protocol"`
+	TLS         *unmarshaledTLS     `yaml:"tls" json:"tls"`
+	Proxy       *unmarshaledProxy   `yaml:"proxy" json:"proxy"`
+	Resolve     map[string]string   `yaml:"resolve" json:"resolve"`
+	DNS         *unmarshaledDNS     `yaml:"dns" json:"dns"`
+	UnixSocket  *string             `yaml:"unixSocket" json:"unixSocket"`
+}
+
+// unmarshaledDNS is a raw data model for the DNS options of a request
+// in config files.
+type unmarshaledDNS struct {
+	Server *string `yaml:"server" json:"server"`
+	Mode   *string `yaml:"mode" json:"mode"`
+}
+
+// unmarshaledProxy is a raw data model for the proxy options of a request
+// in config files.
+type unmarshaledProxy struct {
+	URL     *string   `yaml:"url" json:"url"`
+	NoProxy *[]string `yaml:"noProxy" json:"noProxy"`
+}
+
+// unmarshaledTLS is a raw data model for the TLS options of a request
+// in config files.
+type unmarshaledTLS struct {
+	CA                 *string   `yaml:"ca" json:"ca"`
+	Cert               *string   `yaml:"cert" json:"cert"`
+	Key                *string   `yaml:"key" json:"key"`
+	InsecureSkipVerify *bool     `yaml:"insecureSkipVerify" json:"insecureSkipVerify"`
+	ServerName         *string   `yaml:"serverName" json:"serverName"`
+	MinVersion         *string   `yaml:"minVersion" json:"minVersion"`
+	MaxVersion         *string   `yaml:"maxVersion" json:"maxVersion"`
+	CipherSuites       *[]string `yaml:"cipherSuites" json:"cipherSuites"`
+	SessionResumption  *bool     `yaml:"sessionResumption" json:"sessionResumption"`
+}
+
+// unmarshaledBody is a raw data model for a request body in config files.
+type unmarshaledBody struct {
+	Type    string            `yaml:"type" json:"type"`
+	Content string            `yaml:"content" json:"content"`
+	Fields  map[string]string `yaml:"fields" json:"fields"`
+	Files   map[string]string `yaml:"files" json:"files"`
+}
+
+// unmarshaledInterval is a raw data model for the interval in config files,
+// set as a whole. An empty distribution is read as a constant one.
+type unmarshaledInterval struct {
+	Distribution string `yaml:"distribution" json:"distribution"`
+	Duration     string `yaml:"duration" json:"duration"`
+	Min          string `yaml:"min" json:"min"`
+	Max          string `yaml:"max" json:"max"`
+	StdDev       string `yaml:"stdDev" json:"stdDev"`
+	Mode         string `yaml:"mode" json:"mode"`
+}
+
+// unmarshaledChecks is a raw data model for response checks in config files.
+type unmarshaledChecks struct {
+	Status     []interface{}          `yaml:"status" json:"status"`
+	Header     map[string]string      `yaml:"header" json:"header"`
+	Body       *string                `yaml:"body" json:"body"`
+	BodyRegexp *string                `yaml:"bodyRegexp" json:"bodyRegexp"`
+	JSON       map[string]interface{} `yaml:"json" json:"json"`
 }
 
 // Parse parses a benchttp runner config file into a config.Global
@@ -121,9 +245,64 @@ func parseFile(filename string) (uconf unmarshaledConfig, err error) {
 		return uconf, errWithDetails(ErrParse, filename, err)
 	}
 
+	// resolve the data, records and body file paths relatively
+	// to the config file
+	dir := filepath.Dir(filename)
+	if dataFile := uconf.Data.File; dataFile != nil {
+		resolved := resolvePath(dir, *dataFile)
+		uconf.Data.File = &resolved
+	}
+	if recordDir := uconf.Runner.Records.Dir; recordDir != nil {
+		resolved := resolvePath(dir, *recordDir)
+		uconf.Runner.Records.Dir = &resolved
+	}
+	resolveRequestPaths(dir, uconf.Request)
+	if uconf.Endpoints != nil {
+		for _, e := range *uconf.Endpoints {
+			resolveRequestPaths(dir, e.Request)
+		}
+	}
+	if uconf.Scenario != nil {
+		for _, s := range *uconf.Scenario {
+			resolveRequestPaths(dir, s.Request)
+		}
+	}
+
 	return uconf, nil
 }
 
+// resolvePath returns path joined to dir if path is relative and non-empty,
+// else it returns path unchanged.
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// resolveRequestPaths resolves the file paths of the body, the TLS
+// options and the Unix socket of ureq relatively to dir.
+func resolveRequestPaths(dir string, ureq unmarshaledRequest) {
+	if ubody := ureq.Body; ubody != nil {
+		if ubody.Type == config.BodyFile {
+			ubody.Content = resolvePath(dir, ubody.Content)
+		}
+		for field, filename := range ubody.Files {
+			ubody.Files[field] = resolvePath(dir, filename)
+		}
+	}
+	if utls := ureq.TLS; utls != nil {
+		for _, path := range []*string{utls.CA, utls.Cert, utls.Key} {
+			if path != nil {
+				*path = resolvePath(dir, *path)
+			}
+		}
+	}
+	if ureq.UnixSocket != nil {
+		*ureq.UnixSocket = resolvePath(dir, *ureq.UnixSocket)
+	}
+}
+
 // parseAndMergeConfigs iterates backwards over uconfs, parsing them
 // as config.Global and merging them into a single one.
 // It returns the merged result or the first non-nil error occurring in the
@@ -165,7 +344,7 @@ func (pconf *parsedConfig) add(field string) {
 // newParsedConfig parses an input raw config as a config.Global and returns
 // a parsedConfig or the first non-nil error occurring in the process.
 func newParsedConfig(uconf unmarshaledConfig) (parsedConfig, error) { //nolint:gocognit // acceptable complexity for a parsing func
-	const numField = 12 // should match the number of config Fields (not critical)
+	const numField = 56 // should match the number of config Fields (not critical)
 
 	pconf := parsedConfig{
 		fields: make([]string, 0, numField),
@@ -195,13 +374,67 @@ func newParsedConfig(uconf unmarshaledConfig) (parsedConfig, error) { //nolint:g
 	}
 
 	if body := uconf.Request.Body; body != nil {
-		pconf.Request.Body = config.Body{
-			Type:    body.Type,
-			Content: []byte(body.Content),
-		}
+		pconf.Request.Body = parseBody(*body)
 		pconf.add(config.FieldBody)
 	}
 
+	if protocol := uconf.Request.Protocol; protocol != nil {
+		pconf.Request.Protocol = *protocol
+		pconf.add(config.FieldProtocol)
+	}
+
+	if utls := uconf.Request.TLS; utls != nil {
+		pconf.Request.TLS = parseTLS(*utls)
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{config.FieldTLSCA, utls.CA != nil},
+			{config.FieldTLSCert, utls.Cert != nil},
+			{config.FieldTLSKey, utls.Key != nil},
+			{config.FieldInsecure, utls.InsecureSkipVerify != nil},
+			{config.FieldTLSServerName, utls.ServerName != nil},
+			{config.FieldTLSMinVersion, utls.MinVersion != nil},
+			{config.FieldTLSMaxVersion, utls.MaxVersion != nil},
+			{config.FieldTLSCiphers, utls.CipherSuites != nil},
+			{config.FieldTLSSessionResumption, utls.SessionResumption != nil},
+		} {
+			if f.set {
+				pconf.add(f.name)
+			}
+		}
+	}
+
+	if uproxy := uconf.Request.Proxy; uproxy != nil {
+		pconf.Request.Proxy = parseProxy(*uproxy)
+		if uproxy.URL != nil {
+			pconf.add(config.FieldProxy)
+		}
+		if uproxy.NoProxy != nil {
+			pconf.add(config.FieldNoProxy)
+		}
+	}
+
+	if unixSocket := uconf.Request.UnixSocket; unixSocket != nil {
+		pconf.Request.UnixSocket = *unixSocket
+		pconf.add(config.FieldUnixSocket)
+	}
+
+	if resolve := uconf.Request.Resolve; resolve != nil {
+		pconf.Request.Resolve = resolve
+		pconf.add(config.FieldResolve)
+	}
+
+	if udns := uconf.Request.DNS; udns != nil {
+		pconf.Request.DNS = parseDNS(*udns)
+		if udns.Server != nil {
+			pconf.add(config.FieldDNSServer)
+		}
+		if udns.Mode != nil {
+			pconf.add(config.FieldDNSMode)
+		}
+	}
+
 	if requests := uconf.Runner.Requests; requests != nil {
 		pconf.Runner.Requests = *requests
 		pconf.add(config.FieldRequests)
@@ -212,8 +445,28 @@ func newParsedConfig(uconf unmarshaledConfig) (parsedConfig, error) { //nolint:g
 		pconf.add(config.FieldConcurrency)
 	}
 
+	if rate := uconf.Runner.Rate; rate != nil {
+		pconf.Runner.Rate = *rate
+		pconf.add(config.FieldRate)
+	}
+
+	if stages := uconf.Runner.Stages; stages != nil {
+		for _, stage := range *stages {
+			parsedDuration, err := parseOptionalDuration(stage.Duration)
+			if err != nil {
+				return parsedConfig{}, err
+			}
+			pconf.Runner.Stages = append(pconf.Runner.Stages, config.Stage{
+				Duration:    parsedDuration,
+				Concurrency: stage.Concurrency,
+				Rate:        stage.Rate,
+			})
+		}
+		pconf.add(config.FieldStages)
+	}
+
 	if interval := uconf.Runner.Interval; interval != nil {
-		parsedInterval, err := parseOptionalDuration(*interval)
+		parsedInterval, err := parseInterval(*interval)
 		if err != nil {
 			return parsedConfig{}, err
 		}
@@ -239,6 +492,117 @@ func newParsedConfig(uconf unmarshaledConfig) (parsedConfig, error) { //nolint:g
 		pconf.add(config.FieldGlobalTimeout)
 	}
 
+	if drain := uconf.Runner.Drain; drain != nil {
+		parsedDrain, err := parseOptionalDuration(*drain)
+		if err != nil {
+			return parsedConfig{}, err
+		}
+		pconf.Runner.Drain = parsedDrain
+		pconf.add(config.FieldDrain)
+	}
+
+	if warmup := uconf.Runner.Warmup; warmup != nil {
+		parsedWarmup, err := config.ParseWarmup(rawString(warmup))
+		if err != nil {
+			return parsedConfig{}, err
+		}
+		pconf.Runner.Warmup.Requests = parsedWarmup.Requests
+		pconf.Runner.Warmup.Duration = parsedWarmup.Duration
+		pconf.add(config.FieldWarmup)
+	}
+
+	if warmupReport := uconf.Runner.WarmupReport; warmupReport != nil {
+		pconf.Runner.Warmup.Report = *warmupReport
+		pconf.add(config.FieldWarmupReport)
+	}
+
+	if bucketInterval := uconf.Runner.BucketInterval; bucketInterval != nil {
+		parsedBucketInterval, err := parseOptionalDuration(*bucketInterval)
+		if err != nil {
+			return parsedConfig{}, err
+		}
+		pconf.Runner.BucketInterval = parsedBucketInterval
+		pconf.add(config.FieldBucketInterval)
+	}
+
+	if skip := uconf.Runner.Preflight.Skip; skip != nil {
+		pconf.Runner.Preflight.Skip = *skip
+		pconf.add(config.FieldSkipPreflight)
+	}
+
+	if preflightURL := uconf.Runner.Preflight.URL; preflightURL != nil {
+		pconf.Runner.Preflight.URL = *preflightURL
+		pconf.add(config.FieldPreflightURL)
+	}
+
+	if method := uconf.Runner.Preflight.Method; method != nil {
+		pconf.Runner.Preflight.Method = *method
+		pconf.add(config.FieldPreflightMethod)
+	}
+
+	if status := uconf.Runner.Preflight.Status; status != nil {
+		pconf.Runner.Preflight.Status = *status
+		pconf.add(config.FieldPreflightStatus)
+	}
+
+	if maxWait := uconf.Runner.Preflight.MaxWait; maxWait != nil {
+		parsedMaxWait, err := parseOptionalDuration(*maxWait)
+		if err != nil {
+			return parsedConfig{}, err
+		}
+		pconf.Runner.Preflight.MaxWait = parsedMaxWait
+		pconf.add(config.FieldPreflightMaxWait)
+	}
+
+	if backoff := uconf.Runner.Preflight.Backoff; backoff != nil {
+		parsedBackoff, err := parseOptionalDuration(*backoff)
+		if err != nil {
+			return parsedConfig{}, err
+		}
+		pconf.Runner.Preflight.Backoff = parsedBackoff
+		pconf.add(config.FieldPreflightBackoff)
+	}
+
+	if seed := uconf.Runner.Seed; seed != nil {
+		pconf.Runner.Seed = *seed
+		pconf.add(config.FieldSeed)
+	}
+
+	if disableKeepAlive := uconf.Runner.Connections.DisableKeepAlive; disableKeepAlive != nil {
+		pconf.Runner.Connections.DisableKeepAlive = *disableKeepAlive
+		pconf.add(config.FieldDisableKeepAlive)
+	}
+
+	if maxIdle := uconf.Runner.Connections.MaxIdle; maxIdle != nil {
+		pconf.Runner.Connections.MaxIdle = *maxIdle
+		pconf.add(config.FieldMaxIdleConns)
+	}
+
+	if maxPerHost := uconf.Runner.Connections.MaxPerHost; maxPerHost != nil {
+		pconf.Runner.Connections.MaxPerHost = *maxPerHost
+		pconf.add(config.FieldMaxConnsPerHost)
+	}
+
+	if maxStreams := uconf.Runner.Connections.MaxStreams; maxStreams != nil {
+		pconf.Runner.Connections.MaxStreams = *maxStreams
+		pconf.add(config.FieldMaxStreams)
+	}
+
+	if pool := uconf.Runner.Connections.Pool; pool != nil {
+		pconf.Runner.Connections.Pool = *pool
+		pconf.add(config.FieldConnPool)
+	}
+
+	if storage := uconf.Runner.Records.Storage; storage != nil {
+		pconf.Runner.Records.Storage = *storage
+		pconf.add(config.FieldRecordStorage)
+	}
+
+	if recordDir := uconf.Runner.Records.Dir; recordDir != nil {
+		pconf.Runner.Records.Dir = *recordDir
+		pconf.add(config.FieldRecordDir)
+	}
+
 	if out := uconf.Output.Out; out != nil {
 		for _, o := range *out {
 			pconf.Output.Out = append(pconf.Output.Out, config.OutputStrategy(o))
@@ -256,6 +620,101 @@ func newParsedConfig(uconf unmarshaledConfig) (parsedConfig, error) { //nolint:g
 		pconf.add(config.FieldTemplate)
 	}
 
+	if file := uconf.Data.File; file != nil {
+		pconf.Data.File = *file
+		pconf.add(config.FieldDataFile)
+	}
+
+	if mode := uconf.Data.Mode; mode != nil {
+		pconf.Data.Mode = *mode
+		pconf.add(config.FieldDataMode)
+	}
+
+	if onEnd := uconf.Data.OnEnd; onEnd != nil {
+		pconf.Data.OnEnd = *onEnd
+		pconf.add(config.FieldDataOnEnd)
+	}
+
+	if endpoints := uconf.Endpoints; endpoints != nil {
+		for _, uendpoint := range *endpoints {
+			// weight defaults to 1, so that unweighted endpoints
+			// are picked uniformly
+			endpoint := config.Endpoint{Name: uendpoint.Name, Weight: 1}
+			if uendpoint.Weight != nil {
+				endpoint.Weight = *uendpoint.Weight
+			}
+
+			request, err := parseRequest(uendpoint.Request)
+			if err != nil {
+				return parsedConfig{}, err
+			}
+			endpoint.Request = request
+
+			pconf.Endpoints = append(pconf.Endpoints, endpoint)
+		}
+		pconf.add(config.FieldEndpoints)
+	}
+
+	if scenario := uconf.Scenario; scenario != nil {
+		for _, ustep := range *scenario {
+			step := config.Step{Name: ustep.Name}
+
+			request, err := parseRequest(ustep.Request)
+			if err != nil {
+				return parsedConfig{}, err
+			}
+			step.Request = request
+
+			if ustep.Checks != nil {
+				if step.Checks, err = parseChecks(*ustep.Checks); err != nil {
+					return parsedConfig{}, err
+				}
+			}
+
+			names := make([]string, 0, len(ustep.Extract))
+			for name := range ustep.Extract {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				uextract := ustep.Extract[name]
+				extract := config.Extract{Name: name}
+				switch {
+				case uextract.JSON != nil:
+					extract.Source, extract.Key = config.ExtractJSON, *uextract.JSON
+				case uextract.Header != nil:
+					extract.Source, extract.Key = config.ExtractHeader, *uextract.Header
+				case uextract.Regexp != nil:
+					extract.Source, extract.Key = config.ExtractRegexp, *uextract.Regexp
+				}
+				step.Extract = append(step.Extract, extract)
+			}
+
+			pconf.Scenario = append(pconf.Scenario, step)
+		}
+		pconf.add(config.FieldScenario)
+	}
+
+	if checks := uconf.Checks; checks != nil {
+		parsedChecks, err := parseChecks(*checks)
+		if err != nil {
+			return parsedConfig{}, err
+		}
+		pconf.Checks = parsedChecks
+		pconf.add(config.FieldChecks)
+	}
+
+	if thresholds := uconf.Thresholds; thresholds != nil {
+		for _, raw := range *thresholds {
+			threshold, err := config.ParseThreshold(raw)
+			if err != nil {
+				return parsedConfig{}, err
+			}
+			pconf.Thresholds = append(pconf.Thresholds, threshold)
+		}
+		pconf.add(config.FieldThresholds)
+	}
+
 	return pconf, nil
 }
 
@@ -263,25 +722,278 @@ func newParsedConfig(uconf unmarshaledConfig) (parsedConfig, error) { //nolint:g
 
 // parseAndBuildURL parses a raw string as a *url.URL and adds any extra
 // query parameters. It returns the first non-nil error occurring in the
-// process.
+// process. The query of raw is kept as is and the extra parameters are
+// escaped except for their placeholders, so that these are rendered
+// for each request.
 func parseAndBuildURL(raw string, qp map[string]string) (*url.URL, error) {
 	u, err := url.ParseRequestURI(raw)
 	if err != nil {
 		return nil, err
 	}
 
-	// retrieve url query, add extra params, re-attach to url
-	if qp != nil {
-		q := u.Query()
-		for k, v := range qp {
-			q.Add(k, v)
+	// append the extra params to the raw query in a predictable order
+	for _, k := range sortedKeys(qp) {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
 		}
-		u.RawQuery = q.Encode()
+		u.RawQuery += escapeQueryTemplate(k) + "=" + escapeQueryTemplate(qp[k])
 	}
 
 	return u, nil
 }
 
+// escapeQueryTemplate escapes s for use in a URL query, except for its
+// placeholders "${...}".
+func escapeQueryTemplate(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end == -1 {
+			break
+		}
+		end += start + 1
+		b.WriteString(url.QueryEscape(s[:start]))
+		b.WriteString(s[start:end])
+		s = s[end:]
+	}
+	b.WriteString(url.QueryEscape(s))
+	return b.String()
+}
+
+// parseRequest parses a raw request as a config.Request. Unset method
+// defaults to "GET".
+func parseRequest(ureq unmarshaledRequest) (config.Request, error) {
+	req := config.Request{Method: "GET", Header: http.Header{}}
+
+	if ureq.Method != nil {
+		req.Method = *ureq.Method
+	}
+
+	if ureq.URL != nil {
+		parsedURL, err := parseAndBuildURL(*ureq.URL, ureq.QueryParams)
+		if err != nil {
+			return config.Request{}, err
+		}
+		req.URL = parsedURL
+	}
+
+	for key, val := range ureq.Header {
+		req.Header[key] = val
+	}
+
+	if ureq.Body != nil {
+		req.Body = parseBody(*ureq.Body)
+	}
+
+	if ureq.Protocol != nil {
+		req.Protocol = *ureq.Protocol
+	}
+
+	if ureq.TLS != nil {
+		req.TLS = parseTLS(*ureq.TLS)
+	}
+
+	if ureq.Proxy != nil {
+		req.Proxy = parseProxy(*ureq.Proxy)
+	}
+
+	req.Resolve = ureq.Resolve
+
+	if ureq.DNS != nil {
+		req.DNS = parseDNS(*ureq.DNS)
+	}
+
+	if ureq.UnixSocket != nil {
+		req.UnixSocket = *ureq.UnixSocket
+	}
+
+	return req, nil
+}
+
+// parseProxy parses raw proxy options as a config.Proxy.
+func parseProxy(uproxy unmarshaledProxy) config.Proxy {
+	var p config.Proxy
+	if uproxy.URL != nil {
+		p.URL = *uproxy.URL
+	}
+	if uproxy.NoProxy != nil {
+		p.NoProxy = *uproxy.NoProxy
+	}
+	return p
+}
+
+// parseTLS parses raw TLS options as a config.TLS.
+func parseTLS(utls unmarshaledTLS) config.TLS {
+	var t config.TLS
+	if utls.CA != nil {
+		t.CAFile = *utls.CA
+	}
+	if utls.Cert != nil {
+		t.CertFile = *utls.Cert
+	}
+	if utls.Key != nil {
+		t.KeyFile = *utls.Key
+	}
+	if utls.InsecureSkipVerify != nil {
+		t.InsecureSkipVerify = *utls.InsecureSkipVerify
+	}
+	if utls.ServerName != nil {
+		t.ServerName = *utls.ServerName
+	}
+	if utls.MinVersion != nil {
+		t.MinVersion = *utls.MinVersion
+	}
+	if utls.MaxVersion != nil {
+		t.MaxVersion = *utls.MaxVersion
+	}
+	if utls.CipherSuites != nil {
+		t.CipherSuites = *utls.CipherSuites
+	}
+	if utls.SessionResumption != nil {
+		t.SessionResumption = *utls.SessionResumption
+	}
+	return t
+}
+
+// parseDNS parses raw DNS options as a config.DNS.
+func parseDNS(udns unmarshaledDNS) config.DNS {
+	var d config.DNS
+	if udns.Server != nil {
+		d.Server = *udns.Server
+	}
+	if udns.Mode != nil {
+		d.Mode = *udns.Mode
+	}
+	return d
+}
+
+// parseBody parses a raw body as a config.Body.
+func parseBody(ubody unmarshaledBody) config.Body {
+	body := config.NewBody(ubody.Type, ubody.Content)
+	if len(ubody.Fields) > 0 {
+		body.Fields = url.Values{}
+		for key, value := range ubody.Fields {
+			body.Fields.Set(key, value)
+		}
+	}
+	if len(ubody.Files) > 0 {
+		body.Files = make(map[string]string, len(ubody.Files))
+		for field, filename := range ubody.Files {
+			body.Files[field] = filename
+		}
+	}
+	return body
+}
+
+// rawString returns v, a number or a string unmarshaled into an interface{},
+// as a string. Numbers are written without exponent, so that a JSON
+// number such as 1000000 is not read as "1e+06".
+func rawString(v interface{}) string {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// parseInterval returns the given raw interval as a config.Interval,
+// or the first non-nil error occurring parsing its durations.
+func parseInterval(uinterval unmarshaledInterval) (config.Interval, error) {
+	var err error
+	interval := config.Interval{Mode: uinterval.Mode, Distribution: uinterval.Distribution}
+	if interval.Duration, err = parseOptionalDuration(uinterval.Duration); err != nil {
+		return config.Interval{}, err
+	}
+	if interval.Min, err = parseOptionalDuration(uinterval.Min); err != nil {
+		return config.Interval{}, err
+	}
+	if interval.Max, err = parseOptionalDuration(uinterval.Max); err != nil {
+		return config.Interval{}, err
+	}
+	if interval.StdDev, err = parseOptionalDuration(uinterval.StdDev); err != nil {
+		return config.Interval{}, err
+	}
+	return interval, nil
+}
+
+// parseChecks returns the given raw checks as a slice of config.Check.
+// Checks are sorted by kind, then by key for kinds relying on a map,
+// so the order is predictable. JSON values are re-encoded as JSON.
+func parseChecks(uchecks unmarshaledChecks) ([]config.Check, error) {
+	var (
+		checks     = []config.Check{}
+		status     = uchecks.Status
+		header     = uchecks.Header
+		body       = uchecks.Body
+		bodyRegexp = uchecks.BodyRegexp
+		jsonValues = uchecks.JSON
+	)
+
+	if len(status) > 0 {
+		codes := make([]string, len(status))
+		for i, code := range status {
+			codes[i] = fmt.Sprint(code)
+		}
+		checks = append(checks, config.Check{
+			Kind:  config.CheckStatus,
+			Value: strings.Join(codes, ","),
+		})
+	}
+
+	for _, key := range sortedKeys(header) {
+		checks = append(checks, config.Check{
+			Kind:  config.CheckHeader,
+			Key:   key,
+			Value: header[key],
+		})
+	}
+
+	if body != nil {
+		checks = append(checks, config.Check{Kind: config.CheckBody, Value: *body})
+	}
+
+	if bodyRegexp != nil {
+		checks = append(checks, config.Check{Kind: config.CheckBodyRegexp, Value: *bodyRegexp})
+	}
+
+	jsonKeys := make([]string, 0, len(jsonValues))
+	for key := range jsonValues {
+		jsonKeys = append(jsonKeys, key)
+	}
+	sort.Strings(jsonKeys)
+	for _, key := range jsonKeys {
+		b, err := json.Marshal(jsonValues[key])
+		if err != nil {
+			return nil, fmt.Errorf("checks: json: %s: %s", key, err)
+		}
+		checks = append(checks, config.Check{
+			Kind:  config.CheckJSON,
+			Key:   key,
+			Value: string(b),
+		})
+	}
+
+	return checks, nil
+}
+
+// sortedKeys returns the keys of m sorted in increasing order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // parseOptionalDuration parses the raw string as a time.Duration
 // and returns the parsed value or a non-nil error.
 // Contrary to time.ParseDuration, it does not return an error
@@ -2,6 +2,7 @@ package configflags
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/benchttp/runner/config"
@@ -18,9 +19,15 @@ func (v bodyValue) String() string {
 }
 
 // Set reads input string in format "type:content" and sets
-// the referenced body accordingly.
+// the referenced body accordingly:
 //
-// Note: only type "raw" is supported at the moment.
+//	raw:<content>
+//	file:<path>
+//	form:<key>=<value>&...
+//	multipart:<key>=<value>&<key>=@<path>&...
+//
+// Form and multipart contents are URL-encoded key/value pairs.
+// A multipart value prefixed with "@" is the path of a file part.
 func (v bodyValue) Set(raw string) error {
 	errFormat := fmt.Errorf(`expect format "<type>:<content>", got "%s"`, raw)
 
@@ -39,12 +46,57 @@ func (v bodyValue) Set(raw string) error {
 	}
 
 	switch btype {
-	case "raw":
+	case config.BodyRaw, config.BodyFile:
 		*v.body = config.NewBody(btype, bcontent)
-	// case "file":
-	// 	// TODO
+	case config.BodyForm, config.BodyMultipart:
+		body, err := parseFormBody(btype, bcontent)
+		if err != nil {
+			return err
+		}
+		*v.body = body
 	default:
-		return fmt.Errorf(`unsupported type: %s (only "raw" accepted)`, btype)
+		return fmt.Errorf(
+			`unsupported type: %s (want one of "raw", "file", "form", "multipart")`,
+			btype,
+		)
 	}
 	return nil
 }
+
+// parseFormBody parses content as URL-encoded key/value pairs and returns
+// a config.Body of type btype holding them. For type multipart, values
+// prefixed with "@" are read as file parts.
+func parseFormBody(btype, content string) (config.Body, error) {
+	fields, err := url.ParseQuery(content)
+	if err != nil {
+		return config.Body{}, fmt.Errorf("invalid %s body %q: %s", btype, content, err)
+	}
+
+	body := config.Body{Type: btype, Fields: fields}
+	if btype != config.BodyMultipart {
+		return body, nil
+	}
+
+	for key, values := range fields {
+		kept := values[:0]
+		for _, value := range values {
+			if !strings.HasPrefix(value, "@") {
+				kept = append(kept, value)
+				continue
+			}
+			if _, exists := body.Files[key]; exists {
+				return config.Body{}, fmt.Errorf("invalid %s body %q: duplicate file %q", btype, content, key)
+			}
+			if body.Files == nil {
+				body.Files = map[string]string{}
+			}
+			body.Files[key] = strings.TrimPrefix(value, "@")
+		}
+		if len(kept) == 0 {
+			delete(fields, key)
+		} else {
+			fields[key] = kept
+		}
+	}
+	return body, nil
+}
@@ -0,0 +1,65 @@
+package configflags
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/benchttp/runner/config"
+)
+
+// checksValue implements flag.Value
+type checksValue struct {
+	checks *[]config.Check
+}
+
+// String returns a string representation of the referenced checks.
+func (v checksValue) String() string {
+	return fmt.Sprint(v.checks)
+}
+
+// Set reads input string as a single check in format "<kind>:<value>"
+// and appends it to the referenced checks. Depending on the kind,
+// the expected value is:
+//
+//	status:     "<codes>" (e.g. "200,3xx,400-404")
+//	header:     "<key>" or "<key>=<value>"
+//	body:       "<substring>"
+//	bodyRegexp: "<regexp>"
+//	json:       "<path>=<value>", value being JSON or a raw string
+func (v checksValue) Set(in string) error {
+	kindval := strings.SplitN(in, ":", 2)
+	if len(kindval) != 2 {
+		return fmt.Errorf(`expect format "<kind>:<value>", got "%s"`, in)
+	}
+
+	check := config.Check{Kind: kindval[0], Value: kindval[1]}
+
+	switch check.Kind {
+	case config.CheckHeader:
+		keyval := strings.SplitN(check.Value, "=", 2)
+		check.Key, check.Value = keyval[0], ""
+		if len(keyval) == 2 {
+			check.Value = keyval[1]
+		}
+	case config.CheckJSON:
+		keyval := strings.SplitN(check.Value, "=", 2)
+		if len(keyval) != 2 {
+			return fmt.Errorf(`expect format "json:<path>=<value>", got "%s"`, in)
+		}
+		check.Key, check.Value = keyval[0], jsonValue(keyval[1])
+	}
+
+	*v.checks = append(*v.checks, check)
+	return nil
+}
+
+// jsonValue returns raw if it is valid JSON, raw encoded as a JSON string
+// otherwise.
+func jsonValue(raw string) string {
+	if json.Valid([]byte(raw)) {
+		return raw
+	}
+	b, _ := json.Marshal(raw)
+	return string(b)
+}
@@ -0,0 +1,28 @@
+package configflags
+
+import "github.com/benchttp/runner/config"
+
+// intervalValue implements flag.Value
+type intervalValue struct {
+	interval *config.Interval
+}
+
+// String returns a string representation of the referenced interval.
+func (v intervalValue) String() string {
+	if v.interval == nil {
+		return ""
+	}
+	return v.interval.String()
+}
+
+// Set reads input string as a duration or a distribution and sets it
+// as the referenced interval's, keeping its mode.
+func (v intervalValue) Set(in string) error {
+	interval, err := config.ParseInterval(in)
+	if err != nil {
+		return err
+	}
+	interval.Mode = v.interval.Mode
+	*v.interval = interval
+	return nil
+}
@@ -0,0 +1,27 @@
+package configflags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// listValue implements flag.Value
+type listValue struct {
+	list *[]string
+}
+
+// String returns a string representation of the referenced list.
+func (v listValue) String() string {
+	return fmt.Sprint(v.list)
+}
+
+// Set reads input string as comma-separated values and appends them
+// to the referenced list.
+func (v listValue) Set(in string) error {
+	for _, value := range strings.Split(in, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			*v.list = append(*v.list, value)
+		}
+	}
+	return nil
+}
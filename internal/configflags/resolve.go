@@ -0,0 +1,32 @@
+package configflags
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// resolveValue implements flag.Value
+type resolveValue struct {
+	resolve *map[string]string
+}
+
+// String returns a string representation of the referenced resolve map.
+func (v resolveValue) String() string {
+	return fmt.Sprint(v.resolve)
+}
+
+// Set reads input string in format "host:port:ip" and maps the address
+// "host:port" to ip in the referenced resolve map.
+func (v resolveValue) Set(raw string) error {
+	parts := strings.SplitN(raw, ":", 3)
+	if len(parts) != 3 {
+		return errors.New(`expect format "<host>:<port>:<ip>"`)
+	}
+	if *v.resolve == nil {
+		*v.resolve = map[string]string{}
+	}
+	addr, ip := parts[0]+":"+parts[1], strings.Trim(parts[2], "[]")
+	(*v.resolve)[addr] = ip
+	return nil
+}
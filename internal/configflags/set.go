@@ -42,6 +42,88 @@ func Set(flagset *flag.FlagSet, dst *config.Global) {
 		config.FieldBody,
 		config.FieldsUsage[config.FieldBody],
 	)
+	// request protocol
+	flagset.StringVar(&dst.Request.Protocol,
+		config.FieldProtocol,
+		dst.Request.Protocol,
+		config.FieldsUsage[config.FieldProtocol],
+	)
+	// request tls
+	flagset.StringVar(&dst.Request.TLS.CAFile,
+		config.FieldTLSCA,
+		dst.Request.TLS.CAFile,
+		config.FieldsUsage[config.FieldTLSCA],
+	)
+	flagset.StringVar(&dst.Request.TLS.CertFile,
+		config.FieldTLSCert,
+		dst.Request.TLS.CertFile,
+		config.FieldsUsage[config.FieldTLSCert],
+	)
+	flagset.StringVar(&dst.Request.TLS.KeyFile,
+		config.FieldTLSKey,
+		dst.Request.TLS.KeyFile,
+		config.FieldsUsage[config.FieldTLSKey],
+	)
+	flagset.BoolVar(&dst.Request.TLS.InsecureSkipVerify,
+		config.FieldInsecure,
+		dst.Request.TLS.InsecureSkipVerify,
+		config.FieldsUsage[config.FieldInsecure],
+	)
+	flagset.StringVar(&dst.Request.TLS.ServerName,
+		config.FieldTLSServerName,
+		dst.Request.TLS.ServerName,
+		config.FieldsUsage[config.FieldTLSServerName],
+	)
+	flagset.StringVar(&dst.Request.TLS.MinVersion,
+		config.FieldTLSMinVersion,
+		dst.Request.TLS.MinVersion,
+		config.FieldsUsage[config.FieldTLSMinVersion],
+	)
+	flagset.StringVar(&dst.Request.TLS.MaxVersion,
+		config.FieldTLSMaxVersion,
+		dst.Request.TLS.MaxVersion,
+		config.FieldsUsage[config.FieldTLSMaxVersion],
+	)
+	flagset.Var(listValue{list: &dst.Request.TLS.CipherSuites},
+		config.FieldTLSCiphers,
+		config.FieldsUsage[config.FieldTLSCiphers],
+	)
+	flagset.BoolVar(&dst.Request.TLS.SessionResumption,
+		config.FieldTLSSessionResumption,
+		dst.Request.TLS.SessionResumption,
+		config.FieldsUsage[config.FieldTLSSessionResumption],
+	)
+	// request proxy
+	flagset.StringVar(&dst.Request.Proxy.URL,
+		config.FieldProxy,
+		dst.Request.Proxy.URL,
+		config.FieldsUsage[config.FieldProxy],
+	)
+	flagset.Var(listValue{list: &dst.Request.Proxy.NoProxy},
+		config.FieldNoProxy,
+		config.FieldsUsage[config.FieldNoProxy],
+	)
+	// request dns
+	flagset.Var(resolveValue{resolve: &dst.Request.Resolve},
+		config.FieldResolve,
+		config.FieldsUsage[config.FieldResolve],
+	)
+	flagset.StringVar(&dst.Request.DNS.Server,
+		config.FieldDNSServer,
+		dst.Request.DNS.Server,
+		config.FieldsUsage[config.FieldDNSServer],
+	)
+	flagset.StringVar(&dst.Request.DNS.Mode,
+		config.FieldDNSMode,
+		dst.Request.DNS.Mode,
+		config.FieldsUsage[config.FieldDNSMode],
+	)
+	// request unix socket
+	flagset.StringVar(&dst.Request.UnixSocket,
+		config.FieldUnixSocket,
+		dst.Request.UnixSocket,
+		config.FieldsUsage[config.FieldUnixSocket],
+	)
 	// requests number
 	flagset.IntVar(&dst.Runner.Requests,
 		config.FieldRequests,
@@ -55,12 +137,28 @@ func Set(flagset *flag.FlagSet, dst *config.Global) {
 		dst.Runner.Concurrency,
 		config.FieldsUsage[config.FieldConcurrency],
 	)
-	// non-conurrent requests interval
-	flagset.DurationVar(&dst.Runner.Interval,
+	// requests rate
+	flagset.IntVar(&dst.Runner.Rate,
+		config.FieldRate,
+		dst.Runner.Rate,
+		config.FieldsUsage[config.FieldRate],
+	)
+	// load profile stages
+	flagset.Var(stagesValue{stages: &dst.Runner.Stages},
+		config.FieldStages,
+		config.FieldsUsage[config.FieldStages],
+	)
+	// pause between requests
+	flagset.Var(intervalValue{interval: &dst.Runner.Interval},
 		config.FieldInterval,
-		dst.Runner.Interval,
 		config.FieldsUsage[config.FieldInterval],
 	)
+	// interval mode
+	flagset.StringVar(&dst.Runner.Interval.Mode,
+		config.FieldIntervalMode,
+		dst.Runner.Interval.Mode,
+		config.FieldsUsage[config.FieldIntervalMode],
+	)
 	// request timeout
 	flagset.DurationVar(&dst.Runner.RequestTimeout,
 		config.FieldRequestTimeout,
@@ -73,6 +171,103 @@ func Set(flagset *flag.FlagSet, dst *config.Global) {
 		dst.Runner.GlobalTimeout,
 		config.FieldsUsage[config.FieldGlobalTimeout],
 	)
+	// in-flight requests drain duration
+	flagset.DurationVar(&dst.Runner.Drain,
+		config.FieldDrain,
+		dst.Runner.Drain,
+		config.FieldsUsage[config.FieldDrain],
+	)
+	// warmup requests or duration
+	flagset.Var(warmupValue{warmup: &dst.Runner.Warmup},
+		config.FieldWarmup,
+		config.FieldsUsage[config.FieldWarmup],
+	)
+	// warmup results report
+	flagset.BoolVar(&dst.Runner.Warmup.Report,
+		config.FieldWarmupReport,
+		dst.Runner.Warmup.Report,
+		config.FieldsUsage[config.FieldWarmupReport],
+	)
+	// time buckets duration
+	flagset.DurationVar(&dst.Runner.BucketInterval,
+		config.FieldBucketInterval,
+		dst.Runner.BucketInterval,
+		config.FieldsUsage[config.FieldBucketInterval],
+	)
+	// pre-flight check
+	flagset.BoolVar(&dst.Runner.Preflight.Skip,
+		config.FieldSkipPreflight,
+		dst.Runner.Preflight.Skip,
+		config.FieldsUsage[config.FieldSkipPreflight],
+	)
+	flagset.StringVar(&dst.Runner.Preflight.URL,
+		config.FieldPreflightURL,
+		dst.Runner.Preflight.URL,
+		config.FieldsUsage[config.FieldPreflightURL],
+	)
+	flagset.StringVar(&dst.Runner.Preflight.Method,
+		config.FieldPreflightMethod,
+		dst.Runner.Preflight.Method,
+		config.FieldsUsage[config.FieldPreflightMethod],
+	)
+	flagset.StringVar(&dst.Runner.Preflight.Status,
+		config.FieldPreflightStatus,
+		dst.Runner.Preflight.Status,
+		config.FieldsUsage[config.FieldPreflightStatus],
+	)
+	flagset.DurationVar(&dst.Runner.Preflight.MaxWait,
+		config.FieldPreflightMaxWait,
+		dst.Runner.Preflight.MaxWait,
+		config.FieldsUsage[config.FieldPreflightMaxWait],
+	)
+	flagset.DurationVar(&dst.Runner.Preflight.Backoff,
+		config.FieldPreflightBackoff,
+		dst.Runner.Preflight.Backoff,
+		config.FieldsUsage[config.FieldPreflightBackoff],
+	)
+	// random values seed
+	flagset.Int64Var(&dst.Runner.Seed,
+		config.FieldSeed,
+		dst.Runner.Seed,
+		config.FieldsUsage[config.FieldSeed],
+	)
+	// connections
+	flagset.BoolVar(&dst.Runner.Connections.DisableKeepAlive,
+		config.FieldDisableKeepAlive,
+		dst.Runner.Connections.DisableKeepAlive,
+		config.FieldsUsage[config.FieldDisableKeepAlive],
+	)
+	flagset.IntVar(&dst.Runner.Connections.MaxIdle,
+		config.FieldMaxIdleConns,
+		dst.Runner.Connections.MaxIdle,
+		config.FieldsUsage[config.FieldMaxIdleConns],
+	)
+	flagset.IntVar(&dst.Runner.Connections.MaxPerHost,
+		config.FieldMaxConnsPerHost,
+		dst.Runner.Connections.MaxPerHost,
+		config.FieldsUsage[config.FieldMaxConnsPerHost],
+	)
+	flagset.IntVar(&dst.Runner.Connections.MaxStreams,
+		config.FieldMaxStreams,
+		dst.Runner.Connections.MaxStreams,
+		config.FieldsUsage[config.FieldMaxStreams],
+	)
+	flagset.StringVar(&dst.Runner.Connections.Pool,
+		config.FieldConnPool,
+		dst.Runner.Connections.Pool,
+		config.FieldsUsage[config.FieldConnPool],
+	)
+	// records storage
+	flagset.StringVar(&dst.Runner.Records.Storage,
+		config.FieldRecordStorage,
+		dst.Runner.Records.Storage,
+		config.FieldsUsage[config.FieldRecordStorage],
+	)
+	flagset.StringVar(&dst.Runner.Records.Dir,
+		config.FieldRecordDir,
+		dst.Runner.Records.Dir,
+		config.FieldsUsage[config.FieldRecordDir],
+	)
 
 	// output strategies
 	flagset.Var(outValue{out: &dst.Output.Out},
@@ -91,4 +286,34 @@ func Set(flagset *flag.FlagSet, dst *config.Global) {
 		dst.Output.Template,
 		config.FieldsUsage[config.FieldTemplate],
 	)
+
+	// data file
+	flagset.StringVar(&dst.Data.File,
+		config.FieldDataFile,
+		dst.Data.File,
+		config.FieldsUsage[config.FieldDataFile],
+	)
+	// data read mode
+	flagset.StringVar(&dst.Data.Mode,
+		config.FieldDataMode,
+		dst.Data.Mode,
+		config.FieldsUsage[config.FieldDataMode],
+	)
+	// data end policy
+	flagset.StringVar(&dst.Data.OnEnd,
+		config.FieldDataOnEnd,
+		dst.Data.OnEnd,
+		config.FieldsUsage[config.FieldDataOnEnd],
+	)
+
+	// response checks
+	flagset.Var(checksValue{checks: &dst.Checks},
+		config.FieldChecks,
+		config.FieldsUsage[config.FieldChecks],
+	)
+	// pass/fail thresholds
+	flagset.Var(thresholdsValue{thresholds: &dst.Thresholds},
+		config.FieldThresholds,
+		config.FieldsUsage[config.FieldThresholds],
+	)
 }
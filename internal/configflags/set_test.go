@@ -3,6 +3,7 @@ package configflags_test
 import (
 	"flag"
 	"net/http"
+	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -34,14 +35,63 @@ func TestSet(t *testing.T) {
 			"-url", "https://benchttp.app?cool=yes",
 			"-header", "Content-Type:application/json",
 			"-body", "raw:hello",
+			"-protocol", "h2c",
+			"-tlsCA", "ca.pem",
+			"-tlsCert", "client.pem",
+			"-tlsKey", "client-key.pem",
+			"-insecure",
+			"-tlsServerName", "api.internal",
+			"-tlsMinVersion", "1.2",
+			"-tlsMaxVersion", "1.3",
+			"-tlsCiphers", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+			"-tlsSessionResumption",
+			"-proxy", "socks5://proxy.internal:1080",
+			"-noProxy", "localhost,10.0.0.0/8",
+			"-resolve", "benchttp.app:443:10.0.0.1",
+			"-resolve", "benchttp.app:80:[::1]",
+			"-dnsServer", "10.0.0.53:53",
+			"-dnsMode", "once",
+			"-unixSocket", "/var/run/app.sock",
 			"-requests", "1",
 			"-concurrency", "2",
-			"-interval", "3s",
+			"-rate", "6",
+			"-stages", "1s:10,2s:20/s",
+			"-interval", "exponential:3s",
+			"-intervalMode", "global",
 			"-requestTimeout", "4s",
 			"-globalTimeout", "5s",
+			"-drain", "7s",
+			"-warmup", "8s",
+			"-warmupReport",
+			"-bucketInterval", "3s",
+			"-skipPreflight",
+			"-preflightURL", "http://a.b/health",
+			"-preflightMethod", "HEAD",
+			"-preflightStatus", "200,204",
+			"-preflightMaxWait", "9s",
+			"-preflightBackoff", "500ms",
+			"-seed", "42",
+			"-disableKeepAlive",
+			"-maxIdleConns", "5",
+			"-maxConnsPerHost", "20",
+			"-maxStreams", "50",
+			"-connPool", "perWorker",
+			"-recordStorage", "disk",
+			"-recordDir", "records",
 			"-out", "stdout,json",
 			"-silent",
 			"-template", "{{ .Report.Length }}",
+			"-dataFile", "users.csv",
+			"-dataMode", "perWorker",
+			"-dataOnEnd", "stop",
+			"-checks", "status:200,3xx",
+			"-checks", "header:Content-Type=application/json",
+			"-checks", "header:X-Request-Id",
+			"-checks", `body:"ok"`,
+			"-checks", "json:data.id=42",
+			"-checks", "json:status=ok",
+			"-thresholds", "p95 < 300ms,errorRate < 1%",
+			"-thresholds", "status.5xx == 0",
 		}
 
 		cfg := config.Global{}
@@ -52,26 +102,135 @@ func TestSet(t *testing.T) {
 
 		exp := config.Global{
 			Request: config.Request{
-				Method: "POST",
-				Header: http.Header{"Content-Type": {"application/json"}},
-				Body:   config.Body{Type: "raw", Content: []byte("hello")},
+				Method:   "POST",
+				Header:   http.Header{"Content-Type": {"application/json"}},
+				Body:     config.Body{Type: "raw", Content: []byte("hello")},
+				Protocol: config.ProtocolH2C,
+				TLS: config.TLS{
+					CAFile:             "ca.pem",
+					CertFile:           "client.pem",
+					KeyFile:            "client-key.pem",
+					InsecureSkipVerify: true,
+					ServerName:         "api.internal",
+					MinVersion:         "1.2",
+					MaxVersion:         "1.3",
+					CipherSuites: []string{
+						"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+						"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+					},
+					SessionResumption: true,
+				},
+				Proxy: config.Proxy{
+					URL:     "socks5://proxy.internal:1080",
+					NoProxy: []string{"localhost", "10.0.0.0/8"},
+				},
+				Resolve:    map[string]string{"benchttp.app:443": "10.0.0.1", "benchttp.app:80": "::1"},
+				DNS:        config.DNS{Server: "10.0.0.53:53", Mode: config.DNSOnce},
+				UnixSocket: "/var/run/app.sock",
 			}.WithURL("https://benchttp.app?cool=yes"),
 			Runner: config.Runner{
-				Requests:       1,
-				Concurrency:    2,
-				Interval:       3 * time.Second,
+				Requests:    1,
+				Concurrency: 2,
+				Rate:        6,
+				Stages: []config.Stage{
+					{Duration: 1 * time.Second, Concurrency: 10},
+					{Duration: 2 * time.Second, Rate: 20},
+				},
+				Interval: config.Interval{
+					Mode:         config.IntervalGlobal,
+					Distribution: config.IntervalExponential,
+					Duration:     3 * time.Second,
+				},
 				RequestTimeout: 4 * time.Second,
 				GlobalTimeout:  5 * time.Second,
+				Drain:          7 * time.Second,
+				Warmup:         config.Warmup{Duration: 8 * time.Second, Report: true},
+				BucketInterval: 3 * time.Second,
+				Preflight: config.Preflight{
+					Skip:    true,
+					URL:     "http://a.b/health",
+					Method:  "HEAD",
+					Status:  "200,204",
+					MaxWait: 9 * time.Second,
+					Backoff: 500 * time.Millisecond,
+				},
+				Seed: 42,
+				Connections: config.Connections{
+					DisableKeepAlive: true,
+					MaxIdle:          5,
+					MaxPerHost:       20,
+					MaxStreams:       50,
+					Pool:             config.ConnPoolPerWorker,
+				},
+				Records: config.Records{
+					Storage: config.RecordStorageDisk,
+					Dir:     "records",
+				},
 			},
 			Output: config.Output{
 				Out:      []config.OutputStrategy{config.OutputStdout, config.OutputJSON},
 				Silent:   true,
 				Template: "{{ .Report.Length }}",
 			},
+			Data: config.Data{
+				File:  "users.csv",
+				Mode:  config.DataPerWorker,
+				OnEnd: config.DataStop,
+			},
+			Checks: []config.Check{
+				{Kind: config.CheckStatus, Value: "200,3xx"},
+				{Kind: config.CheckHeader, Key: "Content-Type", Value: "application/json"},
+				{Kind: config.CheckHeader, Key: "X-Request-Id"},
+				{Kind: config.CheckBody, Value: `"ok"`},
+				{Kind: config.CheckJSON, Key: "data.id", Value: "42"},
+				{Kind: config.CheckJSON, Key: "status", Value: `"ok"`},
+			},
+			Thresholds: []config.Threshold{
+				{Metric: "p95", Operator: "<", Value: float64(300 * time.Millisecond)},
+				{Metric: "errorRate", Operator: "<", Value: 1},
+				{Metric: "status.5xx", Operator: "==", Value: 0},
+			},
 		}
 
 		if !reflect.DeepEqual(cfg, exp) {
 			t.Errorf("\nexp %#v\ngot %#v", exp, cfg)
 		}
 	})
+
+	t.Run("set body with all types", func(t *testing.T) {
+		testcases := []struct {
+			in  string
+			exp config.Body
+		}{
+			{
+				in:  "file:./payload.json",
+				exp: config.Body{Type: "file", Content: []byte("./payload.json")},
+			},
+			{
+				in:  "form:name=alice&tags=a&tags=b+c",
+				exp: config.Body{Type: "form", Fields: url.Values{"name": {"alice"}, "tags": {"a", "b c"}}},
+			},
+			{
+				in: "multipart:name=alice&avatar=@./avatar.png",
+				exp: config.Body{
+					Type:   "multipart",
+					Fields: url.Values{"name": {"alice"}},
+					Files:  map[string]string{"avatar": "./avatar.png"},
+				},
+			},
+		}
+
+		for _, tc := range testcases {
+			flagset := flag.NewFlagSet("run", flag.ContinueOnError)
+			cfg := config.Default()
+			configflags.Set(flagset, &cfg)
+			if err := flagset.Parse([]string{"-body", tc.in}); err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.in, err)
+			}
+
+			if !reflect.DeepEqual(cfg.Request.Body, tc.exp) {
+				t.Errorf("%s:\nexp %#v\ngot %#v", tc.in, tc.exp, cfg.Request.Body)
+			}
+		}
+	})
 }
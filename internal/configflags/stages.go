@@ -0,0 +1,67 @@
+package configflags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benchttp/runner/config"
+)
+
+// stagesValue implements flag.Value
+type stagesValue struct {
+	stages *[]config.Stage
+}
+
+// String returns a string representation of the referenced stages.
+func (v stagesValue) String() string {
+	return fmt.Sprint(v.stages)
+}
+
+// Set reads input string as comma-separated stages in format
+// "<duration>:<concurrency>" or "<duration>:<rate>/s" and appends them
+// to the referenced stages.
+func (v stagesValue) Set(in string) error {
+	for _, raw := range strings.Split(in, ",") {
+		stage, err := parseStage(raw)
+		if err != nil {
+			return err
+		}
+		*v.stages = append(*v.stages, stage)
+	}
+	return nil
+}
+
+// parseStage parses a single stage in format "<duration>:<concurrency>"
+// or "<duration>:<rate>/s".
+func parseStage(raw string) (config.Stage, error) {
+	errFormat := fmt.Errorf(
+		`expect format "<duration>:<concurrency>" or "<duration>:<rate>/s", got "%s"`, raw,
+	)
+
+	split := strings.SplitN(raw, ":", 2)
+	if len(split) != 2 {
+		return config.Stage{}, errFormat
+	}
+
+	duration, err := time.ParseDuration(split[0])
+	if err != nil {
+		return config.Stage{}, errFormat
+	}
+
+	target, isRate := split[1], strings.HasSuffix(split[1], "/s")
+	if isRate {
+		target = strings.TrimSuffix(target, "/s")
+	}
+
+	n, err := strconv.Atoi(target)
+	if err != nil {
+		return config.Stage{}, errFormat
+	}
+
+	if isRate {
+		return config.Stage{Duration: duration, Rate: n}, nil
+	}
+	return config.Stage{Duration: duration, Concurrency: n}, nil
+}
@@ -0,0 +1,32 @@
+package configflags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/benchttp/runner/config"
+)
+
+// thresholdsValue implements flag.Value
+type thresholdsValue struct {
+	thresholds *[]config.Threshold
+}
+
+// String returns a string representation of the referenced thresholds.
+func (v thresholdsValue) String() string {
+	return fmt.Sprint(v.thresholds)
+}
+
+// Set reads input string as comma-separated thresholds in format
+// "<metric> <operator> <value>" and appends them to the referenced
+// thresholds.
+func (v thresholdsValue) Set(in string) error {
+	for _, raw := range strings.Split(in, ",") {
+		threshold, err := config.ParseThreshold(raw)
+		if err != nil {
+			return err
+		}
+		*v.thresholds = append(*v.thresholds, threshold)
+	}
+	return nil
+}
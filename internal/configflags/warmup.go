@@ -0,0 +1,27 @@
+package configflags
+
+import "github.com/benchttp/runner/config"
+
+// warmupValue implements flag.Value
+type warmupValue struct {
+	warmup *config.Warmup
+}
+
+// String returns a string representation of the referenced warmup.
+func (v warmupValue) String() string {
+	if v.warmup == nil {
+		return ""
+	}
+	return v.warmup.String()
+}
+
+// Set reads input string as a number of requests or a duration
+// and sets it as the referenced warmup's.
+func (v warmupValue) Set(in string) error {
+	warmup, err := config.ParseWarmup(in)
+	if err != nil {
+		return err
+	}
+	v.warmup.Requests, v.warmup.Duration = warmup.Requests, warmup.Duration
+	return nil
+}
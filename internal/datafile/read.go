@@ -0,0 +1,131 @@
+// Package datafile reads the test data files fed to the requests
+// of a benchmark.
+package datafile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrFileNotFound signals a data file not found.
+	ErrFileNotFound = errors.New("data file not found")
+
+	// ErrFileRead signals an error trying to read a data file.
+	ErrFileRead = errors.New("invalid data file")
+
+	// ErrFileExt signals an unsupported extension for the data file.
+	ErrFileExt = errors.New("invalid data file extension, want .csv or .jsonl")
+
+	// ErrParse signals an error parsing a data file, such as a CSV row
+	// with an unexpected number of columns or a JSONL line that is not
+	// a JSON object.
+	ErrParse = errors.New("parsing error: invalid data file")
+
+	// ErrEmpty signals a data file with no rows.
+	ErrEmpty = errors.New("empty data file")
+)
+
+// Read reads the data file filename and returns its rows as maps
+// of column names to values, or the first non-nil error occurring
+// in the process.
+//
+// Supported formats are:
+//
+//	.csv:   the first row holds the column names
+//	.jsonl: each line is a JSON object, its keys being the column names.
+//	        String values are kept as is, other values are compact JSON.
+func Read(filename string) ([]map[string]string, error) {
+	f, err := os.Open(filename)
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filename)
+	default:
+		return nil, fmt.Errorf("%w: %s: %s", ErrFileRead, filename, err)
+	}
+	defer f.Close()
+
+	var rows []map[string]string
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".csv":
+		rows, err = readCSV(f)
+	case ".jsonl":
+		rows, err = readJSONL(f)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrFileExt, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrParse, filename, err)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrEmpty, filename)
+	}
+	return rows, nil
+}
+
+// readCSV reads r as CSV, the first row holding the column names.
+func readCSV(r io.Reader) ([]map[string]string, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns, records := records[0], records[1:]
+	rows := make([]map[string]string, len(records))
+	for i, record := range records {
+		rows[i] = make(map[string]string, len(columns))
+		for j, column := range columns {
+			rows[i][column] = record[j]
+		}
+	}
+	return rows, nil
+}
+
+// readJSONL reads r as JSON lines, each of them being a JSON object.
+// Empty lines are ignored.
+func readJSONL(r io.Reader) ([]map[string]string, error) {
+	rows := []map[string]string{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(line, &object); err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+
+		row := make(map[string]string, len(object))
+		for key, raw := range object {
+			var s string
+			if err := json.Unmarshal(raw, &s); err == nil {
+				row[key] = s
+				continue
+			}
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, raw); err != nil {
+				return nil, fmt.Errorf("line %d: %s", n, err)
+			}
+			row[key] = compact.String()
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
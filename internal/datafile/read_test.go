@@ -0,0 +1,71 @@
+package datafile_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/benchttp/runner/internal/datafile"
+)
+
+func TestRead(t *testing.T) {
+	t.Run("read rows for all extensions", func(t *testing.T) {
+		testcases := []struct {
+			filename string
+			exp      []map[string]string
+		}{
+			{
+				filename: "users.csv",
+				exp: []map[string]string{
+					{"id": "1", "name": "alice"},
+					{"id": "2", "name": "bob, jr"},
+				},
+			},
+			{
+				filename: "users.jsonl",
+				exp: []map[string]string{
+					{"id": "1", "name": "alice", "tags": `["a","b"]`},
+					{"id": "2", "name": "bob"},
+				},
+			},
+		}
+
+		for _, tc := range testcases {
+			got, err := datafile.Read(dataPath(tc.filename))
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.filename, err)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("%s:\nexp %v\ngot %v", tc.filename, tc.exp, got)
+			}
+		}
+	})
+
+	t.Run("return file errors", func(t *testing.T) {
+		testcases := []struct {
+			filename string
+			expErr   error
+		}{
+			{filename: "does-not-exist.csv", expErr: datafile.ErrFileNotFound},
+			{filename: "users.txt", expErr: datafile.ErrFileExt},
+			{filename: "badcolumns.csv", expErr: datafile.ErrParse},
+			{filename: "badline.jsonl", expErr: datafile.ErrParse},
+			{filename: "empty.csv", expErr: datafile.ErrEmpty},
+		}
+
+		for _, tc := range testcases {
+			rows, err := datafile.Read(dataPath(tc.filename))
+			if !errors.Is(err, tc.expErr) {
+				t.Errorf("%s: exp %v, got %v", tc.filename, tc.expErr, err)
+			}
+			if rows != nil {
+				t.Errorf("%s: exp nil rows, got %v", tc.filename, rows)
+			}
+		}
+	})
+}
+
+func dataPath(name string) string {
+	return filepath.Join("testdata", name)
+}
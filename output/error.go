@@ -20,6 +20,10 @@ var (
 	// using the function {{ fail }} in an output template.
 	ErrTemplateFailTriggered = errors.New("test failed")
 
+	// ErrThresholdsFailed reports one or many thresholds set in the config
+	// that were not met by the benchmark results.
+	ErrThresholdsFailed = errors.New("thresholds failed")
+
 	errTemplateEmpty  = errors.New("empty template")
 	errTemplateSyntax = errors.New("template syntax error")
 )
@@ -9,6 +9,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,14 +27,6 @@ var (
 	exportHTTP     = export.HTTP
 )
 
-type basicStats struct {
-	Min, Max, Mean time.Duration
-}
-
-func (s basicStats) isZero() bool {
-	return s == basicStats{}
-}
-
 // Report represent a benchmark result as exported by the runner.
 type Report struct {
 	Benchmark requester.Benchmark
@@ -41,23 +34,33 @@ type Report struct {
 		Config     config.Global
 		FinishedAt time.Time
 	}
+	Stats      requester.Stats
+	Phases     requester.PhasesStats
+	TimeSeries []requester.TimeBucket
+	Statuses   requester.StatusStats
+	Errors     requester.ErrorsStats
+	Thresholds []ThresholdResult
 
-	userToken string
+	// WarmupStats holds the stats of the warmup, if it is reported.
+	WarmupStats *requester.Stats
 
-	stats basicStats
+	userToken string
 
 	errTemplateFailTriggered error
 
 	log func(v ...interface{})
 }
 
+// numTopErrors is the number of most frequent errors of a Report.
+const numTopErrors = 10
+
 // New returns a Report initialized with the input benchmark, the config
 // used to run it and a user token. The user token is used to send export
 // the Report to Benchttp server. If config.OutputBenchttp is not set in
 // cfg, then the token is ignored.
 func New(bk requester.Benchmark, cfg config.Global, token string) *Report {
 	outputLogger := newLogger(cfg.Output.Silent)
-	return &Report{
+	rep := &Report{
 		Benchmark: bk,
 		Metadata: struct {
 			Config     config.Global
@@ -66,10 +69,21 @@ func New(bk requester.Benchmark, cfg config.Global, token string) *Report {
 			Config:     cfg,
 			FinishedAt: time.Now(),
 		},
+		Stats:      bk.Stats(),
+		Phases:     bk.PhasesStats(),
+		TimeSeries: bk.TimeSeries(),
+		Statuses:   bk.StatusStats(),
+		Errors:     bk.ErrorsStats(numTopErrors),
 
 		userToken: token,
 		log:       outputLogger.Println,
 	}
+	if bk.Warmup != nil {
+		stats := bk.Warmup.Stats()
+		rep.WarmupStats = &stats
+	}
+	rep.Thresholds = rep.checkThresholds()
+	return rep
 }
 
 // newLogger returns the logger to be used by Report.
@@ -113,7 +127,13 @@ func (rep *Report) Export() error {
 	if len(errs) != 0 {
 		return &ExportError{Errors: errs}
 	}
-	return rep.errTemplateFailTriggered
+	if rep.errTemplateFailTriggered != nil {
+		return rep.errTemplateFailTriggered
+	}
+	if failed := rep.failedThresholds(); len(failed) != 0 {
+		return fmt.Errorf("%w: %s", ErrThresholdsFailed, strings.Join(failed, ", "))
+	}
+	return nil
 }
 
 // exportJSONFile exports the Report as a timestamped JSON file
@@ -183,21 +203,278 @@ func (rep *Report) String() string {
 	}
 
 	var (
-		bk             = rep.Benchmark
-		cfg            = rep.Metadata.Config
-		min, max, mean = bk.Stats()
+		bk    = rep.Benchmark
+		cfg   = rep.Metadata.Config
+		stats = rep.Stats
 	)
 
-	b.WriteString(line("Endpoint", cfg.Request.URL))
-	b.WriteString(line("Requests", formatRequests(bk.Length, cfg.Runner.Requests)))
+	maxRequests := cfg.Runner.Requests
+	switch numEndpoint, numStep := len(cfg.Endpoints), len(cfg.Scenario); {
+	case numEndpoint > 0:
+		b.WriteString(line("Endpoints", fmt.Sprintf("%d weighted endpoints", numEndpoint)))
+	case numStep > 0:
+		b.WriteString(line("Scenario", fmt.Sprintf("%d steps", numStep)))
+		if maxRequests != -1 {
+			maxRequests *= numStep
+		}
+	default:
+		b.WriteString(line("Endpoint", cfg.Request.URL))
+	}
+	if w := bk.Warmup; w != nil && rep.WarmupStats != nil {
+		b.WriteString(line("Warmup", fmt.Sprintf(
+			"%d requests, %d errors, mean %s, max %s",
+			w.Length, w.Fail, msString(rep.WarmupStats.Mean), msString(rep.WarmupStats.Max),
+		)))
+	}
+	b.WriteString(line("Requests", formatRequests(bk.Length, maxRequests)))
 	b.WriteString(line("Errors", bk.Fail))
-	b.WriteString(line("Min response time", msString(min)))
-	b.WriteString(line("Max response time", msString(max)))
-	b.WriteString(line("Mean response time", msString(mean)))
+	if cfg.Runner.Rate > 0 {
+		b.WriteString(line("Dropped", bk.Dropped))
+		b.WriteString(line("Late", bk.Late))
+	}
+	if bk.Canceled > 0 {
+		b.WriteString(line("Canceled", bk.Canceled))
+	}
+	if bk.Connections > 0 {
+		b.WriteString(line("Connections", bk.Connections))
+	}
+	if protocols := formatProtocols(bk.Protocols()); protocols != "" {
+		b.WriteString(line("Protocols", protocols))
+	}
+	if codes := formatStatusCodes(rep.Statuses.Codes); codes != "" {
+		b.WriteString(line("Status codes", codes))
+	}
+	if kinds := formatErrorKinds(rep.Errors.Kinds); kinds != "" {
+		b.WriteString(line("Error kinds", kinds))
+	}
+	if len(bk.TLS) > 0 {
+		b.WriteString(line("TLS", formatTLS(bk.TLS)))
+	}
+	b.WriteString(line("Min response time", msString(stats.Min)))
+	b.WriteString(line("Max response time", msString(stats.Max)))
+	b.WriteString(line("Mean response time", msString(stats.Mean)))
+	b.WriteString(line("Std deviation", msString(stats.StdDev)))
+	b.WriteString(line("50th percentile", msString(stats.P50)))
+	b.WriteString(line("75th percentile", msString(stats.P75)))
+	b.WriteString(line("90th percentile", msString(stats.P90)))
+	b.WriteString(line("95th percentile", msString(stats.P95)))
+	b.WriteString(line("99th percentile", msString(stats.P99)))
+	b.WriteString(line("99.9th percentile", msString(stats.P999)))
 	b.WriteString(line("Total duration", msString(bk.Duration)))
+	if bk.RecordsDir != "" {
+		b.WriteString(line("Records", bk.RecordsDir))
+	}
+
+	if numStage := len(cfg.Runner.Stages); numStage > 0 {
+		for i, st := range bk.StagesStats(numStage) {
+			b.WriteString(line(
+				fmt.Sprintf("Stage #%d", i+1),
+				fmt.Sprintf(
+					"%d requests, %d errors, mean %s, max %s",
+					st.Length, st.Fail, msString(st.Mean), msString(st.Max),
+				),
+			))
+		}
+	}
+
+	if numEndpoint := len(cfg.Endpoints); numEndpoint > 0 {
+		totalWeight := 0
+		for _, e := range cfg.Endpoints {
+			totalWeight += e.Weight
+		}
+		for i, st := range bk.EndpointsStats(numEndpoint) {
+			e := cfg.Endpoints[i]
+			b.WriteString(line(
+				fmt.Sprintf("Endpoint #%d", i+1),
+				fmt.Sprintf(
+					"%s (%d%%): %d requests, %d errors, mean %s, max %s",
+					requestName(e.Name, e.Request), 100*e.Weight/totalWeight,
+					st.Length, st.Fail, msString(st.Mean), msString(st.Max),
+				),
+			))
+		}
+	}
+
+	if numStep := len(cfg.Scenario); numStep > 0 {
+		for i, st := range bk.StepsStats(numStep) {
+			b.WriteString(line(
+				fmt.Sprintf("Step #%d", i+1),
+				fmt.Sprintf(
+					"%s: %d requests, %d errors, mean %s, max %s",
+					requestName(cfg.Scenario[i].Name, cfg.Scenario[i].Request), st.Length, st.Fail, msString(st.Mean), msString(st.Max),
+				),
+			))
+		}
+	}
+
+	if phases := rep.Phases; phases.Length > 0 {
+		b.WriteString("\nPhases\n")
+		type phase struct {
+			name  string
+			stats requester.Stats
+		}
+		list := []phase{}
+		if !allUnixSockets(cfg) {
+			list = append(list, phase{"DNS", phases.DNS})
+		}
+		list = append(list, phase{"Connect", phases.Connect})
+		if phases.Proxy.Max > 0 {
+			list = append(list, phase{"Proxy", phases.Proxy})
+		}
+		list = append(list,
+			phase{"TLS", phases.TLS},
+			phase{"Write", phases.Write},
+			phase{"Wait (TTFB)", phases.Wait},
+			phase{"Transfer", phases.Transfer},
+		)
+		for _, p := range list {
+			b.WriteString(line(p.name, fmt.Sprintf(
+				"mean %s, p95 %s, max %s",
+				p.stats.Mean.Round(time.Microsecond),
+				p.stats.P95.Round(time.Microsecond),
+				p.stats.Max.Round(time.Microsecond),
+			)))
+		}
+		b.WriteString(line("Reused connections", fmt.Sprintf("%d/%d", phases.Reused, phases.Length)))
+	}
+
+	if len(rep.Errors.Top) > 0 {
+		b.WriteString("\nTop errors\n")
+		for _, e := range rep.Errors.Top {
+			b.WriteString(fmt.Sprintf("%6d  %-18s %s\n", e.Count, e.Kind, e.Message))
+		}
+	}
+
+	if len(bk.Checks) > 0 {
+		b.WriteString("\nChecks\n")
+		for _, c := range bk.Checks {
+			b.WriteString(formatCheckResult(c))
+			b.WriteString("\n")
+		}
+	}
+
+	if len(rep.Thresholds) > 0 {
+		b.WriteString("\nThresholds\n")
+		for _, r := range rep.Thresholds {
+			b.WriteString(r.String())
+			b.WriteString("\n")
+		}
+	}
 	return b.String()
 }
 
+// requestName returns name if it is not empty, else the method and URL
+// path of req.
+func requestName(name string, req config.Request) string {
+	if name != "" {
+		return name
+	}
+	if req.URL == nil {
+		return req.Method
+	}
+	return req.Method + " " + req.URL.Path
+}
+
+// formatProtocols returns the protocols and their number of responses
+// counts, e.g. "HTTP/1.1 (2), HTTP/2.0 (1)", or an empty string if counts
+// is empty.
+func formatProtocols(counts map[string]int) string {
+	protocols := make([]string, 0, len(counts))
+	for protocol := range counts {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+
+	for i, protocol := range protocols {
+		protocols[i] = fmt.Sprintf("%s (%d)", protocol, counts[protocol])
+	}
+	return strings.Join(protocols, ", ")
+}
+
+// formatStatusCodes returns the status codes and their number of
+// responses, e.g. "200 (9), 503 (1)", or an empty string if counts
+// is empty.
+func formatStatusCodes(counts map[int]int) string {
+	codes := make([]int, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	s := make([]string, len(codes))
+	for i, code := range codes {
+		s[i] = fmt.Sprintf("%d (%d)", code, counts[code])
+	}
+	return strings.Join(s, ", ")
+}
+
+// formatErrorKinds returns the error kinds and their number of records,
+// the most frequent first, e.g. "timeout (3), connectionRefused (1)",
+// or an empty string if counts is empty.
+func formatErrorKinds(counts map[string]int) string {
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if counts[kinds[i]] != counts[kinds[j]] {
+			return counts[kinds[i]] > counts[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+
+	for i, kind := range kinds {
+		kinds[i] = fmt.Sprintf("%s (%d)", kind, counts[kind])
+	}
+	return strings.Join(kinds, ", ")
+}
+
+// allUnixSockets returns true if all the requests of cfg are sent
+// to Unix sockets, for which no DNS lookup occurs.
+func allUnixSockets(cfg config.Global) bool {
+	var requests []config.Request
+	switch {
+	case len(cfg.Endpoints) > 0:
+		for _, e := range cfg.Endpoints {
+			requests = append(requests, e.Request)
+		}
+	case len(cfg.Scenario) > 0:
+		for _, s := range cfg.Scenario {
+			requests = append(requests, s.Request)
+		}
+	default:
+		requests = append(requests, cfg.Request)
+	}
+
+	for _, r := range requests {
+		if r.UnixSocket == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTLS returns the TLS parameters and their number of responses
+// as a comma-separated list, e.g. "1.3 TLS_AES_128_GCM_SHA256 (3)".
+func formatTLS(params []requester.TLSParams) string {
+	s := make([]string, len(params))
+	for i, p := range params {
+		s[i] = fmt.Sprintf("%s %s (%d)", p.Version, p.CipherSuite, p.Responses)
+	}
+	return strings.Join(s, ", ")
+}
+
+// formatCheckResult returns a row of the checks table for c:
+// its pass rate, number of passes over checked responses, and name.
+func formatCheckResult(c requester.CheckResult) string {
+	total := c.Pass + c.Fail
+	rate := 0.0
+	if total > 0 {
+		rate = float64(c.Pass) / float64(total) * 100
+	}
+	return fmt.Sprintf("%6.2f%%  %-12s %s", rate, fmt.Sprintf("%d/%d", c.Pass, total), c.Name)
+}
+
 // HTTPRequest returns the *http.Request to be sent to Benchttp server.
 // The Report is encoded as gob in the request body.
 func (rep *Report) HTTPRequest() (*http.Request, error) {
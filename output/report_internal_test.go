@@ -106,6 +106,25 @@ func TestReport_Export(t *testing.T) {
 			)
 		}
 	})
+
+	t.Run("return ErrThresholdsFailed if any threshold fails", func(t *testing.T) {
+		mockExportFuncs(true, true)
+
+		cfg := newConfigWithStrat(config.OutputStdout)
+		cfg.Thresholds = []config.Threshold{
+			{Metric: "requests", Operator: "==", Value: 0},
+			{Metric: "requests", Operator: ">", Value: 0},
+		}
+		rep := New(requester.Benchmark{}, cfg, "")
+
+		err := rep.Export()
+		if !errors.Is(err, ErrThresholdsFailed) {
+			t.Fatalf("unexpected error:\nexp ErrThresholdsFailed\ngot %v", err)
+		}
+		if exp := "thresholds failed: requests > 0"; err.Error() != exp {
+			t.Errorf("unexpected error message:\nexp %q\ngot %q", exp, err)
+		}
+	})
 }
 
 func TestGenFilename(t *testing.T) {
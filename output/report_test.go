@@ -55,6 +55,238 @@ func TestReport_String(t *testing.T) {
 
 		checkSummary(t, summary)
 	})
+
+	t.Run("show opened connections if any", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Connections = 2
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		if exp := "Errors             1\nConnections        2\n"; !strings.Contains(got, exp) {
+			t.Errorf("\nexp summary containing:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("show response protocols if any", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Records[0].Protocol = "HTTP/2.0"
+		bk.Records[1].Protocol = "HTTP/1.1"
+		bk.Records[2].Protocol = "HTTP/2.0"
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		if exp := "Errors             1\nProtocols          HTTP/1.1 (1), HTTP/2.0 (2)\n"; !strings.Contains(got, exp) {
+			t.Errorf("\nexp summary containing:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("show negotiated tls parameters if any", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.TLS = []requester.TLSParams{
+			{Version: "1.3", CipherSuite: "TLS_AES_128_GCM_SHA256", Responses: 2},
+			{Version: "1.2", CipherSuite: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", Responses: 1},
+		}
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		exp := "Errors             1\nTLS                1.3 TLS_AES_128_GCM_SHA256 (2), 1.2 TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 (1)\n"
+		if !strings.Contains(got, exp) {
+			t.Errorf("\nexp summary containing:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("show warmup summary if reported", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Warmup = &requester.Benchmark{
+			Length:  2,
+			Records: []requester.Record{{Time: 1 * time.Second}, {Time: 3 * time.Second}},
+		}
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		exp := "Warmup             2 requests, 0 errors, mean 2000ms, max 3000ms\nRequests           3/∞\n"
+		if !strings.Contains(got, exp) {
+			t.Errorf("\nexp summary containing:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("show status codes, error kinds and top errors if any", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Records[0].Code = 200
+		bk.Records[1].Code = 503
+		bk.Records[2].Error = `recording error: Get "https://a.b.com": net/http: timeout`
+		bk.Records[2].ErrorKind = requester.ErrorKindTimeout
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		exp := "Errors             1\nStatus codes       200 (1), 503 (1)\nError kinds        timeout (1)\n"
+		if !strings.Contains(got, exp) {
+			t.Errorf("\nexp summary containing:\n%q\ngot summary:\n%q", exp, got)
+		}
+		if exp := "\nTop errors\n     1  timeout            net/http: timeout\n"; !strings.HasSuffix(got, exp) {
+			t.Errorf("\nexp summary ending with:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("append per-stage summary if stages are set", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Records[0].Failed = []string{"status"}
+		bk.Records[2].Stage = 1
+
+		cfg := newConfigWithTemplate("")
+		cfg.Runner.Stages = []config.Stage{
+			{Duration: time.Second, Concurrency: 1},
+			{Duration: time.Second, Rate: 1},
+		}
+
+		got := output.New(bk, cfg, "").String()
+		exp := `
+Stage #1           2 requests, 1 errors, mean 5500ms, max 6000ms
+Stage #2           1 requests, 0 errors, mean 7000ms, max 7000ms
+`[1:]
+
+		if !strings.HasSuffix(got, exp) {
+			t.Errorf("\nexp summary ending with:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("append per-step summary if scenario is set", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Records[1].Failed = []string{"extract token"}
+		bk.Records[1].Step = 1
+
+		cfg := newConfigWithTemplate("")
+		cfg.Scenario = []config.Step{
+			{Name: "login"},
+			{Request: config.Request{Method: "GET", URL: &url.URL{Path: "/profile"}}},
+		}
+
+		got := output.New(bk, cfg, "").String()
+		if !strings.HasPrefix(got, "Scenario           2 steps\n") {
+			t.Errorf("\nexp summary starting with scenario line\ngot summary:\n%q", got)
+		}
+
+		exp := `
+Step #1            login: 2 requests, 0 errors, mean 6000ms, max 7000ms
+Step #2            GET /profile: 1 requests, 1 errors, mean 6000ms, max 6000ms
+`[1:]
+		if !strings.HasSuffix(got, exp) {
+			t.Errorf("\nexp summary ending with:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("append per-endpoint summary if endpoints are set", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Records[0].Failed = []string{"status"}
+		bk.Records[1].Endpoint = 1
+
+		cfg := newConfigWithTemplate("")
+		cfg.Endpoints = []config.Endpoint{
+			{Name: "list", Weight: 3},
+			{Weight: 1, Request: config.Request{Method: "POST", URL: &url.URL{Path: "/items"}}},
+		}
+
+		got := output.New(bk, cfg, "").String()
+		if !strings.HasPrefix(got, "Endpoints          2 weighted endpoints\n") {
+			t.Errorf("\nexp summary starting with endpoints line\ngot summary:\n%q", got)
+		}
+
+		exp := `
+Endpoint #1        list (75%): 2 requests, 1 errors, mean 6000ms, max 7000ms
+Endpoint #2        POST /items (25%): 1 requests, 0 errors, mean 6000ms, max 6000ms
+`[1:]
+		if !strings.HasSuffix(got, exp) {
+			t.Errorf("\nexp summary ending with:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("append per-phase summary if records have timing", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Records[0].Timing = requester.Timing{Connect: 2 * time.Millisecond, Wait: 4 * time.Millisecond}
+		bk.Records[1].Timing = requester.Timing{Wait: 6 * time.Millisecond, Reused: true}
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		exp := `
+Phases
+DNS                mean 0s, p95 0s, max 0s
+Connect            mean 2ms, p95 2ms, max 2ms
+TLS                mean 0s, p95 0s, max 0s
+Write              mean 0s, p95 0s, max 0s
+Wait (TTFB)        mean 5ms, p95 6ms, max 6ms
+Transfer           mean 0s, p95 0s, max 0s
+Reused connections 1/2
+`
+
+		if !strings.HasSuffix(got, exp) {
+			t.Errorf("\nexp summary ending with:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("omit dns phase for unix sockets", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Records[0].Timing = requester.Timing{Connect: 2 * time.Millisecond}
+		cfg := newConfigWithTemplate("")
+		cfg.Request.UnixSocket = "/var/run/app.sock"
+
+		got := output.New(bk, cfg, "").String()
+		if exp := "\nPhases\nConnect "; !strings.Contains(got, exp) {
+			t.Errorf("\nexp summary containing:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("show proxy phase if requests were proxied", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Records[0].Timing = requester.Timing{Connect: 2 * time.Millisecond, Proxy: 3 * time.Millisecond}
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		exp := "Connect            mean 2ms, p95 2ms, max 2ms\nProxy              mean 3ms, p95 3ms, max 3ms\nTLS "
+		if !strings.Contains(got, exp) {
+			t.Errorf("\nexp summary containing:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("show records directory in disk storage", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.RecordsDir = "/tmp/records"
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		if exp := "\nRecords            /tmp/records\n"; !strings.Contains(got, exp) {
+			t.Errorf("\nexp summary containing:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("append checks pass rates if checks are set", func(t *testing.T) {
+		bk := newBenchmark()
+		bk.Checks = []requester.CheckResult{
+			{Name: "status 2xx", Pass: 3, Fail: 0},
+			{Name: `body contains "ok"`, Pass: 1, Fail: 2},
+		}
+
+		got := output.New(bk, newConfigWithTemplate(""), "").String()
+		exp := `
+Checks
+100.00%  3/3          status 2xx
+ 33.33%  1/3          body contains "ok"
+`
+
+		if !strings.HasSuffix(got, exp) {
+			t.Errorf("\nexp summary ending with:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
+
+	t.Run("append thresholds table if thresholds are set", func(t *testing.T) {
+		cfg := newConfigWithTemplate("")
+		cfg.Thresholds = []config.Threshold{
+			{Metric: "p95", Operator: "<", Value: float64(7500 * time.Millisecond)},
+			{Metric: "errorRate", Operator: "<", Value: 1},
+		}
+
+		got := output.New(newBenchmark(), cfg, "").String()
+		exp := `
+Thresholds
+PASS  p95 < 7.5s               got 7s
+FAIL  errorRate < 1%           got 33.33%
+`
+
+		if !strings.HasSuffix(got, exp) {
+			t.Errorf("\nexp summary ending with:\n%q\ngot summary:\n%q", exp, got)
+		}
+	})
 }
 
 func TestReport_HTTPRequest(t *testing.T) {
@@ -105,6 +337,13 @@ Errors             1
 Min response time  5000ms
 Max response time  7000ms
 Mean response time 6000ms
+Std deviation      816ms
+50th percentile    6000ms
+75th percentile    7000ms
+90th percentile    7000ms
+95th percentile    7000ms
+99th percentile    7000ms
+99.9th percentile  7000ms
 Total duration     4000ms
 `[1:]
 
@@ -169,10 +408,12 @@ func sameReports(a, b *output.Report) bool {
 		output.Report{
 			Benchmark: a.Benchmark,
 			Metadata:  a.Metadata,
+			Stats:     a.Stats,
 		},
 		output.Report{
 			Benchmark: b.Benchmark,
 			Metadata:  bMetadata,
+			Stats:     b.Stats,
 		},
 	)
 }
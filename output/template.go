@@ -35,16 +35,24 @@ func (rep *Report) applyTemplate(pattern string) (string, error) {
 }
 
 // templateFuncs returns a template.FuncMap defining template functions
-// that are specific to the Report: stats, event, fail.
+// that are specific to the Report: stats, phases, series, event, phase, fail.
 func (rep *Report) templateFuncs() template.FuncMap {
 	return template.FuncMap{
-		// stats computes basic stats for the Report if not already done,
-		// and returns the results as basicStats.
-		"stats": func() basicStats {
-			if rep.stats.isZero() {
-				rep.stats.Min, rep.stats.Max, rep.stats.Mean = rep.Benchmark.Stats()
-			}
-			return rep.stats
+		// stats returns the stats computed for the Report.
+		"stats": func() requester.Stats {
+			return rep.Stats
+		},
+
+		// phases returns the stats of each request phase computed
+		// for the Report.
+		"phases": func() requester.PhasesStats {
+			return rep.Phases
+		},
+
+		// series returns the stats of each time bucket of the run
+		// computed for the Report.
+		"series": func() []requester.TimeBucket {
+			return rep.TimeSeries
 		},
 
 		// event retrieves an event from the input record given a its name
@@ -58,6 +66,28 @@ func (rep *Report) templateFuncs() template.FuncMap {
 			return 0
 		},
 
+		// phase retrieves the duration of a phase of the input record
+		// given its name: dns, connect, proxy, tls, write, wait or transfer.
+		"phase": func(rec requester.Record, name string) time.Duration {
+			switch name {
+			case "dns":
+				return rec.Timing.DNS
+			case "connect":
+				return rec.Timing.Connect
+			case "proxy":
+				return rec.Timing.Proxy
+			case "tls":
+				return rec.Timing.TLS
+			case "write":
+				return rec.Timing.Write
+			case "wait":
+				return rec.Timing.Wait
+			case "transfer":
+				return rec.Timing.Transfer
+			}
+			return 0
+		},
+
 		// fail sets rep.errTplFailTriggered to the given error, causing
 		// the test to fail
 		"fail": func(a ...interface{}) string {
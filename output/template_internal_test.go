@@ -61,16 +61,17 @@ func TestReport_templateFuncs(t *testing.T) {
 
 		v := retrieveTemplateFuncOrFatal(t, rep, "stats")
 
-		f, ok := v.(func() basicStats)
+		f, ok := v.(func() requester.Stats)
 		if !ok {
-			t.Fatalf("wrong type:\nexp func() basicStats\ngot %T", v)
+			t.Fatalf("wrong type:\nexp func() requester.Stats\ngot %T", v)
 		}
 
-		if gotStats := f(); (gotStats != basicStats{
-			Min:  1 * time.Second,
-			Max:  3 * time.Second,
-			Mean: 2 * time.Second,
-		}) {
+		gotStats := f()
+		if gotStats.Min != 1*time.Second ||
+			gotStats.Max != 3*time.Second ||
+			gotStats.Mean != 2*time.Second ||
+			gotStats.P50 != 1*time.Second ||
+			gotStats.P99 != 3*time.Second {
 			t.Errorf("unexpected stats: %+v", gotStats)
 		}
 	})
@@ -100,6 +101,56 @@ func TestReport_templateFuncs(t *testing.T) {
 		})
 	})
 
+	t.Run("phases", func(t *testing.T) {
+		rep := newFilledReport()
+
+		v := retrieveTemplateFuncOrFatal(t, rep, "phases")
+
+		f, ok := v.(func() requester.PhasesStats)
+		if !ok {
+			t.Fatalf("wrong type:\nexp func() requester.PhasesStats\ngot %T", v)
+		}
+
+		if got := f(); got.Length != 2 || got.Wait.Max != 300*time.Millisecond {
+			t.Errorf("unexpected phases stats: %+v", got)
+		}
+	})
+
+	t.Run("series", func(t *testing.T) {
+		rep := newFilledReport()
+
+		v := retrieveTemplateFuncOrFatal(t, rep, "series")
+
+		f, ok := v.(func() []requester.TimeBucket)
+		if !ok {
+			t.Fatalf("wrong type:\nexp func() []requester.TimeBucket\ngot %T", v)
+		}
+
+		got := f()
+		if len(got) != 2 || got[1].Start != time.Second || got[1].Length != 1 || got[1].Max != 3*time.Second {
+			t.Errorf("unexpected time series: %+v", got)
+		}
+	})
+
+	t.Run("phase", func(t *testing.T) {
+		rep := newFilledReport()
+
+		v := retrieveTemplateFuncOrFatal(t, rep, "phase")
+
+		f, ok := v.(func(requester.Record, string) time.Duration)
+		if !ok {
+			t.Fatalf("wrong type:\nexp func(requester.Record, string) time.Duration\ngot %T", v)
+		}
+
+		rec := rep.Benchmark.Records[0]
+		if got, exp := f(rec, "wait"), rec.Timing.Wait; got != exp {
+			t.Errorf("unexpected time: exp %s, got %s", exp, got)
+		}
+		if got, exp := f(rec, "nomatch"), time.Duration(0); got != exp {
+			t.Errorf("unexpected time: exp %s, got %s", exp, got)
+		}
+	})
+
 	t.Run("fail", func(t *testing.T) {
 		rep := newFilledReport()
 
@@ -137,24 +188,26 @@ func retrieveTemplateFuncOrFatal(t *testing.T, r *Report, name string) interface
 
 // newFilledReport returns a new report with some values set.
 func newFilledReport() *Report {
-	return &Report{
-		Benchmark: requester.Benchmark{
-			Records: []requester.Record{
-				{
-					Time: 1 * time.Second,
-					Events: []requester.Event{
-						{Name: "event0", Time: 400 * time.Millisecond},
-						{Name: "event1", Time: 600 * time.Millisecond},
-					},
+	bk := requester.Benchmark{
+		Records: []requester.Record{
+			{
+				Time: 1 * time.Second,
+				Events: []requester.Event{
+					{Name: "event0", Time: 400 * time.Millisecond},
+					{Name: "event1", Time: 600 * time.Millisecond},
 				},
-				{
-					Time: 3 * time.Second,
-					Events: []requester.Event{
-						{Name: "event0", Time: 2 * time.Second},
-						{Name: "event1", Time: 1 * time.Second},
-					},
+				Timing: requester.Timing{Wait: 100 * time.Millisecond},
+			},
+			{
+				Start: 1500 * time.Millisecond,
+				Time:  3 * time.Second,
+				Events: []requester.Event{
+					{Name: "event0", Time: 2 * time.Second},
+					{Name: "event1", Time: 1 * time.Second},
 				},
+				Timing: requester.Timing{Wait: 300 * time.Millisecond},
 			},
 		},
 	}
+	return &Report{Benchmark: bk, Stats: bk.Stats(), Phases: bk.PhasesStats(), TimeSeries: bk.TimeSeries()}
 }
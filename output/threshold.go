@@ -0,0 +1,133 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/benchttp/runner/config"
+)
+
+// ThresholdResult is the result of a config.Threshold checked against
+// the Report once the benchmark is done.
+type ThresholdResult struct {
+	Threshold config.Threshold
+	Value     float64
+	Pass      bool
+}
+
+// String returns a string representation of the ThresholdResult
+// as a row of the thresholds table.
+func (r ThresholdResult) String() string {
+	status := "PASS"
+	if !r.Pass {
+		status = "FAIL"
+	}
+	return fmt.Sprintf("%s  %-24s got %s", status, r.Threshold, r.Threshold.FormatValue(r.Value))
+}
+
+// checkThresholds checks the thresholds set in the Report's config
+// against its results and returns the results.
+func (rep *Report) checkThresholds() []ThresholdResult {
+	thresholds := rep.Metadata.Config.Thresholds
+	if len(thresholds) == 0 {
+		return nil
+	}
+
+	results := make([]ThresholdResult, len(thresholds))
+	for i, threshold := range thresholds {
+		v := rep.metricValue(threshold.Metric)
+		results[i] = ThresholdResult{
+			Threshold: threshold,
+			Value:     v,
+			Pass:      threshold.Check(v),
+		}
+	}
+	return results
+}
+
+// failedThresholds returns the string representations of the thresholds
+// that did not pass.
+func (rep *Report) failedThresholds() []string {
+	var failed []string
+	for _, r := range rep.Thresholds {
+		if !r.Pass {
+			failed = append(failed, r.Threshold.String())
+		}
+	}
+	return failed
+}
+
+// metricValue returns the value of the given threshold metric
+// for the Report, in the unit expected by config.Threshold.
+func (rep *Report) metricValue(metric string) float64 {
+	bk, stats := rep.Benchmark, rep.Stats
+
+	switch metric {
+	case "min":
+		return float64(stats.Min)
+	case "max":
+		return float64(stats.Max)
+	case "mean":
+		return float64(stats.Mean)
+	case "stdDev":
+		return float64(stats.StdDev)
+	case "p50":
+		return float64(stats.P50)
+	case "p75":
+		return float64(stats.P75)
+	case "p90":
+		return float64(stats.P90)
+	case "p95":
+		return float64(stats.P95)
+	case "p99":
+		return float64(stats.P99)
+	case "p999":
+		return float64(stats.P999)
+	case "errorRate":
+		if bk.Length == 0 {
+			return 0
+		}
+		return float64(bk.Fail) / float64(bk.Length) * 100
+	case "rps":
+		if bk.Duration <= 0 {
+			return 0
+		}
+		return float64(bk.Length) / bk.Duration.Seconds()
+	case "requests":
+		return float64(bk.Length)
+	case "errors":
+		return float64(bk.Fail)
+	case "dropped":
+		return float64(bk.Dropped)
+	case "late":
+		return float64(bk.Late)
+	}
+
+	if code := strings.TrimPrefix(metric, "status."); code != metric {
+		return float64(rep.countStatus(code))
+	}
+	return 0
+}
+
+// countStatus returns the number of records with a status code matching
+// code, that is either a full code (e.g. "404") or a class (e.g. "5xx").
+func (rep *Report) countStatus(code string) int {
+	var n int
+	for status, count := range rep.Benchmark.StatusCodes() {
+		if matchStatus(status, code) {
+			n += count
+		}
+	}
+	return n
+}
+
+// matchStatus returns true if status matches code.
+func matchStatus(status int, code string) bool {
+	if strings.HasSuffix(code, "xx") {
+		class, err := strconv.Atoi(strings.TrimSuffix(code, "xx"))
+		return err == nil && status/100 == class
+	}
+	n, err := strconv.Atoi(code)
+	return err == nil && status == n
+}
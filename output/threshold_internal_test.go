@@ -0,0 +1,70 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benchttp/runner/config"
+	"github.com/benchttp/runner/requester"
+)
+
+func TestReport_checkThresholds(t *testing.T) {
+	bk := requester.Benchmark{
+		Length:   4,
+		Success:  3,
+		Fail:     1,
+		Dropped:  2,
+		Late:     3,
+		Duration: 2 * time.Second,
+		Records: []requester.Record{
+			{Time: 100 * time.Millisecond, Code: 200},
+			{Time: 200 * time.Millisecond, Code: 404},
+			{Time: 300 * time.Millisecond, Code: 503},
+			{Time: 400 * time.Millisecond, Error: "timeout"},
+		},
+	}
+
+	testcases := []struct {
+		raw      string
+		expValue float64
+		expPass  bool
+	}{
+		{raw: "max < 300ms", expValue: float64(400 * time.Millisecond), expPass: false},
+		{raw: "mean <= 250ms", expValue: float64(250 * time.Millisecond), expPass: true},
+		{raw: "p50 < 300ms", expValue: float64(200 * time.Millisecond), expPass: true},
+		{raw: "errorRate < 10%", expValue: 25, expPass: false},
+		{raw: "rps >= 2", expValue: 2, expPass: true},
+		{raw: "requests == 4", expValue: 4, expPass: true},
+		{raw: "errors == 0", expValue: 1, expPass: false},
+		{raw: "dropped == 2", expValue: 2, expPass: true},
+		{raw: "late == 0", expValue: 3, expPass: false},
+		{raw: "status.5xx == 0", expValue: 1, expPass: false},
+		{raw: "status.404 < 2", expValue: 1, expPass: true},
+	}
+
+	cfg := config.Global{}
+	for _, tc := range testcases {
+		threshold, err := config.ParseThreshold(tc.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Thresholds = append(cfg.Thresholds, threshold)
+	}
+
+	rep := New(bk, cfg, "")
+	if len(rep.Thresholds) != len(testcases) {
+		t.Fatalf("exp %d results, got %d", len(testcases), len(rep.Thresholds))
+	}
+
+	for i, tc := range testcases {
+		t.Run(tc.raw, func(t *testing.T) {
+			got := rep.Thresholds[i]
+			if got.Value != tc.expValue || got.Pass != tc.expPass {
+				t.Errorf(
+					"\nexp value %v, pass %v\ngot value %v, pass %v",
+					tc.expValue, tc.expPass, got.Value, got.Pass,
+				)
+			}
+		})
+	}
+}
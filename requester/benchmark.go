@@ -2,16 +2,57 @@ package requester
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 	"time"
 )
 
 // Benchmark represents the collected results of a benchmark test.
+// Canceled records, cut short by the end of the run, are kept in Records
+// but are not counted in Length, Success and Fail.
+// In storage StorageDisk, Records is empty and the records are written
+// to the segment files of RecordsDir instead, see ReadRecords.
+// The statistics of a Benchmark returned by a run are accumulated as the
+// records are added, they are computed from Records otherwise.
+// With a rate, Dropped is the number of requests not started because
+// all workers were busy, and Late the number of requests started after
+// their scheduled time.
 type Benchmark struct {
 	Records  []Record      `json:"records"`
 	Length   int           `json:"length"`
 	Success  int           `json:"success"`
 	Fail     int           `json:"fail"`
+	Dropped  int           `json:"dropped"`
+	Late     int           `json:"late"`
+	Canceled int           `json:"canceled"`
 	Duration time.Duration `json:"duration"`
+
+	// BucketInterval is the duration of the time buckets of TimeSeries,
+	// one second if it is not set. It is doubled as many times as needed
+	// to keep the run within 1000 buckets.
+	BucketInterval time.Duration `json:"bucketInterval"`
+
+	// Warmup is the report of the warmup phase, if it is run and reported.
+	// Its records are not kept.
+	Warmup *Benchmark `json:"warmup,omitempty"`
+
+	// RecordsDir is the directory of the record files in storage
+	// StorageDisk.
+	RecordsDir string `json:"recordsDir,omitempty"`
+
+	// Connections is the number of connections opened during the run.
+	Connections int `json:"connections"`
+
+	// TLS lists the parameters negotiated over TLS, the most used first.
+	TLS []TLSParams `json:"tls,omitempty"`
+
+	Checks []CheckResult `json:"checks,omitempty"`
+
+	// Seed is the seed of the random values of the run, that can be
+	// set in the config to reproduce it.
+	Seed int64 `json:"seed"`
+
+	summary *summary
 }
 
 // String returns an indented JSON representation of the Benchmark.
@@ -20,37 +61,294 @@ func (bk Benchmark) String() string {
 	return string(b)
 }
 
-// Stats returns basic stats about the Benchmark's records:
-// min duration, max duration, and mean duration.
+// numHistogramBucket is the number of buckets of Stats.Histogram.
+const numHistogramBucket = 10
+
+// Stats holds statistics about the durations of a Benchmark's records.
+type Stats struct {
+	Min    time.Duration `json:"min"`
+	Max    time.Duration `json:"max"`
+	Mean   time.Duration `json:"mean"`
+	StdDev time.Duration `json:"stdDev"`
+	P50    time.Duration `json:"p50"`
+	P75    time.Duration `json:"p75"`
+	P90    time.Duration `json:"p90"`
+	P95    time.Duration `json:"p95"`
+	P99    time.Duration `json:"p99"`
+	P999   time.Duration `json:"p999"`
+
+	// Histogram is the distribution of the durations in buckets
+	// of equal width from Min to Max.
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// HistogramBucket is a range of durations [Min, Max) and the number
+// of records that fall in it.
+type HistogramBucket struct {
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+	Count int           `json:"count"`
+}
+
+// summarize returns the summary of the Benchmark's records.
+func (bk Benchmark) summarize() *summary {
+	if bk.summary != nil {
+		return bk.summary
+	}
+	return summarize(bk.Records, bk.BucketInterval)
+}
+
+// Stats returns statistics about the Benchmark's records durations.
+// Canceled records are ignored.
+// The durations are recorded in an HDR-style histogram, so that the memory
+// and time needed are kept low for large numbers of records, at the cost
+// of a relative error under 1% for percentiles. Min, Max, Mean and StdDev
+// are exact.
 // It does not replace the remote computing and should only be used
 // when a local reporting is needed.
-func (bk Benchmark) Stats() (min, max, mean time.Duration) {
-	n := len(bk.Records)
-	if n == 0 {
-		return 0, 0, 0
+func (bk Benchmark) Stats() Stats {
+	return newStats(&bk.summarize().times)
+}
+
+// newStats returns the Stats of the values recorded in h.
+func newStats(h *histogram) Stats {
+	return Stats{
+		Min:       h.min,
+		Max:       h.max,
+		Mean:      h.mean(),
+		StdDev:    h.stdDev(),
+		P50:       h.percentile(50),
+		P75:       h.percentile(75),
+		P90:       h.percentile(90),
+		P95:       h.percentile(95),
+		P99:       h.percentile(99),
+		P999:      h.percentile(99.9),
+		Histogram: h.distribution(numHistogramBucket),
 	}
+}
 
-	var sum time.Duration
-	for _, rec := range bk.Records {
-		d := rec.Time
-		if d < min || min == 0 {
-			min = d
-		}
-		if d > max {
-			max = d
+// PhasesStats holds statistics about the durations of each phase
+// of the Benchmark's requests (see Timing).
+type PhasesStats struct {
+	DNS      Stats `json:"dns"`
+	Connect  Stats `json:"connect"`
+	Proxy    Stats `json:"proxy"`
+	TLS      Stats `json:"tls"`
+	Write    Stats `json:"write"`
+	Wait     Stats `json:"wait"`
+	Transfer Stats `json:"transfer"`
+
+	// Length is the number of records with a timing, Reused the number
+	// of them sent on a reused connection.
+	Length int `json:"length"`
+	Reused int `json:"reused"`
+}
+
+// PhasesStats returns statistics about the durations of each phase of the
+// Benchmark's requests. Records without timing, such as failed requests,
+// and canceled records are ignored. The DNS, Connect, Proxy and TLS phases only account for
+// the requests they occurred for.
+func (bk Benchmark) PhasesStats() PhasesStats {
+	p := &bk.summarize().phases
+	return PhasesStats{
+		DNS:      newStats(&p.dns),
+		Connect:  newStats(&p.connect),
+		Proxy:    newStats(&p.proxy),
+		TLS:      newStats(&p.tls),
+		Write:    newStats(&p.write),
+		Wait:     newStats(&p.wait),
+		Transfer: newStats(&p.transfer),
+		Length:   p.length,
+		Reused:   p.reused,
+	}
+}
+
+// GroupStats holds stats about a group of records, such as the records
+// of a single stage or of a single scenario step.
+type GroupStats struct {
+	Stats
+	Length int
+	Fail   int
+}
+
+// StagesStats returns stats about the Benchmark's records for each
+// of the numStage stages of the load profile, indexed by stage.
+// Stages that have no record are left empty.
+func (bk Benchmark) StagesStats(numStage int) []GroupStats {
+	return groupStats(bk.summarize().stages, numStage)
+}
+
+// StepsStats returns stats about the Benchmark's records for each
+// of the numStep steps of the scenario, indexed by step.
+// Steps that have no record are left empty.
+func (bk Benchmark) StepsStats(numStep int) []GroupStats {
+	return groupStats(bk.summarize().steps, numStep)
+}
+
+// EndpointsStats returns stats about the Benchmark's records for each
+// of the numEndpoint endpoints of the mix, indexed by endpoint.
+// Endpoints that have no record are left empty.
+func (bk Benchmark) EndpointsStats(numEndpoint int) []GroupStats {
+	return groupStats(bk.summarize().endpoints, numEndpoint)
+}
+
+// TimeBucket holds stats about the records started during an interval
+// of the run, from Start to Start+Duration. Duration is BucketInterval,
+// except for the last bucket that ends with the run. RPS is the number of
+// records started per second during the interval. The durations are
+// recorded with a relative error under 7% to keep the memory used by
+// long runs low.
+type TimeBucket struct {
+	Stats
+	Start       time.Duration `json:"start"`
+	Duration    time.Duration `json:"duration"`
+	Length      int           `json:"length"`
+	Fail        int           `json:"fail"`
+	RPS         float64       `json:"rps"`
+	StatusCodes map[int]int   `json:"statusCodes"`
+}
+
+// TimeSeries returns stats about the Benchmark's records for each interval
+// of BucketInterval of the run, by start time of the records, from the
+// start of the run to the last interval with records. Intervals that have
+// no record are left empty. Canceled records are ignored.
+func (bk Benchmark) TimeSeries() []TimeBucket {
+	return bk.summarize().series.timeBuckets(bk.Duration)
+}
+
+// ErrorCount is the number of records failed with the same error,
+// Message being the error without the parts that depend on the request,
+// such as its URL.
+type ErrorCount struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// ErrorsStats holds the number of records of each error kind,
+// e.g. ErrorKindTimeout, and the most frequent errors, the most
+// frequent first. Canceled records are counted as ErrorKindCanceled.
+type ErrorsStats struct {
+	Kinds map[string]int `json:"kinds"`
+	Top   []ErrorCount   `json:"top"`
+}
+
+// ErrorsStats returns the number of the Benchmark's records of each
+// error kind and the numTop most frequent errors. Only the first 100
+// distinct errors of a run are counted.
+func (bk Benchmark) ErrorsStats(numTop int) ErrorsStats {
+	s := bk.summarize()
+	stats := ErrorsStats{Kinds: map[string]int{}, Top: []ErrorCount{}}
+	for kind, n := range s.errorKinds {
+		stats.Kinds[kind] = n
+	}
+	for key, n := range s.errors {
+		stats.Top = append(stats.Top, ErrorCount{Kind: key.kind, Message: key.message, Count: n})
+	}
+	sort.Slice(stats.Top, func(i, j int) bool {
+		a, b := stats.Top[i], stats.Top[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
 		}
-		sum += rec.Time
+		return a.Message < b.Message
+	})
+	if len(stats.Top) > numTop {
+		stats.Top = stats.Top[:numTop]
+	}
+	return stats
+}
+
+// StatusStats holds the number of responses of each status code,
+// and of each class of status codes, from "1xx" to "5xx".
+type StatusStats struct {
+	Classes map[string]int `json:"classes"`
+	Codes   map[int]int    `json:"codes"`
+}
+
+// StatusStats returns the number of the Benchmark's responses of each
+// status code and class of status codes. Canceled records are ignored.
+func (bk Benchmark) StatusStats() StatusStats {
+	stats := StatusStats{Classes: map[string]int{}, Codes: bk.StatusCodes()}
+	for code, n := range stats.Codes {
+		stats.Classes[fmt.Sprintf("%dxx", code/100)] += n
+	}
+	return stats
+}
+
+// Protocols returns the number of responses received with each protocol,
+// e.g. "HTTP/2.0". Canceled records are ignored.
+func (bk Benchmark) Protocols() map[string]int {
+	counts := map[string]int{}
+	for protocol, n := range bk.summarize().protocols {
+		counts[protocol] = n
+	}
+	return counts
+}
+
+// StatusCodes returns the number of responses received with each
+// status code. Canceled records are ignored.
+func (bk Benchmark) StatusCodes() map[int]int {
+	counts := map[int]int{}
+	for code, n := range bk.summarize().statuses {
+		counts[code] = n
 	}
-	return min, max, sum / time.Duration(n)
+	return counts
 }
 
-// newReport generates and returns a Benchmark given a Run dataset.
-func newReport(records []Record, numErr int, d time.Duration) Benchmark {
+// newReport generates and returns a Benchmark given a Run dataset:
+// the run summary s and the records kept in memory, if any.
+func newReport(s *summary, records []Record, numDropped, numLate int, d time.Duration) Benchmark {
+	length := int(s.times.n)
 	return Benchmark{
 		Records:  records,
-		Length:   len(records),
-		Success:  len(records) - numErr,
-		Fail:     numErr,
+		Length:   length,
+		Success:  length - s.fail,
+		Fail:     s.fail,
+		Dropped:  numDropped,
+		Late:     numLate,
+		Canceled: s.canceled,
 		Duration: d,
+
+		BucketInterval: s.series.interval,
+
+		summary: s,
+	}
+}
+
+// checkResults returns the results of the checkers of each step
+// of the endpoints over the records summarized in s. Checks sharing
+// the same name, such as the checks of the Requester config run for
+// every step, are merged in a single result.
+func checkResults(endpoints []endpoint, s *summary) []CheckResult {
+	results := []CheckResult{}
+	index := map[string]int{}
+	for _, e := range endpoints {
+		for _, s := range e.steps {
+			for _, c := range s.checkers {
+				if _, ok := index[c.name]; !ok {
+					index[c.name] = len(results)
+					results = append(results, CheckResult{Name: c.name})
+				}
+			}
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	for i, e := range endpoints {
+		for j, step := range e.steps {
+			n := s.checked[stepKey{endpoint: i, step: j}]
+			for _, c := range step.checkers {
+				results[index[c.name]].Pass += n
+			}
+		}
+	}
+	for name, n := range s.failed {
+		if i, ok := index[name]; ok {
+			results[i].Pass -= n
+			results[i].Fail += n
+		}
 	}
+	return results
 }
@@ -0,0 +1,262 @@
+package requester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Check kinds.
+const (
+	// CheckStatus checks the response status code against a comma-separated
+	// list of codes ("200"), classes ("2xx") or ranges ("200-299").
+	CheckStatus = "status"
+	// CheckHeader checks the response has a header Key. If Value is set,
+	// the header value must be equal to it.
+	CheckHeader = "header"
+	// CheckBody checks the response body contains Value.
+	CheckBody = "body"
+	// CheckBodyRegexp checks the response body matches the regular
+	// expression Value.
+	CheckBodyRegexp = "bodyRegexp"
+	// CheckJSON checks the value found at the JSON path Key in the response
+	// body is equal to the JSON-encoded Value. Path segments are separated
+	// by dots, array indexes can be written "items.0" or "items[0]".
+	CheckJSON = "json"
+)
+
+// Check is a condition a response must meet to be considered successful.
+// A response failing a Check is counted as a failure and the Name of
+// the Check is added to its Record.Failed. If Name is empty, it defaults
+// to a representation of Kind, Key and Value.
+type Check struct {
+	Name  string
+	Kind  string
+	Key   string
+	Value string
+}
+
+// CheckResult is the number of responses that passed and failed a Check.
+// Records with a transport error are not counted, as no response was
+// received to be checked.
+type CheckResult struct {
+	Name string `json:"name"`
+	Pass int    `json:"pass"`
+	Fail int    `json:"fail"`
+}
+
+// checker is a compiled Check.
+type checker struct {
+	name     string
+	check    func(resp *http.Response, body []byte, parsed *jsonBody) bool
+	readBody bool // whether check reads the response body
+}
+
+// jsonBody lazily decodes a response body as JSON, so that it is decoded
+// at most once for all checks.
+type jsonBody struct {
+	raw     []byte
+	decoded bool
+	value   interface{}
+	err     error
+}
+
+// get returns the decoded body.
+func (b *jsonBody) get() (interface{}, error) {
+	if !b.decoded {
+		b.err = json.Unmarshal(b.raw, &b.value)
+		b.decoded = true
+	}
+	return b.value, b.err
+}
+
+// compileChecks returns the checkers for the given checks, or the first
+// non-nil error occurring in the process.
+func compileChecks(checks []Check) ([]checker, error) {
+	checkers := make([]checker, 0, len(checks))
+	for _, c := range checks {
+		check, err := compileCheck(c)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCheck, err)
+		}
+
+		name := c.Name
+		if name == "" {
+			name = strings.Join(nonEmpty(c.Kind, c.Key, c.Value), " ")
+		}
+
+		checkers = append(checkers, checker{
+			name:     name,
+			check:    check,
+			readBody: c.Kind == CheckBody || c.Kind == CheckBodyRegexp || c.Kind == CheckJSON,
+		})
+	}
+	return checkers, nil
+}
+
+// compileCheck returns the check function for c according to its kind.
+func compileCheck(c Check) (func(*http.Response, []byte, *jsonBody) bool, error) {
+	switch c.Kind {
+	case CheckStatus:
+		match, err := statusMatcher(c.Value)
+		if err != nil {
+			return nil, err
+		}
+		return func(resp *http.Response, _ []byte, _ *jsonBody) bool {
+			return match(resp.StatusCode)
+		}, nil
+
+	case CheckHeader:
+		key, value := c.Key, c.Value
+		return func(resp *http.Response, _ []byte, _ *jsonBody) bool {
+			values, ok := resp.Header[http.CanonicalHeaderKey(key)]
+			if !ok {
+				return false
+			}
+			if value == "" {
+				return true
+			}
+			for _, v := range values {
+				if v == value {
+					return true
+				}
+			}
+			return false
+		}, nil
+
+	case CheckBody:
+		substr := []byte(c.Value)
+		return func(_ *http.Response, body []byte, _ *jsonBody) bool {
+			return bytes.Contains(body, substr)
+		}, nil
+
+	case CheckBodyRegexp:
+		re, err := regexp.Compile(c.Value)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ *http.Response, body []byte, _ *jsonBody) bool {
+			return re.Match(body)
+		}, nil
+
+	case CheckJSON:
+		var exp interface{}
+		if err := json.Unmarshal([]byte(c.Value), &exp); err != nil {
+			return nil, fmt.Errorf("json %s: %s", c.Key, err)
+		}
+		path := jsonPath(c.Key)
+		return func(_ *http.Response, _ []byte, parsed *jsonBody) bool {
+			v, err := parsed.get()
+			if err != nil {
+				return false
+			}
+			got, ok := lookupJSON(v, path)
+			return ok && reflect.DeepEqual(got, exp)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown check kind %q", c.Kind)
+}
+
+// nonEmpty returns the non-empty strings among values.
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// runChecks runs the checkers against the response and returns the names
+// of the failed checks.
+func runChecks(checkers []checker, resp *http.Response, body []byte, parsed *jsonBody) []string {
+	var failed []string
+	for _, c := range checkers {
+		if !c.check(resp, body, parsed) {
+			failed = append(failed, c.name)
+		}
+	}
+	return failed
+}
+
+// statusMatcher returns a function reporting whether a status code matches
+// spec, a comma-separated list of codes ("200"), classes ("2xx") or ranges
+// ("200-299").
+func statusMatcher(spec string) (func(code int) bool, error) {
+	type codeRange struct{ min, max int }
+
+	ranges := []codeRange{}
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+
+		if strings.HasSuffix(s, "xx") {
+			class, err := strconv.Atoi(strings.TrimSuffix(s, "xx"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid status %q", s)
+			}
+			ranges = append(ranges, codeRange{class * 100, class*100 + 99})
+			continue
+		}
+
+		bounds := strings.SplitN(s, "-", 2)
+		min, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid status %q", s)
+		}
+		max := min
+		if len(bounds) == 2 {
+			if max, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("invalid status %q", s)
+			}
+		}
+		ranges = append(ranges, codeRange{min, max})
+	}
+
+	return func(code int) bool {
+		for _, r := range ranges {
+			if code >= r.min && code <= r.max {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// jsonPath splits a path such as "data.items[0].id" into its segments:
+// ["data", "items", "0", "id"].
+func jsonPath(path string) []string {
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	return strings.Split(strings.Trim(path, "."), ".")
+}
+
+// lookupJSON returns the value found in v at the given path, or false
+// if the path does not exist in v.
+func lookupJSON(v interface{}, path []string) (interface{}, bool) {
+	for _, segment := range path {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,155 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunChecks(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 404,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}
+	body := []byte(`{"data": {"items": [{"id": 42}, {"id": "abc"}]}, "status": "ok"}`)
+
+	testcases := []struct {
+		check   Check
+		expPass bool
+	}{
+		{Check{Kind: CheckStatus, Value: "404"}, true},
+		{Check{Kind: CheckStatus, Value: "200,4xx"}, true},
+		{Check{Kind: CheckStatus, Value: "400-403,500-599"}, false},
+		{Check{Kind: CheckStatus, Value: "2xx"}, false},
+		{Check{Kind: CheckHeader, Key: "content-type"}, true},
+		{Check{Kind: CheckHeader, Key: "Content-Type", Value: "application/json"}, true},
+		{Check{Kind: CheckHeader, Key: "Content-Type", Value: "text/plain"}, false},
+		{Check{Kind: CheckHeader, Key: "X-Missing"}, false},
+		{Check{Kind: CheckBody, Value: `"status": "ok"`}, true},
+		{Check{Kind: CheckBody, Value: "ko"}, false},
+		{Check{Kind: CheckBodyRegexp, Value: `"id": \d+`}, true},
+		{Check{Kind: CheckBodyRegexp, Value: `^\[`}, false},
+		{Check{Kind: CheckJSON, Key: "status", Value: `"ok"`}, true},
+		{Check{Kind: CheckJSON, Key: "data.items[0].id", Value: "42"}, true},
+		{Check{Kind: CheckJSON, Key: "data.items.1.id", Value: `"abc"`}, true},
+		{Check{Kind: CheckJSON, Key: "data.items.1.id", Value: "42"}, false},
+		{Check{Kind: CheckJSON, Key: "data.items.2.id", Value: "42"}, false},
+		{Check{Kind: CheckJSON, Key: "data.missing", Value: "null"}, false},
+	}
+
+	for _, tc := range testcases {
+		checkers, err := compileChecks([]Check{tc.check})
+		if err != nil {
+			t.Fatalf("%+v: unexpected error: %v", tc.check, err)
+		}
+
+		failed := runChecks(checkers, resp, body, &jsonBody{raw: body})
+		if gotPass := len(failed) == 0; gotPass != tc.expPass {
+			t.Errorf("%+v: exp pass %v, got %v", tc.check, tc.expPass, gotPass)
+		}
+	}
+}
+
+func TestCompileChecks(t *testing.T) {
+	t.Run("default name to kind, key and value", func(t *testing.T) {
+		checkers, err := compileChecks([]Check{
+			{Kind: CheckStatus, Value: "2xx"},
+			{Name: "custom", Kind: CheckJSON, Key: "id", Value: "1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{checkers[0].name, checkers[1].name}
+		if exp := []string{"status 2xx", "custom"}; !reflect.DeepEqual(got, exp) {
+			t.Errorf("unexpected names: %q", got)
+		}
+	})
+
+	t.Run("return ErrInvalidCheck for invalid checks", func(t *testing.T) {
+		for _, c := range []Check{
+			{Kind: CheckStatus, Value: "abc"},
+			{Kind: CheckBodyRegexp, Value: "("},
+			{Kind: CheckJSON, Key: "id", Value: "not json"},
+			{Kind: "unknown"},
+		} {
+			if _, err := compileChecks([]Check{c}); !errors.Is(err, ErrInvalidCheck) {
+				t.Errorf("%+v: exp ErrInvalidCheck, got %v", c, err)
+			}
+		}
+	})
+}
+
+func TestRun_checks(t *testing.T) {
+	t.Run("count failed checks as failures", func(t *testing.T) {
+		r := New(Config{
+			Requests:       2,
+			Concurrency:    1,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Checks: []Check{
+				{Name: "ok status", Kind: CheckStatus, Value: "2xx"},
+				{Name: "ok body", Kind: CheckBody, Value: "ok"},
+			},
+			Silent: true,
+		})
+		r.newTransport = func(int, connOptions) http.RoundTripper {
+			return statusTransport{code: 500, body: "ok"}
+		}
+
+		rep, err := r.Run(context.Background(), validRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if rep.Success != 0 || rep.Fail != 2 {
+			t.Errorf("exp 0 success and 2 fails, got %d and %d", rep.Success, rep.Fail)
+		}
+
+		for _, rec := range rep.Records {
+			if !reflect.DeepEqual(rec.Failed, []string{"ok status"}) {
+				t.Errorf("unexpected failed checks: %v", rec.Failed)
+			}
+		}
+
+		expChecks := []CheckResult{
+			{Name: "ok status", Pass: 0, Fail: 2},
+			{Name: "ok body", Pass: 2, Fail: 0},
+		}
+		if !reflect.DeepEqual(rep.Checks, expChecks) {
+			t.Errorf("unexpected check results:\nexp %+v\ngot %+v", expChecks, rep.Checks)
+		}
+	})
+
+	t.Run("return ErrInvalidCheck early", func(t *testing.T) {
+		r := withNoopTransport(New(Config{
+			Requests:       1,
+			Concurrency:    1,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Checks:         []Check{{Kind: "unknown"}},
+		}))
+
+		if _, err := r.Run(context.Background(), validRequest()); !errors.Is(err, ErrInvalidCheck) {
+			t.Errorf("exp ErrInvalidCheck, got %v", err)
+		}
+	})
+}
+
+// helpers
+
+type statusTransport struct {
+	code int
+	body string
+}
+
+func (t statusTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: t.code,
+		Body:       io.NopCloser(strings.NewReader(t.body)),
+	}, nil
+}
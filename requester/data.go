@@ -0,0 +1,101 @@
+package requester
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Data read modes.
+const (
+	// DataSequential reads the rows in order, one per iteration.
+	DataSequential = "sequential"
+	// DataRandom reads a random row for each iteration.
+	DataRandom = "random"
+	// DataPerWorker splits the rows between the workers, so that
+	// concurrent iterations never read the same row. Each worker
+	// reads its own rows in order, which requires at least as many
+	// rows as workers.
+	DataPerWorker = "perWorker"
+)
+
+// Data end policies.
+const (
+	// DataWrap starts over from the first row when the data runs out.
+	DataWrap = "wrap"
+	// DataStop ends the run when the data runs out.
+	DataStop = "stop"
+)
+
+// Data is the test data fed to the requests. The columns of the row read
+// for an iteration are available in its requests using placeholders
+// "${data.<column>}".
+type Data struct {
+	Rows  []map[string]string
+	Mode  string
+	OnEnd string
+}
+
+// validate returns a non-nil error wrapping ErrInvalidData if the rows
+// cannot be fed to numWorker concurrent workers, that is if they are
+// split between more workers than rows in mode DataPerWorker.
+func (d Data) validate(numWorker int) error {
+	if d.Mode == DataPerWorker && len(d.Rows) > 0 && len(d.Rows) < numWorker {
+		return fmt.Errorf(
+			"%w: mode perWorker needs at least one row per worker, got %d rows for %d workers",
+			ErrInvalidData, len(d.Rows), numWorker,
+		)
+	}
+	return nil
+}
+
+// feeder reads the rows of Data for each iteration according to its mode.
+type feeder struct {
+	data      Data
+	numWorker int
+
+	mu      sync.Mutex
+	cursors map[int]int // next read of each worker in mode DataPerWorker
+}
+
+// newFeeder returns a feeder for data, numWorker being the maximum
+// number of concurrent workers.
+func newFeeder(data Data, numWorker int) *feeder {
+	if numWorker < 1 {
+		numWorker = 1
+	}
+	return &feeder{data: data, numWorker: numWorker, cursors: map[int]int{}}
+}
+
+// next returns the row for the given iteration, or false if the data
+// ran out and the run must stop. It returns a nil row if there is no data.
+func (f *feeder) next(iteration, worker int, rnd *rng) (map[string]string, bool) {
+	rows := f.data.Rows
+	n := len(rows)
+	if n == 0 {
+		return nil, true
+	}
+
+	switch f.data.Mode {
+	case DataRandom:
+		return rows[rnd.intn(n)], true
+
+	case DataPerWorker:
+		f.mu.Lock()
+		read := f.cursors[worker]
+		f.cursors[worker]++
+		f.mu.Unlock()
+
+		// worker w reads rows w, w+numWorker, w+2*numWorker...
+		first := worker % n
+		numRow := (n - first + f.numWorker - 1) / f.numWorker
+		if (worker >= n || read >= numRow) && f.data.OnEnd == DataStop {
+			return nil, false
+		}
+		return rows[first+(read%numRow)*f.numWorker], true
+	}
+
+	if iteration >= n && f.data.OnEnd == DataStop {
+		return nil, false
+	}
+	return rows[iteration%n], true
+}
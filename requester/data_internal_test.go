@@ -0,0 +1,149 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFeeder(t *testing.T) {
+	rows := []map[string]string{{"id": "0"}, {"id": "1"}, {"id": "2"}, {"id": "3"}, {"id": "4"}}
+
+	// ids returns the ids of the rows read for each call, "stop" if the
+	// data ran out.
+	ids := func(f *feeder, calls [][2]int) []string {
+		got := []string{}
+		for _, c := range calls {
+			row, ok := f.next(c[0], c[1], newRNG(1, c[0]))
+			if !ok {
+				got = append(got, "stop")
+				continue
+			}
+			got = append(got, row["id"])
+		}
+		return got
+	}
+
+	testcases := []struct {
+		label string
+		data  Data
+		calls [][2]int // iteration, worker
+		exp   []string
+	}{
+		{
+			label: "read rows in order and wrap",
+			data:  Data{Rows: rows, Mode: DataSequential, OnEnd: DataWrap},
+			calls: [][2]int{{0, 0}, {1, 1}, {4, 0}, {5, 1}, {11, 0}},
+			exp:   []string{"0", "1", "4", "0", "1"},
+		},
+		{
+			label: "read rows in order and stop",
+			data:  Data{Rows: rows, Mode: DataSequential, OnEnd: DataStop},
+			calls: [][2]int{{3, 0}, {4, 0}, {5, 0}},
+			exp:   []string{"3", "4", "stop"},
+		},
+		{
+			label: "split rows between workers and wrap",
+			data:  Data{Rows: rows, Mode: DataPerWorker, OnEnd: DataWrap},
+			calls: [][2]int{{0, 0}, {1, 1}, {2, 0}, {3, 1}, {4, 0}, {5, 1}, {6, 0}},
+			exp:   []string{"0", "1", "2", "3", "4", "1", "0"},
+		},
+		{
+			label: "split rows between workers and stop",
+			data:  Data{Rows: rows, Mode: DataPerWorker, OnEnd: DataStop},
+			calls: [][2]int{{0, 1}, {1, 1}, {2, 1}, {3, 0}},
+			exp:   []string{"1", "3", "stop", "0"},
+		},
+		{
+			label: "never stop in random mode",
+			data:  Data{Rows: rows, Mode: DataRandom, OnEnd: DataStop},
+			calls: [][2]int{{0, 0}, {10, 0}, {100, 0}},
+			exp:   nil, // checked below
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := ids(newFeeder(tc.data, 2), tc.calls)
+			if tc.exp == nil {
+				for _, id := range got {
+					if id == "stop" {
+						t.Errorf("exp no stop, got %v", got)
+					}
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("exp %v, got %v", tc.exp, got)
+			}
+		})
+	}
+
+	t.Run("return nil rows without data", func(t *testing.T) {
+		row, ok := newFeeder(Data{}, 1).next(0, 0, newRNG(1, 0))
+		if row != nil || !ok {
+			t.Errorf("exp nil row and true, got %v and %v", row, ok)
+		}
+	})
+}
+
+func TestRun_data(t *testing.T) {
+	t.Run("stop the run when the data runs out", func(t *testing.T) {
+		transport := &routeTransport{}
+		r := New(Config{
+			Requests:       -1,
+			Concurrency:    1,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Data: Data{
+				Rows:  []map[string]string{{"id": "a"}, {"id": "b"}},
+				Mode:  DataSequential,
+				OnEnd: DataStop,
+			},
+			Silent: true,
+		})
+		r.newTransport = func(int, connOptions) http.RoundTripper { return transport }
+
+		req, _ := http.NewRequest("GET", "http://a.b/users/${data.id}", nil)
+
+		start := time.Now()
+		bk, err := r.Run(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("exp early stop, run lasted %v", elapsed)
+		}
+
+		if bk.Length != 2 {
+			t.Errorf("exp 2 records, got %d", bk.Length)
+		}
+
+		// first request is the ping
+		if exp := []string{"/users/a", "/users/b"}; !reflect.DeepEqual(transport.requests[1:], exp) {
+			t.Errorf("unexpected requests:\nexp %q\ngot %q", exp, transport.requests[1:])
+		}
+	})
+	t.Run("return ErrInvalidData with fewer rows than workers in perWorker mode", func(t *testing.T) {
+		r := New(Config{
+			Requests:       -1,
+			Concurrency:    3,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Data: Data{
+				Rows:  []map[string]string{{"id": "a"}, {"id": "b"}},
+				Mode:  DataPerWorker,
+				OnEnd: DataWrap,
+			},
+			Silent: true,
+		})
+
+		_, err := r.Run(context.Background(), validRequest())
+		if !errors.Is(err, ErrInvalidData) {
+			t.Errorf("exp ErrInvalidData, got %v", err)
+		}
+	})
+}
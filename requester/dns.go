@@ -0,0 +1,144 @@
+package requester
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// DNS modes.
+const (
+	// DNSPerConnection resolves the host names for every new connection.
+	DNSPerConnection = "perConnection"
+	// DNSOnce resolves each host name once per run, the following
+	// connections reusing the resolved addresses.
+	DNSOnce = "once"
+)
+
+// DNS holds the options of the resolution of the host names of the
+// servers, which apply to the connections opened by the Requester:
+// through a proxy, the host names are resolved by the proxy.
+// Resolve maps addresses "host:port" to the IP address connected to
+// instead of resolving host, the Host header and the TLS server name
+// being kept.
+// Server is the address "ip:port" of the DNS server queried instead of
+// the system resolver, empty meaning the system resolver.
+// Mode is DNSPerConnection or DNSOnce, an empty Mode being read as
+// DNSPerConnection.
+type DNS struct {
+	Resolve map[string]string
+	Server  string
+	Mode    string
+}
+
+// resolver resolves the host names of the servers according to
+// its DNS options.
+type resolver struct {
+	options      DNS
+	lookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+	mu    sync.Mutex
+	cache map[string]*dnsEntry // lookup of each host, in mode DNSOnce
+}
+
+// dnsEntry is the lookup of a host in mode DNSOnce. Its addresses
+// and error are set once done is closed.
+type dnsEntry struct {
+	done chan struct{}
+	ips  []net.IPAddr
+	err  error
+}
+
+// newResolver returns a resolver for options.
+func newResolver(options DNS) *resolver {
+	netResolver := net.DefaultResolver
+	if server := options.Server; server != "" {
+		netResolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return &resolver{
+		options:      options,
+		lookupIPAddr: netResolver.LookupIPAddr,
+		cache:        map[string]*dnsEntry{},
+	}
+}
+
+// dialer returns a dialFunc connecting with dial to the addresses
+// resolved by r.
+func (r *resolver) dialer(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+		if ip, ok := r.options.Resolve[addr]; ok {
+			return dial(ctx, network, net.JoinHostPort(ip, port))
+		}
+		if net.ParseIP(host) != nil || (r.options.Mode != DNSOnce && r.options.Server == "") {
+			// let the dialer resolve host with the system resolver
+			return dial(ctx, network, addr)
+		}
+
+		ips, err := r.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var firstErr error
+		for _, ip := range ips {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return nil, firstErr
+	}
+}
+
+// lookup returns the IP addresses of host, from the cache in mode DNSOnce
+// if it was already resolved. Concurrent connections to a host wait for
+// its first lookup, while the other hosts are resolved meanwhile. Failed
+// lookups are not cached. The lookups are traced as DNS events by the
+// client trace of ctx, if any.
+func (r *resolver) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if r.options.Mode != DNSOnce {
+		return r.lookupIPAddr(ctx, host)
+	}
+
+	for {
+		r.mu.Lock()
+		entry, ok := r.cache[host]
+		if !ok {
+			entry = &dnsEntry{done: make(chan struct{})}
+			r.cache[host] = entry
+		}
+		r.mu.Unlock()
+
+		if !ok {
+			entry.ips, entry.err = r.lookupIPAddr(ctx, host)
+			if entry.err != nil {
+				r.mu.Lock()
+				delete(r.cache, host)
+				r.mu.Unlock()
+			}
+			close(entry.done)
+			return entry.ips, entry.err
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.err == nil {
+			return entry.ips, nil
+		}
+		// the lookup failed, possibly for its own context: try again
+	}
+}
@@ -0,0 +1,172 @@
+package requester
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestResolver(t *testing.T) {
+	var gotHost atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost.Store(r.Host)
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	addr := "api.test:" + port
+
+	dnsServer, queries := newDNSServer(t, net.IPv4(127, 0, 0, 1))
+	defer dnsServer.Close()
+
+	testcases := []struct {
+		label      string
+		dns        DNS
+		expQueries int64
+	}{
+		{
+			label:      "connect to overridden addresses",
+			dns:        DNS{Resolve: map[string]string{addr: "127.0.0.1"}, Server: dnsServer.LocalAddr().String()},
+			expQueries: 0,
+		},
+		{
+			label:      "resolve for every connection",
+			dns:        DNS{Server: dnsServer.LocalAddr().String(), Mode: DNSPerConnection},
+			expQueries: 3,
+		},
+		{
+			label:      "resolve once per run",
+			dns:        DNS{Server: dnsServer.LocalAddr().String(), Mode: DNSOnce},
+			expQueries: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		atomic.StoreInt64(queries, 0)
+		tr := newTransports(Connections{DisableKeepAlive: true, Pool: ConnPoolShared}, 1)
+
+		for i := 0; i < 3; i++ {
+			tracer := newTracer(tr.get(0, connOptions{dns: &tc.dns}))
+			resp, err := newClient(tracer, 0).Get("http://" + addr)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.label, err)
+			}
+			readClose(resp) //nolint:errcheck
+			tracer.addEventBodyRead()
+
+			if got := gotHost.Load(); got != addr {
+				t.Errorf("%s: exp Host %q, got %q", tc.label, addr, got)
+			}
+			// the DNS phase only occurs for actual lookups
+			_, timing := tracer.results()
+			if expDNS := tc.expQueries > int64(i); (timing.DNS > 0) != expDNS {
+				t.Errorf("%s: request %d: exp DNS phase %v, got %v", tc.label, i, expDNS, timing.DNS)
+			}
+		}
+
+		if got := atomic.LoadInt64(queries); got != tc.expQueries {
+			t.Errorf("%s: exp %d lookups, got %d", tc.label, tc.expQueries, got)
+		}
+	}
+}
+
+func TestResolver_lookup(t *testing.T) {
+	t.Run("resolve other hosts during a lookup in mode once", func(t *testing.T) {
+		var (
+			numLookup int64
+			started   = make(chan struct{})
+			release   = make(chan struct{})
+		)
+		r := newResolver(DNS{Mode: DNSOnce})
+		r.lookupIPAddr = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+			if host == "slow.test" && atomic.AddInt64(&numLookup, 1) == 1 {
+				close(started)
+				<-release
+			}
+			return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, nil
+		}
+
+		slowDone := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := r.lookup(context.Background(), "slow.test")
+				slowDone <- err
+			}()
+		}
+		<-started
+
+		fastDone := make(chan error, 1)
+		go func() {
+			_, err := r.lookup(context.Background(), "fast.test")
+			fastDone <- err
+		}()
+		select {
+		case err := <-fastDone:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Error("exp fast.test resolved during the slow lookup")
+		}
+
+		close(release)
+		for i := 0; i < 2; i++ {
+			if err := <-slowDone; err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := atomic.LoadInt64(&numLookup); got != 1 {
+			t.Errorf("exp 1 lookup of slow.test, got %d", got)
+		}
+	})
+}
+
+// newDNSServer returns a started DNS server answering ip to all A
+// questions, and its number of A questions.
+func newDNSServer(t *testing.T, ip net.IP) (net.PacketConn, *int64) {
+	var queries int64
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+				continue
+			}
+
+			q := query.Questions[0]
+			answer := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+			}
+			if q.Type == dnsmessage.TypeA {
+				atomic.AddInt64(&queries, 1)
+				var a dnsmessage.AResource
+				copy(a.A[:], ip.To4())
+				answer.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 60},
+					Body:   &a,
+				}}
+			}
+			b, err := answer.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(b, from) //nolint:errcheck
+		}
+	}()
+	return conn, &queries
+}
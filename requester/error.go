@@ -1,8 +1,15 @@
 package requester
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"syscall"
 )
 
 var (
@@ -11,10 +18,110 @@ var (
 	ErrConnection = errors.New("connection error")
 	// ErrCanceled is returned when the Requester.Run context is canceled.
 	ErrCanceled = errors.New("canceled")
+	// ErrInvalidCheck is returned when a Check of the Requester config
+	// cannot be compiled.
+	ErrInvalidCheck = errors.New("invalid check")
+	// ErrInvalidExtract is returned when an Extract of a scenario Step
+	// cannot be compiled.
+	ErrInvalidExtract = errors.New("invalid extract")
+	// ErrRecords is returned when the records of the run cannot be stored.
+	ErrRecords = errors.New("records storage error")
+	// ErrInvalidTemplate is returned when a placeholder of a request
+	// cannot be parsed.
+	ErrInvalidTemplate = errors.New("invalid template")
+	// ErrInvalidData is returned when the Data of the Requester config
+	// cannot be fed to its workers.
+	ErrInvalidData = errors.New("invalid data")
 )
 
+// recordErrPrefix is the prefix of the errors of the records.
+const recordErrPrefix = "recording error: "
+
 // recordErr wraps and returns err as a string, marking it as an error
 // that happened when recording the request.
 func recordErr(err error) string {
-	return fmt.Sprintf("recording error: %s", err)
+	return fmt.Sprintf("%s%s", recordErrPrefix, err)
+}
+
+// Error kinds, the classes of the errors of the records.
+const (
+	// ErrorKindDNS is the kind of the failures to resolve a host name.
+	ErrorKindDNS = "dns"
+	// ErrorKindRefused is the kind of the connections refused
+	// by the server.
+	ErrorKindRefused = "connectionRefused"
+	// ErrorKindReset is the kind of the connections reset or closed
+	// by the server while in use.
+	ErrorKindReset = "connectionReset"
+	// ErrorKindTLS is the kind of the failures of TLS handshakes,
+	// such as an invalid server certificate.
+	ErrorKindTLS = "tls"
+	// ErrorKindTimeout is the kind of the requests that exceeded
+	// the request timeout.
+	ErrorKindTimeout = "timeout"
+	// ErrorKindBodyRead is the kind of the failures to read
+	// a response body.
+	ErrorKindBodyRead = "bodyRead"
+	// ErrorKindCanceled is the kind of the requests cut short
+	// by the end of the run.
+	ErrorKindCanceled = "canceled"
+	// ErrorKindOther is the kind of the errors of no other kind.
+	ErrorKindOther = "other"
+)
+
+// errorKind returns the kind of err, or fallback if it is of none
+// of the kinds determined from the underlying error.
+func errorKind(err error, fallback string) string {
+	var (
+		dnsErr         *net.DNSError
+		headerErr      tls.RecordHeaderError
+		authorityErr   x509.UnknownAuthorityError
+		certificateErr x509.CertificateInvalidError
+		hostnameErr    x509.HostnameError
+		rootsErr       x509.SystemRootsError
+		netErr         net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return ErrorKindDNS
+	case errors.As(err, &headerErr), errors.As(err, &authorityErr),
+		errors.As(err, &certificateErr), errors.As(err, &hostnameErr),
+		errors.As(err, &rootsErr), isTLSAlert(err):
+		return ErrorKindTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorKindRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return ErrorKindReset
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorKindTimeout
+	}
+	return fallback
+}
+
+// isTLSAlert returns true if err is a TLS alert sent or received during
+// a handshake, such as a handshake failure or a rejected certificate.
+func isTLSAlert(err error) bool {
+	// crypto/tls returns the alerts as the Err of a *net.OpError,
+	// which Op tells whether the alert was sent or received
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "local error" || opErr.Op == "remote error")
+}
+
+var (
+	// urlErrPrefix matches the prefix added to an error by *url.Error,
+	// the method and URL of the request, e.g. `Get "http://a.b/?id=1": `.
+	urlErrPrefix = regexp.MustCompile(`^[A-Za-z]+ "[^"]*": `)
+	// localAddr matches the local address of a connection in the
+	// message of a *net.OpError, e.g. "127.0.0.1:51234->".
+	localAddr = regexp.MustCompile(`\S+->`)
+)
+
+// errorMessage returns the message of the error of a record, without
+// its parts that depend on the request, such as its URL or the local
+// port of its connection, so that the same errors have the same message.
+func errorMessage(recErr string) string {
+	msg := strings.TrimPrefix(recErr, recordErrPrefix)
+	msg = urlErrPrefix.ReplaceAllString(msg, "")
+	return localAddr.ReplaceAllString(msg, "")
 }
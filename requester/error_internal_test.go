@@ -0,0 +1,152 @@
+package requester
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestErrorKind(t *testing.T) {
+	closedAddr := func() string {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ln.Close()
+		return ln.Addr().String()
+	}
+
+	t.Run("classify errors of requests", func(t *testing.T) {
+		tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer tlsSrv.Close()
+		slowSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+		}))
+		defer slowSrv.Close()
+
+		testcases := []struct {
+			label   string
+			url     string
+			timeout time.Duration
+			exp     string
+		}{
+			{label: "connection refused", url: "http://" + closedAddr(), exp: ErrorKindRefused},
+			{label: "tls failure", url: tlsSrv.URL, exp: ErrorKindTLS},
+			{label: "request timeout", url: slowSrv.URL, timeout: 10 * time.Millisecond, exp: ErrorKindTimeout},
+		}
+		for _, tc := range testcases {
+			client := &http.Client{Timeout: tc.timeout}
+			_, err := client.Get(tc.url)
+			if err == nil {
+				t.Fatalf("%s: exp error, got nil", tc.label)
+			}
+			if got := errorKind(err, ErrorKindOther); got != tc.exp {
+				t.Errorf("%s: exp kind %q, got %q for %v", tc.label, tc.exp, got, err)
+			}
+		}
+	})
+
+	t.Run("classify wrapped errors", func(t *testing.T) {
+		testcases := []struct {
+			label string
+			err   error
+			exp   string
+		}{
+			{
+				label: "dns failure",
+				err:   &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "a.b"}},
+				exp:   ErrorKindDNS,
+			},
+			{
+				label: "connection reset",
+				err:   &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+				exp:   ErrorKindReset,
+			},
+			{
+				label: "tls alert",
+				err:   fmt.Errorf("proxy: %w", &net.OpError{Op: "remote error", Err: errors.New("tls: handshake failure")}),
+				exp:   ErrorKindTLS,
+			},
+			{
+				label: "connection refused mentioning tls",
+				err:   fmt.Errorf("tls: x509: %w", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}),
+				exp:   ErrorKindRefused,
+			},
+			{
+				label: "fallback",
+				err:   errors.New("unexpected EOF"),
+				exp:   ErrorKindBodyRead,
+			},
+		}
+		for _, tc := range testcases {
+			if got := errorKind(tc.err, ErrorKindBodyRead); got != tc.exp {
+				t.Errorf("%s: exp kind %q, got %q", tc.label, tc.exp, got)
+			}
+		}
+	})
+}
+
+func TestErrorMessage(t *testing.T) {
+	testcases := []struct {
+		in, exp string
+	}{
+		{
+			in:  `recording error: Get "http://a.b/?id=1": dial tcp 10.0.0.1:80: connect: connection refused`,
+			exp: `dial tcp 10.0.0.1:80: connect: connection refused`,
+		},
+		{
+			in:  `recording error: read tcp 127.0.0.1:51234->10.0.0.1:80: read: connection reset by peer`,
+			exp: `read tcp 10.0.0.1:80: read: connection reset by peer`,
+		},
+	}
+	for _, tc := range testcases {
+		if got := errorMessage(tc.in); got != tc.exp {
+			t.Errorf("exp %q, got %q", tc.exp, got)
+		}
+	}
+}
+
+func TestBenchmark_ErrorsStats(t *testing.T) {
+	refused := func(port string) Record {
+		return Record{
+			Error:     recordErr(errors.New(`Get "http://a.b:` + port + `": dial tcp 10.0.0.1:80: connect: connection refused`)),
+			ErrorKind: ErrorKindRefused,
+		}
+	}
+	bk := Benchmark{Records: []Record{
+		refused("1"),
+		refused("2"),
+		{Error: recordErr(errors.New("timeout")), ErrorKind: ErrorKindTimeout},
+		{Canceled: true, ErrorKind: ErrorKindCanceled},
+		{Code: 200},
+	}}
+
+	stats := bk.ErrorsStats(1)
+	expKinds := map[string]int{ErrorKindRefused: 2, ErrorKindTimeout: 1, ErrorKindCanceled: 1}
+	if !reflect.DeepEqual(stats.Kinds, expKinds) {
+		t.Errorf("unexpected error kinds:\nexp %v\ngot %v", expKinds, stats.Kinds)
+	}
+	expTop := []ErrorCount{{Kind: ErrorKindRefused, Message: "dial tcp 10.0.0.1:80: connect: connection refused", Count: 2}}
+	if !reflect.DeepEqual(stats.Top, expTop) {
+		t.Errorf("unexpected top errors:\nexp %+v\ngot %+v", expTop, stats.Top)
+	}
+}
+
+func TestBenchmark_StatusStats(t *testing.T) {
+	bk := Benchmark{Records: []Record{{Code: 200}, {Code: 201}, {Code: 503}, {Error: "recording error: EOF"}, {Canceled: true, Code: 200}}}
+
+	exp := StatusStats{
+		Classes: map[string]int{"2xx": 2, "5xx": 1},
+		Codes:   map[int]int{200: 1, 201: 1, 503: 1},
+	}
+	if got := bk.StatusStats(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected status stats:\nexp %+v\ngot %+v", exp, got)
+	}
+}
@@ -0,0 +1,210 @@
+package requester
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// subBucketBits is the log2 of the number of sub-buckets in each power
+// of two range of a histogram. It determines the precision of recorded
+// values: 7 bits keep a relative error under 1%.
+const subBucketBits = 7
+
+// histogram is a log-linear (HDR-style) histogram of durations.
+// Values lower than 2^(subBucketBits+1) nanoseconds are recorded exactly,
+// higher values are grouped into buckets of a width proportional to their
+// magnitude, so the memory used only depends on the order of magnitude
+// of the highest value recorded, not on the number of values.
+//
+// For each bucket, it keeps the count and the sum of the values recorded,
+// so that the value returned for a bucket is the exact mean of its values.
+// Min, max, sum and sum of squares are computed exactly.
+type histogram struct {
+	counts []int64
+	sums   []float64
+
+	// subBits is the log2 of the number of sub-buckets in each power of two
+	// range, subBucketBits if 0. Merged histograms must have the same.
+	subBits int
+
+	n          int64
+	min, max   time.Duration
+	sum, sumSq float64
+}
+
+// record adds d to the histogram. Negative values are recorded as 0.
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	i := h.index(d)
+	if i >= len(h.counts) {
+		h.grow(i + 1)
+	}
+	h.counts[i]++
+	h.sums[i] += float64(d)
+
+	if d < h.min || h.n == 0 {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.n++
+	h.sum += float64(d)
+	h.sumSq += float64(d) * float64(d)
+}
+
+// merge adds the values recorded in other to h.
+func (h *histogram) merge(other *histogram) {
+	if other.n == 0 {
+		return
+	}
+	if len(other.counts) > len(h.counts) {
+		h.grow(len(other.counts))
+	}
+	for i, count := range other.counts {
+		h.counts[i] += count
+		h.sums[i] += other.sums[i]
+	}
+
+	if other.min < h.min || h.n == 0 {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	h.n += other.n
+	h.sum += other.sum
+	h.sumSq += other.sumSq
+}
+
+// grow extends the buckets of h to length n.
+func (h *histogram) grow(n int) {
+	counts := make([]int64, n)
+	copy(counts, h.counts)
+	h.counts = counts
+
+	sums := make([]float64, n)
+	copy(sums, h.sums)
+	h.sums = sums
+}
+
+// mean returns the mean of the recorded values.
+func (h *histogram) mean() time.Duration {
+	if h.n == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / float64(h.n))
+}
+
+// stdDev returns the population standard deviation of the recorded values.
+func (h *histogram) stdDev() time.Duration {
+	if h.n == 0 {
+		return 0
+	}
+	mean := h.sum / float64(h.n)
+	variance := h.sumSq/float64(h.n) - mean*mean
+	if variance < 0 { // floating point imprecision
+		return 0
+	}
+	return time.Duration(math.Sqrt(variance))
+}
+
+// percentile returns the value under which p percent of the recorded
+// values fall, with p in range [0, 100].
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.n == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(h.n)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var cumul int64
+	for i, count := range h.counts {
+		cumul += count
+		if cumul >= rank {
+			return h.bucketValue(i)
+		}
+	}
+	return h.max
+}
+
+// bucketValue returns the mean of the values recorded in bucket i,
+// clamped to the exact min and max.
+func (h *histogram) bucketValue(i int) time.Duration {
+	v := time.Duration(h.sums[i] / float64(h.counts[i]))
+	switch {
+	case v < h.min:
+		return h.min
+	case v > h.max:
+		return h.max
+	}
+	return v
+}
+
+// distribution returns the recorded values as n buckets of equal width
+// from min to max. It returns a single bucket if all values are equal,
+// and nil if no value was recorded.
+func (h *histogram) distribution(n int) []HistogramBucket {
+	if h.n == 0 || n < 1 {
+		return nil
+	}
+
+	if h.min == h.max {
+		return []HistogramBucket{{Min: h.min, Max: h.max, Count: int(h.n)}}
+	}
+
+	width := (h.max - h.min) / time.Duration(n)
+	if width == 0 {
+		width = 1
+	}
+
+	dist := make([]HistogramBucket, n)
+	for i := range dist {
+		dist[i].Min = h.min + time.Duration(i)*width
+		dist[i].Max = dist[i].Min + width
+	}
+	dist[n-1].Max = h.max
+
+	for i, count := range h.counts {
+		if count == 0 {
+			continue
+		}
+		j := int((h.bucketValue(i) - h.min) / width)
+		if j >= n {
+			j = n - 1
+		}
+		dist[j].Count += int(count)
+	}
+	return dist
+}
+
+// index returns the index of the bucket of h d belongs to.
+func (h *histogram) index(d time.Duration) int {
+	if h.subBits == 0 {
+		return bucketIndex(d)
+	}
+	return bucketIndexBits(d, h.subBits)
+}
+
+// bucketIndex returns the index of the bucket d belongs to.
+func bucketIndex(d time.Duration) int {
+	return bucketIndexBits(d, subBucketBits)
+}
+
+// bucketIndexBits returns the index of the bucket d belongs to
+// with 2^subBits sub-buckets in each power of two range.
+func bucketIndexBits(d time.Duration, subBits int) int {
+	v := uint64(d)
+	shift := bits.Len64(v) - (subBits + 1)
+	if shift <= 0 {
+		return int(v)
+	}
+	return shift<<subBits + int(v>>shift)
+}
@@ -0,0 +1,127 @@
+package requester
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBucketIndex(t *testing.T) {
+	t.Run("map values to contiguous increasing buckets", func(t *testing.T) {
+		prev := bucketIndex(0)
+		for v := time.Duration(1); v < 1<<20; v++ {
+			i := bucketIndex(v)
+			if i != prev && i != prev+1 {
+				t.Fatalf("bucketIndex(%d): exp %d or %d, got %d", v, prev, prev+1, i)
+			}
+			prev = i
+		}
+	})
+
+	t.Run("record low values exactly", func(t *testing.T) {
+		for v := time.Duration(0); v < 1<<(subBucketBits+1); v++ {
+			if i := bucketIndex(v); i != int(v) {
+				t.Fatalf("bucketIndex(%d): exp %d, got %d", v, v, i)
+			}
+		}
+	})
+}
+
+func TestHistogram(t *testing.T) {
+	t.Run("return zero values if empty", func(t *testing.T) {
+		var h histogram
+		if got := newStats(&h); got.Max != 0 || got.P99 != 0 || got.Histogram != nil {
+			t.Errorf("exp zero stats, got %+v", got)
+		}
+	})
+
+	t.Run("compute exact stats", func(t *testing.T) {
+		var h histogram
+		for _, d := range []time.Duration{2, 4, 4, 4, 5, 5, 7, 9} {
+			h.record(d * time.Millisecond)
+		}
+
+		stats := newStats(&h)
+		exp := []struct {
+			name     string
+			got, exp time.Duration
+		}{
+			{"min", stats.Min, 2 * time.Millisecond},
+			{"max", stats.Max, 9 * time.Millisecond},
+			{"mean", stats.Mean, 5 * time.Millisecond},
+			{"stdDev", stats.StdDev, 2 * time.Millisecond},
+			{"p50", stats.P50, 4 * time.Millisecond},
+			{"p75", stats.P75, 5 * time.Millisecond},
+			{"p99", stats.P99, 9 * time.Millisecond},
+		}
+		for _, e := range exp {
+			if e.got != e.exp {
+				t.Errorf("%s: exp %v, got %v", e.name, e.exp, e.got)
+			}
+		}
+	})
+
+	t.Run("compute percentiles with a relative error under 1%", func(t *testing.T) {
+		var h histogram
+		for i := 1; i <= 100000; i++ {
+			h.record(time.Duration(i) * time.Microsecond)
+		}
+
+		for _, p := range []float64{50, 75, 90, 95, 99, 99.9} {
+			got := h.percentile(p)
+			exp := time.Duration(p*1000) * time.Microsecond
+			if diff := (got - exp).Seconds() / exp.Seconds(); diff > 0.01 || diff < -0.01 {
+				t.Errorf("p%v: exp %v ±1%%, got %v", p, exp, got)
+			}
+		}
+	})
+
+	t.Run("distribute values in buckets of equal width", func(t *testing.T) {
+		var h histogram
+		for i := 0; i < 100; i++ {
+			h.record(time.Duration(i) * time.Millisecond)
+		}
+
+		dist := h.distribution(10)
+		if len(dist) != 10 {
+			t.Fatalf("exp 10 buckets, got %d", len(dist))
+		}
+		for i, b := range dist {
+			if b.Count != 10 {
+				t.Errorf("bucket %d: exp count 10, got %d", i, b.Count)
+			}
+		}
+		if dist[0].Min != 0 || dist[9].Max != 99*time.Millisecond {
+			t.Errorf("unexpected bounds: %v, %v", dist[0].Min, dist[9].Max)
+		}
+	})
+
+	t.Run("distribute equal values in a single bucket", func(t *testing.T) {
+		var h histogram
+		h.record(time.Second)
+		h.record(time.Second)
+
+		dist := h.distribution(10)
+		if len(dist) != 1 || dist[0].Count != 2 {
+			t.Errorf("exp a single bucket of 2 values, got %+v", dist)
+		}
+	})
+
+	t.Run("merge histograms as if recorded in one", func(t *testing.T) {
+		var a, b, exp histogram
+		for i := 1; i <= 1000; i++ {
+			d := time.Duration(i) * time.Microsecond
+			if i%3 == 0 {
+				a.record(d)
+			} else {
+				b.record(d)
+			}
+			exp.record(d)
+		}
+
+		a.merge(&b)
+		if got, want := newStats(&a), newStats(&exp); !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected merged stats:\nexp %+v\ngot %+v", want, got)
+		}
+	})
+}
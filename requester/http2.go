@@ -0,0 +1,277 @@
+package requester
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+
+	"golang.org/x/net/http2"
+)
+
+// dialFunc connects to addr on the named network.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// h2Transport is an HTTP/2 http.RoundTripper managing its own connections,
+// so that the number of concurrent streams of a connection can be limited
+// below the limit set by the server.
+type h2Transport struct {
+	*http2.Transport
+	pool *h2Pool
+}
+
+// newH2Transport returns an h2Transport configured with the options
+// of conns and opts, connecting with dial. If opts.protocol is ProtocolH2C,
+// it sends the requests over TCP with prior knowledge, else over TLS.
+func newH2Transport(conns Connections, opts connOptions, dial dialFunc) *h2Transport {
+	cleartext := opts.protocol == ProtocolH2C
+	pool := &h2Pool{
+		options:   conns,
+		cleartext: cleartext,
+		tls:       opts.tls,
+		dial:      dial,
+		conns:     map[string][]*http2.ClientConn{},
+		dialing:   map[string]*h2Dials{},
+	}
+	tr := &http2.Transport{ConnPool: pool, AllowHTTP: cleartext}
+	pool.transport = tr
+	return &h2Transport{Transport: tr, pool: pool}
+}
+
+// CloseIdleConnections closes the connections with no active streams.
+func (t *h2Transport) CloseIdleConnections() {
+	t.pool.closeIdle()
+}
+
+// h2Pool is an http2.ClientConnPool opening a new connection when all
+// connections to an address reached the maximum number of streams.
+type h2Pool struct {
+	transport *http2.Transport
+	options   Connections
+	cleartext bool
+	tls       *tls.Config
+	dial      dialFunc
+
+	mu      sync.Mutex
+	conns   map[string][]*http2.ClientConn // open connections of each address
+	dialing map[string]*h2Dials            // connections being dialed to each address
+}
+
+// h2Dials are the connections being dialed to an address.
+type h2Dials struct {
+	n    int
+	done chan struct{} // closed once one of them is dialed or failed
+}
+
+// GetClientConn implements http2.ClientConnPool. It returns a connection
+// to addr that can take a new stream, opening one if none can. Once
+// Connections.MaxPerHost connections are open or being dialed, it returns
+// the least busy one instead, even if it reached Connections.MaxStreams.
+// Connections are dialed without holding the lock of the pool. Unless
+// keep-alive is disabled, a connection to an address is dialed one at
+// a time, so that concurrent requests share a new connection rather
+// than opening one each.
+func (p *h2Pool) GetClientConn(req *http.Request, addr string) (*http2.ClientConn, error) {
+	if trace := httptrace.ContextClientTrace(req.Context()); trace != nil && trace.GetConn != nil {
+		trace.GetConn(addr)
+	}
+
+	ctx := req.Context()
+	for {
+		p.mu.Lock()
+		cc, dials, ok := p.reserveConn(addr)
+		if ok {
+			p.mu.Unlock()
+			return cc, nil
+		}
+
+		if dials != nil {
+			// wait for a connection being dialed, then look again
+			done := dials.done
+			p.mu.Unlock()
+			select {
+			case <-done:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		dials = p.dialing[addr]
+		if dials == nil {
+			dials = &h2Dials{done: make(chan struct{})}
+			p.dialing[addr] = dials
+		}
+		dials.n++
+		p.mu.Unlock()
+
+		cc, err := p.dialConn(ctx, addr)
+
+		p.mu.Lock()
+		dials.n--
+		close(dials.done)
+		if dials.n == 0 {
+			delete(p.dialing, addr)
+		} else {
+			dials.done = make(chan struct{})
+		}
+		if err == nil {
+			cc.ReserveNewRequest()
+			p.conns[addr] = append(p.conns[addr], cc)
+		}
+		p.mu.Unlock()
+		return cc, err
+	}
+}
+
+// reserveConn returns an open connection to addr that can take a new
+// stream and true, or the dials to wait for if a new connection must
+// not be dialed, or neither if it must. p.mu must be held.
+func (p *h2Pool) reserveConn(addr string) (*http2.ClientConn, *h2Dials, bool) {
+	open := p.conns[addr][:0]
+	for _, cc := range p.conns[addr] {
+		state := cc.State()
+		switch {
+		case state.Closed || state.Closing:
+			continue
+		case p.options.DisableKeepAlive && numStream(state) == 0:
+			// connections are not reused without keep-alive
+			cc.Close()
+			continue
+		}
+		open = append(open, cc)
+	}
+	p.conns[addr] = open
+
+	var leastBusy *http2.ClientConn
+	minStream := 0
+	for _, cc := range open {
+		n := numStream(cc.State())
+		if leastBusy == nil || n < minStream {
+			leastBusy, minStream = cc, n
+		}
+		if p.options.DisableKeepAlive || (p.options.MaxStreams > 0 && n >= p.options.MaxStreams) {
+			continue
+		}
+		if cc.ReserveNewRequest() {
+			return cc, nil, true
+		}
+	}
+
+	dials := p.dialing[addr]
+	numDialing := 0
+	if dials != nil {
+		numDialing = dials.n
+	}
+	if max := p.options.MaxPerHost; max > 0 && len(open)+numDialing >= max {
+		if leastBusy != nil {
+			return leastBusy, nil, true
+		}
+		return nil, dials, false
+	}
+	if numDialing > 0 && !p.options.DisableKeepAlive {
+		return nil, dials, false
+	}
+	return nil, nil, false
+}
+
+// MarkDead implements http2.ClientConnPool. It removes cc from the pool.
+func (p *h2Pool) MarkDead(cc *http2.ClientConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for addr, conns := range p.conns {
+		for i, c := range conns {
+			if c == cc {
+				p.conns[addr] = append(conns[:i:i], conns[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// closeIdle closes the connections with no active streams.
+func (p *h2Pool) closeIdle() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for addr, conns := range p.conns {
+		open := conns[:0]
+		for _, cc := range conns {
+			if numStream(cc.State()) == 0 {
+				cc.Close()
+				continue
+			}
+			open = append(open, cc)
+		}
+		p.conns[addr] = open
+	}
+}
+
+// numStream returns the number of streams of a connection in the given
+// state, including the reserved and pending ones.
+func numStream(state http2.ClientConnState) int {
+	return state.StreamsActive + state.StreamsReserved + state.StreamsPending
+}
+
+// dialConn opens a new HTTP/2 connection to addr.
+func (p *h2Pool) dialConn(ctx context.Context, addr string) (*http2.ClientConn, error) {
+	conn, err := p.dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if !p.cleartext {
+		if conn, err = tlsHandshake(ctx, conn, addr, p.tls); err != nil {
+			return nil, err
+		}
+	}
+	cc, err := p.transport.NewClientConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return cc, nil
+}
+
+// tlsHandshake runs the TLS handshake on conn negotiating HTTP/2 with
+// the server at addr, using cfg if non-nil. It closes conn and returns
+// a non-nil error if the handshake fails or if the server does not
+// support HTTP/2.
+func tlsHandshake(ctx context.Context, conn net.Conn, addr string, cfg *tls.Config) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+	cfg = cfg.Clone()
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	cfg.NextProtos = []string{http2.NextProtoTLS}
+
+	tlsConn := tls.Client(conn, cfg)
+
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.TLSHandshakeStart != nil {
+		trace.TLSHandshakeStart()
+	}
+	err = tlsConn.HandshakeContext(ctx)
+	if trace != nil && trace.TLSHandshakeDone != nil {
+		trace.TLSHandshakeDone(tlsConn.ConnectionState(), err)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if proto := tlsConn.ConnectionState().NegotiatedProtocol; proto != http2.NextProtoTLS {
+		conn.Close()
+		return nil, fmt.Errorf("server does not support HTTP/2 (negotiated %q)", proto)
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,153 @@
+package requester
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestH2Transport(t *testing.T) {
+	t.Run("send requests over h2c", func(t *testing.T) {
+		srv := newH2CServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		tr := newTransports(Connections{Pool: ConnPoolShared}, 1)
+		defer tr.closeIdle()
+		client := newClient(tr.get(0, connOptions{protocol: ProtocolH2C}), 0)
+
+		for i := 0; i < 3; i++ {
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			readClose(resp) //nolint:errcheck
+			if resp.Proto != "HTTP/2.0" {
+				t.Errorf("exp protocol HTTP/2.0, got %s", resp.Proto)
+			}
+		}
+
+		if got := tr.numOpened(); got != 1 {
+			t.Errorf("exp %d opened connection, got %d", 1, got)
+		}
+	})
+
+	t.Run("limit streams per connection", func(t *testing.T) {
+		testcases := []struct {
+			label string
+			conns Connections
+			exp   int
+		}{
+			{
+				label: "open connections beyond max streams",
+				conns: Connections{MaxStreams: 2, Pool: ConnPoolShared},
+				exp:   2,
+			},
+			{
+				label: "queue streams beyond max connections",
+				conns: Connections{MaxStreams: 1, MaxPerHost: 1, Pool: ConnPoolShared},
+				exp:   1,
+			},
+		}
+
+		for _, tc := range testcases {
+			const numRequest = 4
+
+			// the handler blocks until all requests are received, or
+			// until released if they cannot be concurrent
+			var received sync.WaitGroup
+			received.Add(numRequest)
+			release := make(chan struct{})
+			srv := newH2CServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received.Done()
+				<-release
+			}))
+
+			tr := newTransports(tc.conns, numRequest)
+			client := newClient(tr.get(0, connOptions{protocol: ProtocolH2C}), 0)
+
+			var wg sync.WaitGroup
+			for i := 0; i < numRequest; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					resp, err := client.Get(srv.URL)
+					if err != nil {
+						t.Errorf("%s: unexpected error: %v", tc.label, err)
+						return
+					}
+					readClose(resp) //nolint:errcheck
+				}()
+			}
+			if tc.conns.MaxPerHost == 0 {
+				received.Wait()
+			}
+			close(release)
+			wg.Wait()
+
+			if got := tr.numOpened(); got != tc.exp {
+				t.Errorf("%s: exp %d opened connections, got %d", tc.label, tc.exp, got)
+			}
+			tr.closeIdle()
+			srv.Close()
+		}
+	})
+
+	t.Run("dial without blocking requests to other addresses", func(t *testing.T) {
+		srv := newH2CServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		dialing, release := make(chan struct{}), make(chan struct{})
+		dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if addr == "slow.test:80" {
+				close(dialing)
+				<-release
+				return nil, errTest
+			}
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		}
+		tr := newH2Transport(Connections{}, connOptions{protocol: ProtocolH2C}, dial)
+		defer tr.CloseIdleConnections()
+		client := newClient(tr, 0)
+
+		slowDone := make(chan error)
+		go func() {
+			_, err := client.Get("http://slow.test/")
+			slowDone <- err
+		}()
+		<-dialing
+
+		fastDone := make(chan error)
+		go func() {
+			resp, err := client.Get("http://fast.test/")
+			if err == nil {
+				readClose(resp) //nolint:errcheck
+			}
+			fastDone <- err
+		}()
+
+		select {
+		case err := <-fastDone:
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Error("request blocked by a dial to another address")
+		}
+		close(release)
+		if err := <-slowDone; err == nil {
+			t.Error("exp dial error, got nil")
+		}
+	})
+}
+
+// newH2CServer returns a started server serving handler over h2c.
+func newH2CServer(handler http.Handler) *httptest.Server {
+	return httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+}
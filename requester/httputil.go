@@ -30,3 +30,10 @@ func readClose(resp *http.Response) ([]byte, error) {
 	defer resp.Body.Close()
 	return io.ReadAll(resp.Body)
 }
+
+// drainClose reads resp.Body without keeping it, closes it and returns
+// the number of bytes read.
+func drainClose(resp *http.Response) (int64, error) {
+	defer resp.Body.Close()
+	return io.Copy(io.Discard, resp.Body)
+}
@@ -0,0 +1,93 @@
+package requester
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Interval modes.
+const (
+	// IntervalPerWorker pauses each worker after each of its iterations,
+	// whether its requests succeeded or not, as the think time of a user.
+	IntervalPerWorker = "perWorker"
+	// IntervalGlobal spaces the starts of the requests of all workers
+	// by at least a pause, whatever the concurrency.
+	IntervalGlobal = "global"
+)
+
+// Interval distributions.
+const (
+	// IntervalConstant pauses for Duration.
+	IntervalConstant = "constant"
+	// IntervalUniform pauses for a duration drawn uniformly between
+	// Min and Max.
+	IntervalUniform = "uniform"
+	// IntervalExponential pauses for a duration drawn from an exponential
+	// distribution of mean Duration.
+	IntervalExponential = "exponential"
+	// IntervalNormal pauses for a duration drawn from a normal distribution
+	// of mean Duration and standard deviation StdDev, negative draws
+	// pausing for 0.
+	IntervalNormal = "normal"
+)
+
+// Interval holds the options of the pauses between the requests,
+// according to Mode, IntervalPerWorker if it is empty. Their durations
+// are drawn from Distribution, IntervalConstant if it is empty, with
+// the random generator of the iteration so that a run with the same
+// seed pauses for the same durations. Pauses end early when the run
+// is done.
+type Interval struct {
+	Mode         string
+	Distribution string
+	Duration     time.Duration
+	Min          time.Duration
+	Max          time.Duration
+	StdDev       time.Duration
+}
+
+// global returns true if the Interval spaces the starts of the requests
+// of all workers.
+func (i Interval) global() bool {
+	return i.Mode == IntervalGlobal
+}
+
+// pause returns a duration drawn from the distribution of the Interval
+// with rand.
+func (i Interval) pause(rand *rng) time.Duration {
+	switch i.Distribution {
+	case IntervalUniform:
+		return i.Min + time.Duration(rand.float64()*float64(i.Max-i.Min))
+	case IntervalExponential:
+		return time.Duration(rand.expFloat64() * float64(i.Duration))
+	case IntervalNormal:
+		if d := i.Duration + time.Duration(rand.normFloat64()*float64(i.StdDev)); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return i.Duration
+}
+
+// pacer spaces the starts of the requests of all workers, each start
+// being at least the pause drawn for the previous one after it.
+type pacer struct {
+	mu   sync.Mutex
+	next time.Time // earliest start of the next request
+}
+
+// wait waits for the next start slot, reserving the following one pause
+// after it, and returns true, or returns false if ctx is done before.
+func (p *pacer) wait(ctx context.Context, pause time.Duration) bool {
+	p.mu.Lock()
+	start := time.Now()
+	if start.Before(p.next) {
+		start = p.next
+	}
+	p.next = start.Add(pause)
+	p.mu.Unlock()
+
+	sleep(ctx, time.Until(start))
+	return ctx.Err() == nil
+}
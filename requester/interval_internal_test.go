@@ -0,0 +1,162 @@
+package requester
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestInterval_pause(t *testing.T) {
+	const n = 20000
+
+	testcases := []struct {
+		label           string
+		interval        Interval
+		expMean, expStd time.Duration
+		expMin, expMax  time.Duration
+	}{
+		{
+			label:    "constant",
+			interval: Interval{Duration: 100 * time.Millisecond},
+			expMean:  100 * time.Millisecond,
+			expMin:   100 * time.Millisecond,
+			expMax:   100 * time.Millisecond,
+		},
+		{
+			label:    "uniform",
+			interval: Interval{Distribution: IntervalUniform, Min: 100 * time.Millisecond, Max: 300 * time.Millisecond},
+			expMean:  200 * time.Millisecond,
+			expStd:   57735 * time.Microsecond, // (max-min)/sqrt(12)
+			expMin:   100 * time.Millisecond,
+			expMax:   300 * time.Millisecond,
+		},
+		{
+			label:    "exponential",
+			interval: Interval{Distribution: IntervalExponential, Duration: 100 * time.Millisecond},
+			expMean:  100 * time.Millisecond,
+			expStd:   100 * time.Millisecond,
+			expMax:   math.MaxInt64,
+		},
+		{
+			label:    "normal",
+			interval: Interval{Distribution: IntervalNormal, Duration: 200 * time.Millisecond, StdDev: 20 * time.Millisecond},
+			expMean:  200 * time.Millisecond,
+			expStd:   20 * time.Millisecond,
+			expMax:   math.MaxInt64,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			var sum, sumSquares float64
+			for i := 0; i < n; i++ {
+				d := tc.interval.pause(newRNG(42, i))
+				if d < tc.expMin || d > tc.expMax {
+					t.Fatalf("exp pause in [%v, %v], got %v", tc.expMin, tc.expMax, d)
+				}
+				sum += float64(d)
+				sumSquares += float64(d) * float64(d)
+			}
+
+			mean := sum / n
+			std := math.Sqrt(sumSquares/n - mean*mean)
+			if math.Abs(mean-float64(tc.expMean)) > 0.03*float64(tc.expMean) {
+				t.Errorf("exp mean %v, got %v", tc.expMean, time.Duration(mean))
+			}
+			if math.Abs(std-float64(tc.expStd)) > 0.05*float64(tc.expStd)+1 {
+				t.Errorf("exp standard deviation %v, got %v", tc.expStd, time.Duration(std))
+			}
+		})
+	}
+}
+
+func TestRun_interval(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	t.Run("space the starts of all requests with a global interval", func(t *testing.T) {
+		const spacing = 20 * time.Millisecond
+
+		bk, err := New(Config{
+			Requests:       6,
+			Concurrency:    3,
+			Interval:       Interval{Mode: IntervalGlobal, Duration: spacing},
+			RequestTimeout: time.Second,
+			GlobalTimeout:  5 * time.Second,
+			Silent:         true,
+		}).Run(context.Background(), mustRequest(t, srv.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		starts := make([]time.Duration, 0, len(bk.Records))
+		for _, rec := range bk.Records {
+			starts = append(starts, rec.Start)
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+		if len(starts) != 6 {
+			t.Fatalf("exp 6 records, got %d", len(starts))
+		}
+		for i, start := range starts {
+			if start < time.Duration(i)*spacing {
+				t.Errorf("exp request %d to start after %v, got %v", i, time.Duration(i)*spacing, start)
+			}
+		}
+	})
+
+	t.Run("pause after failed requests", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ln.Close()
+
+		start := time.Now()
+		bk, err := New(Config{
+			Requests:       3,
+			Concurrency:    1,
+			Interval:       Interval{Duration: 30 * time.Millisecond},
+			RequestTimeout: time.Second,
+			GlobalTimeout:  5 * time.Second,
+			Preflight:      Preflight{Skip: true},
+			Silent:         true,
+		}).Run(context.Background(), mustRequest(t, "http://"+ln.Addr().String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bk.Fail != 3 {
+			t.Errorf("exp 3 failed requests, got %d", bk.Fail)
+		}
+		if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+			t.Errorf("exp pauses after failed requests, run took %v", elapsed)
+		}
+	})
+
+	t.Run("cut the pause short at the end of the run", func(t *testing.T) {
+		start := time.Now()
+		bk, err := New(Config{
+			Requests:       -1,
+			Concurrency:    1,
+			Interval:       Interval{Duration: time.Hour},
+			RequestTimeout: time.Second,
+			GlobalTimeout:  50 * time.Millisecond,
+			Silent:         true,
+		}).Run(context.Background(), mustRequest(t, srv.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bk.Length != 1 {
+			t.Errorf("exp 1 request, got %d", bk.Length)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("exp the pause to end with the run, run took %v", elapsed)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package requester
+
+import "sort"
+
+// Endpoint is a scenario of a weighted mix: each iteration runs the Steps
+// of a single Endpoint, picked with a probability proportional to its Weight.
+type Endpoint struct {
+	Name   string
+	Weight int
+	Steps  []Step
+}
+
+// endpoint is a compiled Endpoint.
+type endpoint struct {
+	steps []step
+}
+
+// compileEndpoints returns the compiled endpoints, or the first non-nil
+// error occurring in the process.
+func compileEndpoints(endpoints []Endpoint, global []Check) ([]endpoint, error) {
+	compiled := make([]endpoint, len(endpoints))
+	for i, e := range endpoints {
+		steps, err := compileSteps(e.Steps, global)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i].steps = steps
+	}
+	return compiled, nil
+}
+
+// weights picks indexes with a probability proportional to their weight.
+// It holds the cumulative sums of the weights.
+type weights []int
+
+// newWeights returns the weights of the endpoints. Weights lower than 1
+// are counted as 1.
+func newWeights(endpoints []Endpoint) weights {
+	w := make(weights, len(endpoints))
+	sum := 0
+	for i, e := range endpoints {
+		if e.Weight < 1 {
+			sum++
+		} else {
+			sum += e.Weight
+		}
+		w[i] = sum
+	}
+	return w
+}
+
+// pick returns an index at random using rnd, with a probability
+// proportional to its weight.
+func (w weights) pick(rnd *rng) int {
+	if len(w) < 2 {
+		return 0
+	}
+	return w.index(rnd.intn(w[len(w)-1]))
+}
+
+// index returns the index in which n falls, n being in [0, sum of weights).
+func (w weights) index(n int) int {
+	return sort.Search(len(w), func(i int) bool { return w[i] > n })
+}
@@ -0,0 +1,82 @@
+package requester
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWeights(t *testing.T) {
+	w := newWeights([]Endpoint{{Weight: 7}, {Weight: 0}, {Weight: 2}})
+
+	testcases := []struct {
+		n   int
+		exp int
+	}{
+		{n: 0, exp: 0},
+		{n: 6, exp: 0},
+		{n: 7, exp: 1}, // weight 0 counted as 1
+		{n: 8, exp: 2},
+		{n: 9, exp: 2},
+	}
+
+	for _, tc := range testcases {
+		if got := w.index(tc.n); got != tc.exp {
+			t.Errorf("index(%d): exp %d, got %d", tc.n, tc.exp, got)
+		}
+	}
+
+	if got := newWeights([]Endpoint{{Weight: 5}}).pick(newRNG(0, 0)); got != 0 {
+		t.Errorf("single endpoint: exp 0, got %d", got)
+	}
+}
+
+func TestRunMix(t *testing.T) {
+	t.Run("pick endpoints by weight and tag records", func(t *testing.T) {
+		const numRequest = 1000
+
+		transport := &routeTransport{routes: map[string]routeResponse{
+			"/items":   {body: "[1,2,3]"},
+			"/items/1": {body: "{}"},
+		}}
+
+		r := New(Config{
+			Requests:       numRequest,
+			Concurrency:    10,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Silent:         true,
+		})
+		r.newTransport = func(int, connOptions) http.RoundTripper { return transport }
+
+		list, _ := http.NewRequest("GET", "http://a.b/items", nil)
+		item, _ := http.NewRequest("GET", "http://a.b/items/1", nil)
+
+		bk, err := r.RunMix(context.Background(), []Endpoint{
+			{Name: "list", Weight: 3, Steps: []Step{{Request: list}}},
+			{Name: "item", Weight: 1, Steps: []Step{{Request: item}}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		stats := bk.EndpointsStats(2)
+		if stats[0].Length+stats[1].Length != numRequest {
+			t.Fatalf("exp %d records, got %d and %d", numRequest, stats[0].Length, stats[1].Length)
+		}
+
+		// expect 750 records for the first endpoint, with a large margin
+		// to avoid flakiness
+		if n := stats[0].Length; n < 650 || n > 850 {
+			t.Errorf("exp ~750 records for weight 3/4, got %d", n)
+		}
+
+		// endpoints are identified by the length of their response body
+		for _, rec := range bk.Records {
+			if exp := []int{7, 2}[rec.Endpoint]; rec.Bytes != exp {
+				t.Fatalf("endpoint %d: exp %d bytes, got %d", rec.Endpoint, exp, rec.Bytes)
+			}
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultPreflightBackoff is the first wait between two pre-flight
+	// attempts if Preflight.Backoff is not set.
+	defaultPreflightBackoff = 100 * time.Millisecond
+	// maxPreflightBackoff is the longest wait between two pre-flight
+	// attempts the backoff grows to.
+	maxPreflightBackoff = 5 * time.Second
+)
+
+// Preflight holds the options of the check sent before the run to make
+// sure the server is ready. Unless Skip is true, the first request of
+// each endpoint is sent once, or a request of Method to URL if it is set,
+// with the connection options of the first request. The server is ready
+// if the response has one of the statuses Status, or any status if it
+// is empty. If MaxWait is set, the check is retried until the server is
+// ready or MaxWait is elapsed, waiting Backoff then twice as long between
+// the attempts.
+type Preflight struct {
+	Skip    bool
+	URL     string
+	Method  string
+	Status  string
+	MaxWait time.Duration
+	Backoff time.Duration
+}
+
+// preflight runs the pre-flight check of the endpoints and returns
+// the error of its last attempt if the server is not ready, or ctx.Err()
+// if ctx is done before.
+func (r *Requester) preflight(ctx context.Context) error {
+	opts := r.config.Preflight
+	if opts.Skip {
+		return nil
+	}
+
+	match := func(int) bool { return true }
+	if opts.Status != "" {
+		m, err := statusMatcher(opts.Status)
+		if err != nil {
+			return err
+		}
+		match = m
+	}
+
+	waitCtx := ctx
+	if opts.MaxWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, opts.MaxWait)
+		defer cancel()
+	}
+
+	if opts.URL != "" {
+		method := opts.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		newRequest := func() (*http.Request, error) {
+			return http.NewRequest(method, opts.URL, nil)
+		}
+		return r.waitReady(ctx, waitCtx, newRequest, r.endpoints[0].steps[0].conn, match)
+	}
+
+	for _, e := range r.endpoints {
+		s := e.steps[0]
+		newRequest := func() (*http.Request, error) {
+			return s.request.build(&renderContext{rand: newRNG(r.seed, -1)}), nil
+		}
+		if err := r.waitReady(ctx, waitCtx, newRequest, s.conn, match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitReady pings the request returned by newRequest until its response
+// status matches, retrying with an exponential backoff until waitCtx
+// is done if Preflight.MaxWait is set.
+func (r *Requester) waitReady(
+	ctx, waitCtx context.Context,
+	newRequest func() (*http.Request, error),
+	opts connOptions,
+	match func(code int) bool,
+) error {
+	backoff := r.config.Preflight.Backoff
+	if backoff <= 0 {
+		backoff = defaultPreflightBackoff
+	}
+
+	for {
+		req, err := newRequest()
+		if err != nil {
+			return err
+		}
+		err = r.ping(req.WithContext(waitCtx), opts, match)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil || r.config.Preflight.MaxWait <= 0 {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("not ready after %s: %s", r.config.Preflight.MaxWait, err)
+		case <-timer.C:
+		}
+
+		if backoff < maxPreflightBackoff {
+			backoff *= 2
+			if backoff > maxPreflightBackoff {
+				backoff = maxPreflightBackoff
+			}
+		}
+	}
+}
+
+// ping sends req once and returns a non-nil error if it fails or if
+// the status of its response does not match. Its connection is closed,
+// a warm start being the purpose of the warmup.
+func (r *Requester) ping(req *http.Request, opts connOptions, match func(code int) bool) error {
+	client := newClient(r.newTransport(0, opts), r.config.RequestTimeout)
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if !match(resp.StatusCode) {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRun_preflight(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string
+		numDown  int // number of health requests responded 503
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/health" && numDown > 0 {
+			numDown--
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	run := func(preflight Preflight, down int) ([]string, error) {
+		mu.Lock()
+		received, numDown = nil, down
+		mu.Unlock()
+
+		_, err := New(Config{
+			Requests:       1,
+			Concurrency:    1,
+			RequestTimeout: time.Second,
+			GlobalTimeout:  5 * time.Second,
+			Preflight:      preflight,
+			Silent:         true,
+		}).Run(context.Background(), mustRequest(t, srv.URL+"/api"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		return received, err
+	}
+
+	t.Run("send the first request once", func(t *testing.T) {
+		got, err := run(Preflight{}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := "GET /api,GET /api"; strings.Join(got, ",") != exp {
+			t.Errorf("exp requests %s, got %s", exp, got)
+		}
+	})
+
+	t.Run("skip the check", func(t *testing.T) {
+		got, err := run(Preflight{Skip: true}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := "GET /api"; strings.Join(got, ",") != exp {
+			t.Errorf("exp requests %s, got %s", exp, got)
+		}
+	})
+
+	t.Run("send the check to the health url", func(t *testing.T) {
+		got, err := run(Preflight{URL: srv.URL + "/health", Method: "HEAD"}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := "HEAD /health,GET /api"; strings.Join(got, ",") != exp {
+			t.Errorf("exp requests %s, got %s", exp, got)
+		}
+	})
+
+	t.Run("return ErrConnection on unexpected status", func(t *testing.T) {
+		got, err := run(Preflight{URL: srv.URL + "/health", Status: "2xx"}, 1)
+		if !errors.Is(err, ErrConnection) || !strings.Contains(err.Error(), "503") {
+			t.Fatalf("exp ErrConnection for status 503, got %v", err)
+		}
+		if len(got) != 1 {
+			t.Errorf("exp a single attempt, got requests %s", got)
+		}
+	})
+
+	t.Run("retry until the server is ready", func(t *testing.T) {
+		got, err := run(Preflight{
+			URL:     srv.URL + "/health",
+			Status:  "200",
+			MaxWait: time.Second,
+			Backoff: 5 * time.Millisecond,
+		}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := "GET /health,GET /health,GET /health,GET /api"; strings.Join(got, ",") != exp {
+			t.Errorf("exp requests %s, got %s", exp, got)
+		}
+	})
+
+	t.Run("return ErrConnection after max wait", func(t *testing.T) {
+		start := time.Now()
+		_, err := run(Preflight{
+			URL:     srv.URL + "/health",
+			Status:  "200",
+			MaxWait: 50 * time.Millisecond,
+			Backoff: 10 * time.Millisecond,
+		}, 1000)
+		if !errors.Is(err, ErrConnection) || !strings.Contains(err.Error(), "not ready after 50ms") {
+			t.Fatalf("exp ErrConnection after max wait, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("exp check to end after max wait, took %v", elapsed)
+		}
+	})
+
+	t.Run("return ErrCanceled when interrupted", func(t *testing.T) {
+		blockSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer blockSrv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		_, err := New(Config{
+			Requests:       1,
+			Concurrency:    1,
+			RequestTimeout: 5 * time.Second,
+			GlobalTimeout:  5 * time.Second,
+			Silent:         true,
+		}).Run(ctx, mustRequest(t, blockSrv.URL))
+		if !errors.Is(err, ErrCanceled) {
+			t.Errorf("exp ErrCanceled, got %v", err)
+		}
+	})
+}
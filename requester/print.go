@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/benchttp/runner/ansi"
@@ -15,6 +16,7 @@ type state struct {
 	done             bool
 	err              error
 	reqcur, reqmax   int
+	unit             string
 	timeout, elapsed time.Duration
 }
 
@@ -25,13 +27,25 @@ func (r *Requester) state() state {
 	return state{
 		done:    r.done,
 		err:     r.runErr,
-		reqcur:  len(r.records),
+		reqcur:  int(atomic.LoadInt64(&r.numIter)),
 		reqmax:  r.config.Requests,
-		timeout: r.config.GlobalTimeout,
+		unit:    r.unit(),
+		timeout: r.timeout(),
 		elapsed: time.Since(r.start),
 	}
 }
 
+// unit returns the unit of the progression: "iterations" if the benchmark
+// runs a scenario of several steps, "requests" otherwise.
+func (r *Requester) unit() string {
+	for _, e := range r.endpoints {
+		if len(e.steps) > 1 {
+			return "iterations"
+		}
+	}
+	return "requests"
+}
+
 // String returns a string representation of state for a fancy display
 // in a CLI:
 // 	RUNNING ◼︎◼︎◼︎◼︎◼︎◼︎◼︎◼︎◼︎◼︎ 50% | 50/100 requests | 27s timeout
@@ -51,10 +65,10 @@ func (s state) String() string {
 	}
 
 	return fmt.Sprintf(
-		"%s%s %s %d%% | %d/%s requests | %.0fs timeout             \n",
+		"%s%s %s %d%% | %d/%s %s | %.0fs timeout             \n",
 		ansi.Erase(1),                 // replace previous line
 		s.status(), timeline, pctdone, // progress
-		s.reqcur, reqmax, // requests
+		s.reqcur, reqmax, s.unit, // requests
 		countdown.Seconds(), // timeout
 	)
 }
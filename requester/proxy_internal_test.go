@@ -0,0 +1,289 @@
+package requester
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsSrv.Close()
+	// the test servers share their certificate, trusted by tlsConfig
+	tlsConfig := tlsSrv.Client().Transport.(*http.Transport).TLSClientConfig
+
+	httpProxy, httpCounts := newHTTPProxy("user:pass", false)
+	defer httpProxy.Close()
+
+	httpsProxy, httpsCounts := newHTTPProxy("user:pass", true)
+	defer httpsProxy.Close()
+
+	socksProxy, socksTunnels := newSOCKS5Proxy(t)
+	defer socksProxy.Close()
+
+	testcases := []struct {
+		label    string
+		proxy    *Proxy
+		url      string
+		count    *int64 // number of tunnels or forwarded requests
+		expCount int64
+		expProxy bool // proxy phase
+		expErr   string
+	}{
+		{
+			label:    "forward http requests to http proxy",
+			proxy:    &Proxy{URL: mustParseURL("http://user:pass@" + httpProxy.Listener.Addr().String())},
+			url:      srv.URL,
+			count:    &httpCounts.forwards,
+			expCount: 2,
+		},
+		{
+			label:    "tunnel https requests through http proxy",
+			proxy:    &Proxy{URL: mustParseURL("http://user:pass@" + httpProxy.Listener.Addr().String())},
+			url:      tlsSrv.URL,
+			count:    &httpCounts.tunnels,
+			expCount: 1,
+			expProxy: true,
+		},
+		{
+			label:  "return error for rejected credentials",
+			proxy:  &Proxy{URL: mustParseURL("http://user:oops@" + httpProxy.Listener.Addr().String())},
+			url:    tlsSrv.URL,
+			count:  &httpCounts.tunnels,
+			expErr: "407 Proxy Authentication Required",
+		},
+		{
+			label:    "tunnel through https proxy trusted by the request tls config",
+			proxy:    &Proxy{URL: mustParseURL("https://user:pass@" + httpsProxy.Listener.Addr().String())},
+			url:      tlsSrv.URL,
+			count:    &httpsCounts.tunnels,
+			expCount: 1,
+			expProxy: true,
+		},
+		{
+			label:    "forward http requests to https proxy trusted by the request tls config",
+			proxy:    &Proxy{URL: mustParseURL("https://user:pass@" + httpsProxy.Listener.Addr().String())},
+			url:      srv.URL,
+			count:    &httpsCounts.forwards,
+			expCount: 2,
+		},
+		{
+			label:    "tunnel through socks5 proxy",
+			proxy:    &Proxy{URL: mustParseURL("socks5://" + socksProxy.Addr().String())},
+			url:      srv.URL,
+			count:    socksTunnels,
+			expCount: 1,
+			expProxy: true,
+		},
+		{
+			label:    "bypass proxy for no proxy hosts",
+			proxy:    &Proxy{URL: mustParseURL("socks5://" + socksProxy.Addr().String()), NoProxy: []string{"127.0.0.0/8"}},
+			url:      srv.URL,
+			count:    socksTunnels,
+			expCount: 0,
+		},
+		{
+			label:    "bypass http proxy for no proxy hosts",
+			proxy:    &Proxy{URL: mustParseURL("http://user:pass@" + httpProxy.Listener.Addr().String()), NoProxy: []string{"127.0.0.0/8"}},
+			url:      srv.URL,
+			count:    &httpCounts.forwards,
+			expCount: 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		atomic.StoreInt64(tc.count, 0)
+		tr := newTransports(Connections{Pool: ConnPoolShared}, 1)
+
+		for i := 0; i < 2; i++ {
+			tracer := newTracer(tr.get(0, connOptions{proxy: tc.proxy, tls: tlsConfig}))
+			resp, err := newClient(tracer, 0).Get(tc.url)
+			if tc.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+					t.Errorf("%s: exp error containing %q, got %v", tc.label, tc.expErr, err)
+				}
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.label, err)
+			}
+			readClose(resp) //nolint:errcheck
+			tracer.addEventBodyRead()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("%s: exp status 200, got %d", tc.label, resp.StatusCode)
+			}
+
+			// the connection is reused for the second request
+			_, timing := tracer.results()
+			if gotProxy := timing.Proxy > 0; i == 0 && gotProxy != tc.expProxy {
+				t.Errorf("%s: exp proxy phase %v, got %v", tc.label, tc.expProxy, timing.Proxy)
+			}
+		}
+		tr.closeIdle()
+
+		if got := atomic.LoadInt64(tc.count); got != tc.expCount {
+			t.Errorf("%s: exp count %d, got %d", tc.label, tc.expCount, got)
+		}
+	}
+}
+
+func TestProxy_bypass(t *testing.T) {
+	p := &Proxy{NoProxy: []string{"example.com", ".internal", "10.0.0.0/8", "::1", "localhost:8080"}}
+
+	testcases := []struct {
+		addr string
+		exp  bool
+	}{
+		{addr: "example.com:443", exp: true},
+		{addr: "api.example.com:443", exp: true},
+		{addr: "notexample.com:443", exp: false},
+		{addr: "a.internal:80", exp: true},
+		{addr: "10.1.2.3:80", exp: true},
+		{addr: "11.1.2.3:80", exp: false},
+		{addr: "[::1]:80", exp: true},
+		{addr: "localhost:8080", exp: true},
+		{addr: "localhost:9090", exp: false},
+	}
+
+	for _, tc := range testcases {
+		if got := p.bypass(tc.addr); got != tc.exp {
+			t.Errorf("%s: exp %v, got %v", tc.addr, tc.exp, got)
+		}
+	}
+
+	if !(&Proxy{NoProxy: []string{"*"}}).bypass("a.b:80") {
+		t.Error("exp wildcard to bypass all hosts")
+	}
+}
+
+// helpers
+
+func mustParseURL(rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// proxyCounts are the numbers of tunnels and forwarded requests
+// of a proxy.
+type proxyCounts struct {
+	tunnels  int64
+	forwards int64
+}
+
+// newHTTPProxy returns a started HTTP proxy, over TLS if secure,
+// tunneling the CONNECT requests and forwarding the other requests
+// authenticated with credentials, and its counts.
+func newHTTPProxy(credentials string, secure bool) (*httptest.Server, *proxyCounts) {
+	counts := &proxyCounts{}
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != auth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		if r.Method != http.MethodConnect {
+			if !r.URL.IsAbs() {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			resp, err := http.DefaultTransport.RoundTrip(&http.Request{Method: r.Method, URL: r.URL, Header: http.Header{}})
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			resp.Body.Close()
+			atomic.AddInt64(&counts.forwards, 1)
+			w.WriteHeader(resp.StatusCode)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		atomic.AddInt64(&counts.tunnels, 1)
+		io.WriteString(conn, "HTTP/1.1 200 OK\r\n\r\n") //nolint:errcheck
+		pipe(conn, buf, target)
+	}))
+	if secure {
+		srv.StartTLS()
+	} else {
+		srv.Start()
+	}
+	return srv, counts
+}
+
+// newSOCKS5Proxy returns a started SOCKS5 proxy without authentication
+// and its number of tunnels.
+func newSOCKS5Proxy(t *testing.T) (net.Listener, *int64) {
+	var tunnels int64
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				// greeting: version, methods, accepting no authentication
+				head := make([]byte, 2)
+				if _, err := io.ReadFull(conn, head); err != nil {
+					conn.Close()
+					return
+				}
+				io.ReadFull(conn, make([]byte, head[1])) //nolint:errcheck
+				conn.Write([]byte{5, 0})                 //nolint:errcheck
+
+				// request: version, command, reserved, IPv4 address, port
+				req := make([]byte, 10)
+				if _, err := io.ReadFull(conn, req); err != nil || req[3] != 1 {
+					conn.Close()
+					return
+				}
+				addr := &net.TCPAddr{IP: net.IP(req[4:8]), Port: int(binary.BigEndian.Uint16(req[8:]))}
+				target, err := net.Dial("tcp", addr.String())
+				if err != nil {
+					conn.Close()
+					return
+				}
+				atomic.AddInt64(&tunnels, 1)
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}) //nolint:errcheck
+				pipe(conn, conn, target)
+			}()
+		}
+	}()
+	return ln, &tunnels
+}
+
+// pipe copies the data between the client conn, read from r,
+// and target until any of them is closed.
+func pipe(conn net.Conn, r io.Reader, target net.Conn) {
+	go func() {
+		io.Copy(target, r) //nolint:errcheck
+		target.Close()
+	}()
+	io.Copy(conn, target) //nolint:errcheck
+	conn.Close()
+}
@@ -0,0 +1,81 @@
+package requester
+
+import (
+	"fmt"
+	"math"
+)
+
+// rng is a splitmix64 pseudo-random generator. It is cheap to create,
+// so that each iteration gets its own generator derived from the seed
+// and the iteration index: the generated values are then the same from
+// one run to another, regardless of the scheduling of the iterations.
+// It is not safe for concurrent use.
+type rng struct {
+	state uint64
+}
+
+// newRNG returns a rng for the given seed and iteration.
+func newRNG(seed int64, iteration int) *rng {
+	return &rng{state: mix64(uint64(seed) ^ mix64(uint64(iteration)+1))}
+}
+
+// uint64 returns a pseudo-random 64-bit value.
+func (r *rng) uint64() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	return mix64(r.state)
+}
+
+// intn returns a pseudo-random int in [0, n). It panics if n <= 0.
+func (r *rng) intn(n int) int {
+	if n <= 0 {
+		panic("invalid argument to intn")
+	}
+	return int(r.uint64() % uint64(n))
+}
+
+// float64 returns a pseudo-random float64 in [0, 1).
+func (r *rng) float64() float64 {
+	return float64(r.uint64()>>11) / (1 << 53)
+}
+
+// expFloat64 returns a pseudo-random float64 drawn from an exponential
+// distribution of mean 1.
+func (r *rng) expFloat64() float64 {
+	return -math.Log(1 - r.float64())
+}
+
+// normFloat64 returns a pseudo-random float64 drawn from a normal
+// distribution of mean 0 and standard deviation 1, using the Box-Muller
+// transform.
+func (r *rng) normFloat64() float64 {
+	u1, u2 := 1-r.float64(), r.float64()
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// alphanumeric returns a pseudo-random alphanumeric string of length n.
+func (r *rng) alphanumeric(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphanumeric[r.intn(len(alphanumeric))]
+	}
+	return string(b)
+}
+
+// uuid returns a pseudo-random version 4 UUID.
+func (r *rng) uuid() string {
+	hi, lo := r.uint64(), r.uint64()
+	hi = hi&^0xf000 | 0x4000     // version 4
+	lo = lo&^(0xc<<60) | 0x8<<60 // variant RFC 4122
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+		hi>>32, hi>>16&0xffff, hi&0xffff, lo>>48, lo&0xffffffffffff,
+	)
+}
+
+// mix64 is the finalizer of splitmix64.
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
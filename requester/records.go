@@ -0,0 +1,177 @@
+package requester
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Record storages.
+const (
+	// StorageMemory keeps the records in memory, in Benchmark.Records.
+	StorageMemory = "memory"
+	// StorageDisk writes the records to NDJSON segment files,
+	// so that the memory used does not grow with the number of records.
+	StorageDisk = "disk"
+)
+
+// recordsPerSegment is the number of records of each segment file
+// in storage StorageDisk.
+const recordsPerSegment = 100000
+
+// Records holds the options of the storage of the records.
+// An empty Storage is read as StorageMemory.
+// Dir is the directory of the segment files in storage StorageDisk,
+// a new temporary directory being created if it is empty.
+type Records struct {
+	Storage string
+	Dir     string
+}
+
+// recordSink stores the records of a run.
+type recordSink interface {
+	// add stores rec.
+	add(rec Record) error
+	// close flushes the stored records and releases the sink resources.
+	close() error
+}
+
+// newRecordSink returns the recordSink for the storage options opts,
+// capacity being the number of records expected, or a non-nil error
+// if the directory of storage StorageDisk cannot be created.
+func newRecordSink(opts Records, capacity int) (recordSink, error) {
+	if opts.Storage != StorageDisk {
+		return &memorySink{records: make([]Record, 0, capacity)}, nil
+	}
+	return newDiskSink(opts.Dir)
+}
+
+// memorySink is a recordSink keeping the records in memory.
+type memorySink struct {
+	records []Record
+}
+
+func (s *memorySink) add(rec Record) error {
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func (s *memorySink) close() error {
+	return nil
+}
+
+// diskSink is a recordSink writing the records as JSON lines to segment
+// files of perSegment records in a directory, named after their index
+// so that reading them in lexical order reads the records in order.
+type diskSink struct {
+	dir        string
+	perSegment int // number of records per segment file
+
+	segment int // index of the current segment file
+	n       int // number of records of the current segment file
+	f       *os.File
+	w       *bufio.Writer
+	enc     *json.Encoder
+}
+
+// newDiskSink returns a diskSink writing to dir, creating it if needed,
+// or to a new temporary directory if dir is empty.
+func newDiskSink(dir string) (*diskSink, error) {
+	var err error
+	if dir == "" {
+		dir, err = os.MkdirTemp("", "benchttp-records-")
+	} else {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &diskSink{dir: dir, perSegment: recordsPerSegment}, nil
+}
+
+func (s *diskSink) add(rec Record) error {
+	if s.f == nil || s.n == s.perSegment {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	s.n++
+	return s.enc.Encode(rec)
+}
+
+// rotate closes the current segment file, if any, and opens the next one.
+func (s *diskSink) rotate() error {
+	if err := s.close(); err != nil {
+		return err
+	}
+	s.segment++
+	name := filepath.Join(s.dir, fmt.Sprintf("records-%06d.ndjson", s.segment))
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	s.f, s.n = f, 0
+	s.w = bufio.NewWriter(f)
+	s.enc = json.NewEncoder(s.w)
+	return nil
+}
+
+func (s *diskSink) close() error {
+	if s.f == nil {
+		return nil
+	}
+	f := s.f
+	s.f = nil
+	if err := s.w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadRecords calls fn for each record written to the segment files of dir
+// in storage StorageDisk, in the order they were written. It stops at the
+// first non-nil error returned by fn or occurring reading the files, and
+// returns it.
+func ReadRecords(dir string, fn func(Record) error) error {
+	names, err := filepath.Glob(filepath.Join(dir, "records-*.ndjson"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := readSegment(name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSegment calls fn for each record of the segment file name.
+func readSegment(name string, fn func(Record) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var rec Record
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package requester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiskSink(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "records")
+	sink, err := newDiskSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.perSegment = 2
+
+	var exp []Record
+	for i := 0; i < 5; i++ {
+		rec := Record{Time: time.Duration(i), Code: 200, Events: []Event{{Name: "BodyRead", Time: 1}}}
+		if err := sink.add(rec); err != nil {
+			t.Fatal(err)
+		}
+		exp = append(exp, rec)
+	}
+	if err := sink.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if names, _ := filepath.Glob(filepath.Join(dir, "*.ndjson")); len(names) != 3 {
+		t.Errorf("exp 3 segment files, got %q", names)
+	}
+
+	var got []Record
+	if err := ReadRecords(dir, func(rec Record) error {
+		got = append(got, rec)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected records read:\nexp %+v\ngot %+v", exp, got)
+	}
+}
+
+func TestRun_records(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(500)
+		}
+	}))
+	defer srv.Close()
+
+	endpoints := []Endpoint{
+		{Weight: 1, Steps: []Step{{Request: mustRequest(t, srv.URL)}}},
+		{Weight: 1, Steps: []Step{{Request: mustRequest(t, srv.URL+"?fail=1")}}},
+	}
+	config := Config{
+		Requests:       20,
+		Concurrency:    2,
+		RequestTimeout: time.Second,
+		GlobalTimeout:  5 * time.Second,
+		Checks:         []Check{{Name: "status", Kind: CheckStatus, Value: "2xx"}},
+		BucketInterval: time.Millisecond,
+		Seed:           1,
+		Silent:         true,
+	}
+
+	t.Run("keep records in memory", func(t *testing.T) {
+		bk, err := New(config).RunMix(context.Background(), endpoints)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bk.Records) != 20 || bk.RecordsDir != "" {
+			t.Fatalf("exp 20 records in memory, got %d and dir %q", len(bk.Records), bk.RecordsDir)
+		}
+
+		// the stats accumulated during the run match the ones
+		// computed from the records
+		fromRecords := Benchmark{Records: bk.Records, Duration: bk.Duration, BucketInterval: time.Millisecond}
+		if !reflect.DeepEqual(bk.Stats(), fromRecords.Stats()) {
+			t.Errorf("unexpected stats:\nexp %+v\ngot %+v", fromRecords.Stats(), bk.Stats())
+		}
+		if !reflect.DeepEqual(bk.EndpointsStats(2), fromRecords.EndpointsStats(2)) {
+			t.Errorf("unexpected endpoints stats:\nexp %+v\ngot %+v", fromRecords.EndpointsStats(2), bk.EndpointsStats(2))
+		}
+		if !reflect.DeepEqual(bk.TimeSeries(), fromRecords.TimeSeries()) {
+			t.Errorf("unexpected time series:\nexp %+v\ngot %+v", fromRecords.TimeSeries(), bk.TimeSeries())
+		}
+	})
+
+	t.Run("write records to disk", func(t *testing.T) {
+		dir := t.TempDir()
+		config := config
+		config.Records = Records{Storage: StorageDisk, Dir: dir}
+
+		bk, err := New(config).RunMix(context.Background(), endpoints)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bk.Records) != 0 || bk.RecordsDir != dir {
+			t.Fatalf("exp records in %q, got %d records and dir %q", dir, len(bk.Records), bk.RecordsDir)
+		}
+
+		var records []Record
+		if err := ReadRecords(dir, func(rec Record) error {
+			records = append(records, rec)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 20 {
+			t.Fatalf("exp 20 records written, got %d", len(records))
+		}
+
+		fromRecords := Benchmark{Records: records}
+		if bk.Length != 20 || bk.Fail != fromRecords.StatusCodes()[500] {
+			t.Errorf("exp 20 requests and %d fails, got %d and %d", fromRecords.StatusCodes()[500], bk.Length, bk.Fail)
+		}
+		if !reflect.DeepEqual(bk.Stats(), fromRecords.Stats()) {
+			t.Errorf("unexpected stats:\nexp %+v\ngot %+v", fromRecords.Stats(), bk.Stats())
+		}
+		if !reflect.DeepEqual(bk.StatusCodes(), fromRecords.StatusCodes()) {
+			t.Errorf("unexpected status codes:\nexp %v\ngot %v", fromRecords.StatusCodes(), bk.StatusCodes())
+		}
+		if exp := bk.Fail; len(bk.Checks) != 1 || bk.Checks[0].Fail != exp || bk.Checks[0].Pass != 20-exp {
+			t.Errorf("exp %d failed checks out of 20, got %+v", exp, bk.Checks)
+		}
+	})
+}
+
+func mustRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
@@ -2,9 +2,11 @@ package requester
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/benchttp/runner/dispatcher"
@@ -18,22 +20,50 @@ const (
 type Config struct {
 	Requests       int
 	Concurrency    int
-	Interval       time.Duration
+	Rate           int
+	Stages         []Stage
+	Interval       Interval
 	RequestTimeout time.Duration
 	GlobalTimeout  time.Duration
+	Drain          time.Duration
+	Warmup         Warmup
+	BucketInterval time.Duration
+	Preflight      Preflight
+	Connections    Connections
+	Records        Records
+	Checks         []Check
+	Data           Data
+	Seed           int64
 	Silent         bool
 }
 
 // Requester executes the benchmark. It wraps http.Client.
+// The records and statistics of the iterations are kept by their workers
+// and merged at the end of the run, so that the workers do not contend
+// for a lock on each request.
 type Requester struct {
-	records []Record
-	numErr  int
-	runErr  error
-	start   time.Time
-	done    bool
+	records    recordSink
+	recordsErr error // first error storing a record
+	recordsMu  sync.Mutex
+	series     series // time buckets completed by the workers
+	seriesMu   sync.Mutex
+	numIter    int64 // number of iterations done, accessed atomically
+	seed       int64
+	iter       int64 // index of the next iteration, accessed atomically
+	workers    *workers
+	feeder     *feeder
+	stop       context.CancelFunc // ends the run early, as if it was complete
+	runErr     error
+	start      time.Time
+	done       bool
+	stage      int32 // index of the current stage, accessed atomically
+	pacer      pacer // spaces the requests with a global interval
+	transports *transports
 
 	config       Config
-	newTransport func() http.RoundTripper
+	endpoints    []endpoint
+	weights      weights
+	newTransport func(worker int, opts connOptions) http.RoundTripper
 
 	mu sync.RWMutex
 }
@@ -42,36 +72,112 @@ type Requester struct {
 // it is the caller's responsibility to ensure cfg is valid using
 // cfg.Validate.
 func New(cfg Config) *Requester {
-	recordsCap := cfg.Requests
-	if recordsCap < 1 {
-		recordsCap = defaultRecordsCap
+	// a random seed is picked if none is set, it is reported
+	// in the Benchmark so the run can be reproduced
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
 
-	return &Requester{
-		records: make([]Record, 0, recordsCap),
-		config:  cfg,
-		newTransport: func() http.RoundTripper {
-			return newTracer()
-		},
+	r := &Requester{
+		seed:   seed,
+		config: cfg,
+		series: newSeries(cfg.BucketInterval),
+	}
+	r.workers = newWorkers(r.numWorker(), cfg.BucketInterval)
+	r.transports = newTransports(cfg.Connections, r.numWorker())
+	r.newTransport = func(worker int, opts connOptions) http.RoundTripper {
+		return newTracer(r.transports.get(worker, opts))
 	}
+	return r
+}
+
+// numWorker returns the maximum number of concurrent workers of the run.
+func (r *Requester) numWorker() int {
+	return stages(r.config.Stages).maxConcurrency(r.config.Concurrency)
 }
 
 // Run starts the benchmark test and pipelines the results inside a Report.
 // Returns the Report when the test ended and all results have been collected.
 func (r *Requester) Run(ctx context.Context, req *http.Request) (Benchmark, error) {
-	if err := r.ping(req); err != nil {
+	return r.RunScenario(ctx, []Step{{Request: req}})
+}
+
+// RunScenario is like Run, except that each iteration sends the requests
+// of the steps sequentially, passing the values extracted from a response
+// to the next steps. Config.Requests is then the number of iterations.
+// An iteration is aborted at the first step whose request or extraction
+// fails.
+func (r *Requester) RunScenario(ctx context.Context, steps []Step) (Benchmark, error) {
+	return r.RunMix(ctx, []Endpoint{{Weight: 1, Steps: steps}})
+}
+
+// RunMix is like RunScenario, except that each iteration runs the steps
+// of a single endpoint, picked with a probability proportional to its
+// weight. Records are marked with the index of their endpoint.
+func (r *Requester) RunMix(ctx context.Context, endpoints []Endpoint) (Benchmark, error) {
+	if err := r.config.Data.validate(r.numWorker()); err != nil {
+		return Benchmark{}, err
+	}
+	compiled, err := compileEndpoints(endpoints, r.config.Checks)
+	if err != nil {
+		return Benchmark{}, err
+	}
+	r.endpoints = compiled
+	r.weights = newWeights(endpoints)
+	r.feeder = newFeeder(r.config.Data, r.numWorker())
+
+	if err := r.preflight(ctx); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return Benchmark{}, ErrCanceled
+		}
 		return Benchmark{}, fmt.Errorf("%w: %s", ErrConnection, err)
 	}
+	defer r.transports.closeIdle()
+
+	recordsCap := r.config.Requests
+	if recordsCap < 1 {
+		recordsCap = defaultRecordsCap
+	}
+	r.records, err = newRecordSink(r.config.Records, recordsCap)
+	if err != nil {
+		return Benchmark{}, fmt.Errorf("%w: %s", ErrRecords, err)
+	}
+	defer r.records.close()
 
 	var (
 		errRun error
 
 		numWorker = r.config.Concurrency
+		rate      = r.config.Rate
 		maxIter   = r.config.Requests
-		timeout   = r.config.GlobalTimeout
-		interval  = r.config.Interval
+		timeout   = r.timeout()
 	)
 
+	if len(r.config.Stages) > 0 {
+		_, numWorker, rate = stages(r.config.Stages).at(0, numWorker)
+	}
+
+	var warmup Benchmark
+	if r.config.Warmup.enabled() {
+		// with stages, the warmup runs at the target of the first one
+		// rather than at the load of its start
+		warmupWorker, warmupRate := numWorker, rate
+		if len(r.config.Stages) > 0 {
+			warmupWorker, warmupRate = r.config.Stages[0].target(r.config.Concurrency)
+		}
+		warmup, err = r.warmup(ctx, warmupWorker, warmupRate)
+		if err == context.Canceled {
+			return Benchmark{}, ErrCanceled
+		}
+		if err != nil {
+			return Benchmark{}, err
+		}
+	}
+
+	// the connections opened by the pre-flight check and the warmup are not reported
+	numPrevConn := r.transports.numOpened()
+
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -84,9 +190,29 @@ func (r *Requester) Run(ctx context.Context, req *http.Request) (Benchmark, erro
 		go r.refreshState()
 	}
 
-	err := dispatcher.New(numWorker).Do(ctx, maxIter, r.record(req, interval))
+	dsp := dispatcher.New(numWorker)
+	dsp.SetRate(rate)
+
+	if len(r.config.Stages) > 0 {
+		go r.followStages(ctx, dsp)
+	}
+
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	r.stop = stop
+
+	reqCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	go r.drain(ctx, reqCtx, cancelRequests)
+
+	err = dsp.Do(runCtx, maxIter, r.iterate(runCtx, reqCtx))
 	runDuration := time.Since(r.start)
 
+	if err == context.Canceled && ctx.Err() == nil {
+		// the run was stopped early by the Requester itself
+		err = nil
+	}
+
 	switch err {
 	case nil, context.DeadlineExceeded:
 		r.end(err)
@@ -97,90 +223,233 @@ func (r *Requester) Run(ctx context.Context, req *http.Request) (Benchmark, erro
 		return Benchmark{}, err
 	}
 
-	return newReport(r.records, r.numErr, runDuration), errRun
+	// merge the records and statistics of the workers
+	summary := newSummary(r.config.BucketInterval)
+	var tls tlsCounter
+	for _, w := range r.workers.list() {
+		r.flushRecords(w)
+		summary.merge(w.summary)
+		tls.merge(&w.tls)
+	}
+	summary.series.merge(&r.series)
+
+	if err := r.records.close(); err != nil && r.recordsErr == nil {
+		r.recordsErr = err
+	}
+	if r.recordsErr != nil {
+		return Benchmark{}, fmt.Errorf("%w: %s", ErrRecords, r.recordsErr)
+	}
+
+	var records []Record
+	var recordsDir string
+	switch sink := r.records.(type) {
+	case *memorySink:
+		records = sink.records
+	case *diskSink:
+		recordsDir = sink.dir
+	}
+
+	bk := newReport(summary, records, dsp.Dropped(), dsp.Late(), runDuration)
+	bk.RecordsDir = recordsDir
+	bk.Checks = checkResults(r.endpoints, summary)
+	bk.Seed = r.seed
+	bk.Connections = r.transports.numOpened() - numPrevConn
+	bk.TLS = tls.params()
+	if r.config.Warmup.Report && r.config.Warmup.enabled() {
+		bk.Warmup = &warmup
+	}
+	return bk, errRun
+}
+
+// drain cancels the requests context reqCtx once the run context ctx
+// is done, after waiting up to the configured drain duration for
+// the in-flight requests to end. It returns early if reqCtx is done,
+// i.e. the run ended normally.
+func (r *Requester) drain(ctx, reqCtx context.Context, cancelRequests context.CancelFunc) {
+	select {
+	case <-ctx.Done():
+	case <-reqCtx.Done():
+		return
+	}
+
+	if r.config.Drain > 0 {
+		timer := time.NewTimer(r.config.Drain)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-reqCtx.Done():
+			return
+		}
+	}
+	cancelRequests()
 }
 
-func (r *Requester) ping(req *http.Request) error {
-	client := newClient(r.newTransport(), r.config.RequestTimeout)
-	resp, err := client.Do(req)
-	if resp != nil {
-		resp.Body.Close()
+// timeout returns the maximum duration of the run: the global timeout,
+// or the total duration of the stages if they are set and shorter.
+func (r *Requester) timeout() time.Duration {
+	timeout := r.config.GlobalTimeout
+	if d := stages(r.config.Stages).duration(); d > 0 && d < timeout {
+		timeout = d
 	}
-	client.CloseIdleConnections()
-	return err
+	return timeout
 }
 
 // Record is the summary of a HTTP response. If Record.Error is not
 // empty string, the HTTP call failed somewhere between sending the request
 // to decoding the response body. In that cas invalidating the entire response,
 // as it is not a remote server error.
+// Record.ErrorKind is the class of the error, e.g. ErrorKindTimeout,
+// or ErrorKindCanceled for a canceled Record.
+// Record.Failed lists the names of the checks the response did not pass.
+// Record.Timing is the duration of each phase of the request.
+// Record.Protocol is the protocol of the response, e.g. "HTTP/2.0".
+// Record.Start is the offset of the start of the request from the start
+// of the run.
+// Record.Canceled is true if the request was cut short by the end
+// of the run, in which case it is neither a success nor a failure.
+// Record.Endpoint and Record.Step are the indexes of the endpoint and
+// of the scenario step the request was sent by.
+// A Record is successful if it has no Error and no Failed checks.
 type Record struct {
-	Time   time.Duration `json:"time"`
-	Code   int           `json:"code"`
-	Bytes  int           `json:"bytes"`
-	Error  string        `json:"error,omitempty"`
-	Events []Event       `json:"events"`
+	Start     time.Duration `json:"start"`
+	Time      time.Duration `json:"time"`
+	Code      int           `json:"code"`
+	Bytes     int           `json:"bytes"`
+	Error     string        `json:"error,omitempty"`
+	ErrorKind string        `json:"errorKind,omitempty"`
+	Failed    []string      `json:"failed,omitempty"`
+	Events    []Event       `json:"events"`
+	Timing    Timing        `json:"timing"`
+	Protocol  string        `json:"protocol,omitempty"`
+	Canceled  bool          `json:"canceled,omitempty"`
+	Stage     int           `json:"stage"`
+	Endpoint  int           `json:"endpoint"`
+	Step      int           `json:"step"`
+}
+
+// failed returns true if the Record has an Error or failed checks.
+func (rec Record) failed() bool {
+	return rec.Error != "" || len(rec.Failed) > 0
 }
 
-func (r *Requester) record(req *http.Request, interval time.Duration) func() {
+// iterate returns the function run by the dispatcher for each iteration:
+// it runs the steps of an endpoint picked by weight sequentially,
+// then pauses for Config.Interval, or waits for the start slot of each
+// step with a global interval. The requests are sent with reqCtx.
+// No step is run and no pause lasts once the run context ctx is done.
+func (r *Requester) iterate(ctx, reqCtx context.Context) func() {
+	interval := r.config.Interval
+	global := interval.global()
+
 	return func() {
-		// We need new client and request instances each call to this function
-		// to make it safe for concurrent use.
-		client := newClient(r.newTransport(), r.config.RequestTimeout)
-		newReq := cloneRequest(req)
+		stage := r.currentStage()
+		w := r.workers.acquire()
+		iteration := int(atomic.AddInt64(&r.iter, 1) - 1)
+		rc := &renderContext{
+			vars:      map[string]string{},
+			iteration: iteration,
+			worker:    w.id,
+			rand:      newRNG(r.seed, iteration),
+		}
 
-		// Send request
-		resp, err := client.Do(newReq)
-		if err != nil {
-			r.appendRecord(Record{Error: recordErr(err)})
+		row, ok := r.feeder.next(iteration, w.id, rc.rand)
+		if !ok {
+			// the data ran out
+			r.workers.release(w)
+			r.stop()
 			return
 		}
+		rc.row = row
 
-		// Read and close response body
-		body, err := readClose(resp)
-		if err != nil {
-			r.appendRecord(Record{Error: recordErr(err)})
-			return
+		e := r.weights.pick(rc.rand)
+
+		for i, s := range r.endpoints[e].steps {
+			if i > 0 && ctx.Err() != nil {
+				break
+			}
+			if global && !r.pacer.wait(ctx, interval.pause(rc.rand)) {
+				break
+			}
+			start := time.Since(r.start)
+			rec, ok := r.do(reqCtx, w, s, rc)
+			rec.Start, rec.Stage, rec.Endpoint, rec.Step = start, stage, e, i
+			r.addRecord(w, rec)
+			if !ok {
+				break
+			}
 		}
 
-		// Retrieve tracer events and append BodyRead event
-		events := []Event{}
-		if reqtracer, ok := client.Transport.(*tracer); ok {
-			reqtracer.addEventBodyRead()
-			events = reqtracer.events
+		r.workers.release(w)
+		atomic.AddInt64(&r.numIter, 1)
+
+		if !global {
+			sleep(ctx, interval.pause(rc.rand))
 		}
+	}
+}
 
-		r.appendRecord(Record{
-			Code:   resp.StatusCode,
-			Time:   eventsTotalTime(events),
-			Bytes:  len(body),
-			Events: events,
-		})
+// sleep pauses the current goroutine for duration d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
 
-		r.printState()
-		time.Sleep(interval)
+// addRecord adds rec to the records of w, flushing them to the records
+// sink once its buffer is full. The time buckets of w are moved to the
+// Requester once w starts a later one, the start of its records never
+// decreasing, so that w only keeps its current bucket.
+func (r *Requester) addRecord(w *worker, rec Record) {
+	w.summary.add(rec)
+	if n := len(w.summary.series.buckets); n > 1 {
+		r.flushSeries(w, n-1)
+	}
+	w.records = append(w.records, rec)
+	if len(w.records) >= recordsBufferSize {
+		r.flushRecords(w)
 	}
 }
 
-func (r *Requester) appendRecord(rec Record) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.records = append(r.records, rec)
-	if rec.Error != "" {
-		r.numErr++
+// flushSeries moves the n first time buckets of w to the Requester.
+func (r *Requester) flushSeries(w *worker, n int) {
+	buckets := w.summary.series.take(n)
+	r.seriesMu.Lock()
+	defer r.seriesMu.Unlock()
+	r.series.merge(&buckets)
+}
+
+// flushRecords adds the records buffered by w to the records sink.
+func (r *Requester) flushRecords(w *worker) {
+	r.recordsMu.Lock()
+	defer r.recordsMu.Unlock()
+	for _, rec := range w.records {
+		if err := r.records.add(rec); err != nil && r.recordsErr == nil {
+			r.recordsErr = err
+		}
 	}
+	w.records = w.records[:0]
 }
 
+// refreshInterval is the interval between two prints of the state
+// of the run.
+const refreshInterval = 100 * time.Millisecond
+
 func (r *Requester) refreshState() {
-	ticker := time.NewTicker(time.Second)
-	tick := ticker.C
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
 	for {
-		if r.done {
-			ticker.Stop()
-			break
+		state := r.state()
+		if state.done {
+			return
 		}
-		r.printState()
-		<-tick
+		fmt.Print(state)
+		<-ticker.C
 	}
 }
 
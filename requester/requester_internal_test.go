@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -118,6 +122,34 @@ func TestRun(t *testing.T) {
 		t.Log(rep)
 	})
 
+	t.Run("use rate and report dropped requests", func(t *testing.T) {
+		const requests = 10
+
+		r := withCallbackTransport(New(Config{
+			Requests:       requests,
+			Concurrency:    1,
+			Rate:           100,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Silent:         true,
+		}), func() {
+			time.Sleep(25 * time.Millisecond)
+		})
+
+		rep, err := r.Run(context.Background(), validRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if rep.Dropped == 0 {
+			t.Error("unexpected Report.Dropped: exp > 0, got 0")
+		}
+
+		if got := rep.Length + rep.Dropped; got != requests {
+			t.Errorf("unexpected Report.Length + Report.Dropped: exp %d, got %d", requests, got)
+		}
+	})
+
 	t.Run("use interval", func(t *testing.T) {
 		const (
 			requests    = 12
@@ -143,7 +175,7 @@ func TestRun(t *testing.T) {
 		cfg := Config{
 			Concurrency:   concurrency,
 			Requests:      requests,
-			Interval:      interval,
+			Interval:      Interval{Duration: interval},
 			GlobalTimeout: 5 * time.Second,
 		}
 
@@ -199,8 +231,157 @@ func TestRun(t *testing.T) {
 	})
 }
 
+func TestRun_drain(t *testing.T) {
+	t.Run("cancel in-flight requests at the end of the run", func(t *testing.T) {
+		r := withSlowTransport(New(Config{
+			Requests:       -1,
+			Concurrency:    2,
+			RequestTimeout: 5 * time.Second,
+			GlobalTimeout:  100 * time.Millisecond,
+			Silent:         true,
+		}), 2*time.Second)
+
+		start := time.Now()
+		rep, err := r.Run(context.Background(), validRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("exp run to end on global timeout, lasted %v", elapsed)
+		}
+		if rep.Canceled != 2 {
+			t.Errorf("unexpected Report.Canceled: exp 2, got %d", rep.Canceled)
+		}
+		if rep.Length != 0 || rep.Fail != 0 {
+			t.Errorf("exp canceled requests not counted, got Length %d, Fail %d", rep.Length, rep.Fail)
+		}
+	})
+
+	t.Run("wait for in-flight requests up to drain", func(t *testing.T) {
+		r := withSlowTransport(New(Config{
+			Requests:       -1,
+			Concurrency:    2,
+			RequestTimeout: 5 * time.Second,
+			GlobalTimeout:  100 * time.Millisecond,
+			Drain:          2 * time.Second,
+			Silent:         true,
+		}), 300*time.Millisecond)
+
+		start := time.Now()
+		rep, err := r.Run(context.Background(), validRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("exp run to end when requests are done, lasted %v", elapsed)
+		}
+		if rep.Canceled != 0 {
+			t.Errorf("unexpected Report.Canceled: exp 0, got %d", rep.Canceled)
+		}
+		if rep.Success != 2 {
+			t.Errorf("unexpected Report.Success: exp 2, got %d", rep.Success)
+		}
+	})
+}
+
+// BenchmarkRequester_do measures the overhead of the runner for a request
+// and its record, the response being returned by an in-memory transport.
+func BenchmarkRequester_do(b *testing.B) {
+	r := New(Config{RequestTimeout: time.Second})
+	r.newTransport = func(int, connOptions) http.RoundTripper {
+		return newTracer(staticTransport{})
+	}
+	steps, err := compileSteps([]Step{{Request: validRequest()}}, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	w := newWorker(0, 0)
+	rc := &renderContext{vars: map[string]string{}, rand: newRNG(1, 0)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rec, _ := r.do(context.Background(), w, steps[0], rc)
+		w.summary.add(rec)
+	}
+}
+
+// BenchmarkRun measures the overhead of the runner per request of a run
+// against a local server, with each record storage.
+func BenchmarkRun(b *testing.B) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello")) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	for _, storage := range []string{StorageMemory, StorageDisk} {
+		b.Run(storage, func(b *testing.B) {
+			concurrency := 8
+			if b.N < concurrency {
+				concurrency = b.N
+			}
+			r := New(Config{
+				Requests:       b.N,
+				Concurrency:    concurrency,
+				RequestTimeout: time.Second,
+				GlobalTimeout:  time.Minute,
+				Records:        Records{Storage: storage, Dir: b.TempDir()},
+				Silent:         true,
+			})
+			req, _ := http.NewRequest("GET", srv.URL, nil)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			if _, err := r.Run(context.Background(), req); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
+
 // helpers
 
+// staticTransport responds to every request with a small static body.
+type staticTransport struct{}
+
+func (staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: 200,
+		Proto:      "HTTP/1.1",
+		Body:       io.NopCloser(strings.NewReader("hello")),
+		Request:    req,
+	}, nil
+}
+
+// slowTransport responds after delay or fails when the request context
+// is done. The first request, sent by the ping, is responded immediately.
+type slowTransport struct {
+	delay time.Duration
+	count *int32
+}
+
+func (t slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(t.count, 1) == 1 {
+		return &http.Response{Body: http.NoBody}, nil
+	}
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case <-time.After(t.delay):
+		return &http.Response{Body: http.NoBody}, nil
+	}
+}
+
+func withSlowTransport(req *Requester, delay time.Duration) *Requester {
+	count := new(int32)
+	req.newTransport = func(int, connOptions) http.RoundTripper {
+		return slowTransport{delay: delay, count: count}
+	}
+	return req
+}
+
 type callbackTransport struct{ callback func() }
 
 func (t callbackTransport) RoundTrip(*http.Request) (*http.Response, error) {
@@ -209,7 +390,7 @@ func (t callbackTransport) RoundTrip(*http.Request) (*http.Response, error) {
 }
 
 func withCallbackTransport(req *Requester, callback func()) *Requester {
-	req.newTransport = func() http.RoundTripper {
+	req.newTransport = func(int, connOptions) http.RoundTripper {
 		return callbackTransport{callback: callback}
 	}
 	return req
@@ -226,7 +407,7 @@ func (errTransport) RoundTrip(*http.Request) (*http.Response, error) {
 }
 
 func withErrTransport(req *Requester) *Requester {
-	req.newTransport = func() http.RoundTripper {
+	req.newTransport = func(int, connOptions) http.RoundTripper {
 		return errTransport{}
 	}
 	return req
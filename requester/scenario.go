@@ -0,0 +1,283 @@
+package requester
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// Extract sources.
+const (
+	// ExtractJSON extracts the value found at the JSON path Key
+	// in the response body. Strings are extracted unquoted, other values
+	// are JSON-encoded.
+	ExtractJSON = "json"
+	// ExtractHeader extracts the first value of the response header Key.
+	ExtractHeader = "header"
+	// ExtractRegexp extracts the first match of the regular expression Key
+	// in the response body, or its first capturing group if any.
+	ExtractRegexp = "regexp"
+)
+
+// Step is a single request of a scenario. Its URL path and query, header
+// values and body may contain placeholders "${name}", replaced by the values
+// extracted by the previous steps of the same iteration, or by dynamic
+// values (see template).
+// Checks are run in addition to the Checks of the Requester config.
+// Protocol is the protocol the request is sent with (see ProtocolAuto),
+// an empty Protocol being read as ProtocolAuto.
+// TLS is the TLS configuration of the request, nil meaning the default one.
+// Proxy is the proxy the request is sent through, nil meaning the proxy
+// set by the environment variables HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// if any, for protocols other than ProtocolHTTP2 and ProtocolH2C.
+// DNS holds the options of the resolution of the host names, nil meaning
+// the system resolver for every new connection.
+// UnixSocket is the path of the Unix socket the request is sent to
+// instead of the address of its URL, Proxy and DNS being ignored.
+// The DNS phase of its Timing is then always 0.
+// StreamBody sends the body of Request as is, read from its GetBody for
+// each request, rather than as a template. It is meant for bodies that
+// are not text, such as files.
+// Steps sharing the same *tls.Config, *Proxy and *DNS share their
+// connections.
+type Step struct {
+	Name       string
+	Request    *http.Request
+	Protocol   string
+	TLS        *tls.Config
+	Proxy      *Proxy
+	DNS        *DNS
+	UnixSocket string
+	StreamBody bool
+	Checks     []Check
+	Extract    []Extract
+}
+
+// Extract describes a value to extract from a response into the variable
+// Name. A response from which the value cannot be extracted is counted
+// as a failure and "extract <Name>" is added to its Record.Failed.
+type Extract struct {
+	Name   string
+	Source string
+	Key    string
+}
+
+// step is a compiled Step.
+// The response body is only kept in memory if readBody is true,
+// i.e. if a checker or an extractor reads it.
+type step struct {
+	request    requestTemplate
+	conn       connOptions
+	checkers   []checker
+	extractors []extractor
+	readBody   bool
+}
+
+// extractor is a compiled Extract.
+type extractor struct {
+	name     string
+	extract  func(resp *http.Response, body []byte, parsed *jsonBody) (string, bool)
+	readBody bool // whether extract reads the response body
+}
+
+// compileSteps returns the compiled steps, each of them running the checks
+// global in addition to its own, or the first non-nil error occurring
+// in the process.
+func compileSteps(steps []Step, global []Check) ([]step, error) {
+	globalCheckers, err := compileChecks(global)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := make([]step, len(steps))
+	for i, s := range steps {
+		stepCheckers, err := compileChecks(s.Checks)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i].checkers = append(append([]checker{}, globalCheckers...), stepCheckers...)
+
+		for _, e := range s.Extract {
+			extract, err := compileExtract(e)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %s", ErrInvalidExtract, e.Name, err)
+			}
+			compiled[i].extractors = append(compiled[i].extractors, extractor{
+				name:     e.Name,
+				extract:  extract,
+				readBody: e.Source != ExtractHeader,
+			})
+		}
+
+		for _, c := range compiled[i].checkers {
+			compiled[i].readBody = compiled[i].readBody || c.readBody
+		}
+		for _, e := range compiled[i].extractors {
+			compiled[i].readBody = compiled[i].readBody || e.readBody
+		}
+
+		compiled[i].conn = connOptions{
+			protocol:   s.Protocol,
+			tls:        s.TLS,
+			proxy:      s.Proxy,
+			dns:        s.DNS,
+			unixSocket: s.UnixSocket,
+		}
+		if compiled[i].request, err = newRequestTemplate(s.Request, s.StreamBody); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTemplate, err)
+		}
+	}
+	return compiled, nil
+}
+
+// compileExtract returns the extract function for e according to its source.
+func compileExtract(e Extract) (func(*http.Response, []byte, *jsonBody) (string, bool), error) {
+	switch e.Source {
+	case ExtractJSON:
+		path := jsonPath(e.Key)
+		return func(_ *http.Response, _ []byte, parsed *jsonBody) (string, bool) {
+			v, err := parsed.get()
+			if err != nil {
+				return "", false
+			}
+			got, ok := lookupJSON(v, path)
+			if !ok {
+				return "", false
+			}
+			if s, ok := got.(string); ok {
+				return s, true
+			}
+			b, err := json.Marshal(got)
+			return string(b), err == nil
+		}, nil
+
+	case ExtractHeader:
+		key := http.CanonicalHeaderKey(e.Key)
+		return func(resp *http.Response, _ []byte, _ *jsonBody) (string, bool) {
+			values := resp.Header[key]
+			if len(values) == 0 {
+				return "", false
+			}
+			return values[0], true
+		}, nil
+
+	case ExtractRegexp:
+		re, err := regexp.Compile(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ *http.Response, body []byte, _ *jsonBody) (string, bool) {
+			match := re.FindSubmatch(body)
+			switch len(match) {
+			case 0:
+				return "", false
+			case 1:
+				return string(match[0]), true
+			}
+			return string(match[1]), true
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown extract source %q", e.Source)
+}
+
+// do sends the request of the step rendered with rc with context ctx
+// using the client of worker w, and returns the resulting Record.
+// If ctx is done before the response is read, the Record is marked
+// as canceled. The values extracted from the response are added
+// to rc.vars. It returns false if the request or an extraction failed,
+// in which case the next steps of the iteration must not be run.
+// The response body is only kept in memory if the step reads it,
+// else it is drained and only its size is recorded.
+func (r *Requester) do(ctx context.Context, w *worker, s step, rc *renderContext) (Record, bool) {
+	client := r.client(w, s.conn)
+	reqtracer, traced := client.Transport.(*tracer)
+	if traced {
+		reqtracer.reset()
+	}
+	req := s.request.build(rc).WithContext(ctx)
+
+	// Send request
+	resp, err := client.Do(req)
+	if err != nil {
+		// events of the failed request may still be recorded
+		// by its tracer, so it is not reused
+		delete(w.clients, s.conn)
+		return errRecord(ctx, err, ErrorKindOther), false
+	}
+
+	// Read or drain and close response body
+	var body []byte
+	var size int64
+	if s.readBody {
+		body, err = readClose(resp)
+		size = int64(len(body))
+	} else {
+		size, err = drainClose(resp)
+	}
+	if err != nil {
+		delete(w.clients, s.conn)
+		return errRecord(ctx, err, ErrorKindBodyRead), false
+	}
+	w.tls.add(resp.TLS)
+
+	// Retrieve tracer events and timing after appending BodyRead event
+	events := []Event{}
+	var timing Timing
+	if traced {
+		reqtracer.addEventBodyRead()
+		events, timing = reqtracer.results()
+	}
+
+	var failed []string
+	ok := true
+	if len(s.checkers) > 0 || len(s.extractors) > 0 {
+		parsed := &jsonBody{raw: body}
+		failed = runChecks(s.checkers, resp, body, parsed)
+
+		for _, e := range s.extractors {
+			v, extracted := e.extract(resp, body, parsed)
+			if !extracted {
+				failed = append(failed, "extract "+e.name)
+				ok = false
+				continue
+			}
+			rc.vars[e.name] = v
+		}
+	}
+
+	return Record{
+		Code:     resp.StatusCode,
+		Time:     eventsTotalTime(events),
+		Bytes:    int(size),
+		Failed:   failed,
+		Events:   events,
+		Timing:   timing,
+		Protocol: resp.Proto,
+	}, ok
+}
+
+// client returns the client of worker w for the connection options opts,
+// creating it if needed. It is reused for the next requests of w, which
+// are sent one at a time.
+func (r *Requester) client(w *worker, opts connOptions) *http.Client {
+	client, ok := w.clients[opts]
+	if !ok {
+		client = newClient(r.newTransport(w.id, opts), r.config.RequestTimeout)
+		w.clients[opts] = client
+	}
+	return client
+}
+
+// errRecord returns the Record of a request that failed with err,
+// or a canceled Record if ctx is done. The kind of the error is
+// fallback if it cannot be determined from err.
+func errRecord(ctx context.Context, err error, fallback string) Record {
+	if ctx.Err() != nil {
+		return Record{Canceled: true, ErrorKind: ErrorKindCanceled}
+	}
+	return Record{Error: recordErr(err), ErrorKind: errorKind(err, fallback)}
+}
@@ -0,0 +1,214 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunScenario(t *testing.T) {
+	t.Run("pass extracted values to the next steps", func(t *testing.T) {
+		transport := &routeTransport{routes: map[string]routeResponse{
+			"/login": {
+				header: http.Header{"Set-Cookie": []string{"session=s1"}},
+				body:   `{"data": {"token": "t0k3n", "id": 42}}`,
+			},
+			"/users/42": {body: "hello"},
+		}}
+
+		r := New(Config{
+			Requests:       2,
+			Concurrency:    1,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Checks:         []Check{{Name: "ok status", Kind: CheckStatus, Value: "2xx"}},
+			Silent:         true,
+		})
+		r.newTransport = func(int, connOptions) http.RoundTripper { return transport }
+
+		login, _ := http.NewRequest("POST", "http://a.b/login", nil)
+		profile, _ := http.NewRequest("GET", "http://a.b/users/${id}?token=${token}", nil)
+		profile.Header.Set("Cookie", "${session}")
+
+		bk, err := r.RunScenario(context.Background(), []Step{
+			{
+				Name:    "login",
+				Request: login,
+				Extract: []Extract{
+					{Name: "token", Source: ExtractJSON, Key: "data.token"},
+					{Name: "id", Source: ExtractJSON, Key: "data.id"},
+					{Name: "session", Source: ExtractHeader, Key: "set-cookie"},
+				},
+			},
+			{
+				Name:    "profile",
+				Request: profile,
+				Checks:  []Check{{Name: "hello", Kind: CheckBody, Value: "hello"}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if bk.Length != 4 || bk.Fail != 0 {
+			t.Errorf("exp 4 records and 0 fails, got %d and %d", bk.Length, bk.Fail)
+		}
+
+		// first request is the ping
+		expRequests := []string{
+			"/login", "/login", "/users/42?token=t0k3n session=s1", "/login", "/users/42?token=t0k3n session=s1",
+		}
+		if !reflect.DeepEqual(transport.requests, expRequests) {
+			t.Errorf("unexpected requests:\nexp %q\ngot %q", expRequests, transport.requests)
+		}
+
+		for i, rec := range bk.Records {
+			if rec.Step != i%2 {
+				t.Errorf("record %d: exp step %d, got %d", i, i%2, rec.Step)
+			}
+		}
+
+		expChecks := []CheckResult{
+			{Name: "ok status", Pass: 4, Fail: 0},
+			{Name: "hello", Pass: 2, Fail: 0},
+		}
+		if !reflect.DeepEqual(bk.Checks, expChecks) {
+			t.Errorf("unexpected check results:\nexp %+v\ngot %+v", expChecks, bk.Checks)
+		}
+	})
+
+	t.Run("abort iteration on failed extraction", func(t *testing.T) {
+		transport := &routeTransport{routes: map[string]routeResponse{
+			"/login": {body: "no token"},
+		}}
+
+		r := New(Config{
+			Requests:       1,
+			Concurrency:    1,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Silent:         true,
+		})
+		r.newTransport = func(int, connOptions) http.RoundTripper { return transport }
+
+		login, _ := http.NewRequest("POST", "http://a.b/login", nil)
+		profile, _ := http.NewRequest("GET", "http://a.b/profile", nil)
+
+		bk, err := r.RunScenario(context.Background(), []Step{
+			{
+				Request: login,
+				Extract: []Extract{{Name: "token", Source: ExtractRegexp, Key: `token=(\w+)`}},
+			},
+			{Request: profile},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if bk.Length != 1 || bk.Fail != 1 {
+			t.Fatalf("exp 1 record and 1 fail, got %d and %d", bk.Length, bk.Fail)
+		}
+		if exp := []string{"extract token"}; !reflect.DeepEqual(bk.Records[0].Failed, exp) {
+			t.Errorf("unexpected failed checks: exp %q, got %q", exp, bk.Records[0].Failed)
+		}
+	})
+
+	t.Run("return ErrInvalidExtract early", func(t *testing.T) {
+		r := withNoopTransport(New(Config{
+			Requests:       1,
+			Concurrency:    1,
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+		}))
+
+		_, err := r.RunScenario(context.Background(), []Step{{
+			Request: validRequest(),
+			Extract: []Extract{{Name: "x", Source: ExtractRegexp, Key: "("}},
+		}})
+		if !errors.Is(err, ErrInvalidExtract) {
+			t.Errorf("exp ErrInvalidExtract, got %v", err)
+		}
+	})
+}
+
+func TestCompileExtract(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"X-Id": []string{"1", "2"}}}
+	body := []byte(`{"data": {"token": "abc", "ids": [4, 2], "ok": true}} id=7`)
+
+	testcases := []struct {
+		extract Extract
+		exp     string
+		expOK   bool
+	}{
+		{Extract{Source: ExtractJSON, Key: "data.token"}, "", false}, // invalid json
+		{Extract{Source: ExtractHeader, Key: "x-id"}, "1", true},
+		{Extract{Source: ExtractHeader, Key: "X-Missing"}, "", false},
+		{Extract{Source: ExtractRegexp, Key: `id=\d`}, "id=7", true},
+		{Extract{Source: ExtractRegexp, Key: `id=(\d)`}, "7", true},
+		{Extract{Source: ExtractRegexp, Key: `id=(\w{3})`}, "", false},
+	}
+
+	for _, tc := range testcases {
+		extract, err := compileExtract(tc.extract)
+		if err != nil {
+			t.Fatalf("%+v: unexpected error: %v", tc.extract, err)
+		}
+
+		got, ok := extract(resp, body, &jsonBody{raw: body})
+		if got != tc.exp || ok != tc.expOK {
+			t.Errorf("%+v: exp %q %v, got %q %v", tc.extract, tc.exp, tc.expOK, got, ok)
+		}
+	}
+
+	t.Run("extract json values", func(t *testing.T) {
+		body := []byte(`{"data": {"token": "abc", "ids": [4, 2], "ok": true}}`)
+		for key, exp := range map[string]string{
+			"data.token":  "abc",
+			"data.ids[1]": "2",
+			"data.ids":    "[4,2]",
+			"data.ok":     "true",
+		} {
+			extract, _ := compileExtract(Extract{Source: ExtractJSON, Key: key})
+			if got, ok := extract(nil, body, &jsonBody{raw: body}); !ok || got != exp {
+				t.Errorf("%s: exp %q, got %q (%v)", key, exp, got, ok)
+			}
+		}
+	})
+}
+
+// helpers
+
+type routeResponse struct {
+	header http.Header
+	body   string
+}
+
+// routeTransport responds with the routeResponse matching the request path,
+// or 404, and records the path, query and cookie of each request.
+type routeTransport struct {
+	routes   map[string]routeResponse
+	requests []string
+	mu       sync.Mutex
+}
+
+func (t *routeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests = append(t.requests, strings.TrimSpace(req.URL.RequestURI()+" "+req.Header.Get("Cookie")))
+	t.mu.Unlock()
+
+	route, ok := t.routes[req.URL.Path]
+	if !ok {
+		return &http.Response{StatusCode: 404, Body: http.NoBody}, nil
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     route.header,
+		Body:       io.NopCloser(strings.NewReader(route.body)),
+	}, nil
+}
@@ -0,0 +1,174 @@
+package requester
+
+import "time"
+
+// defaultBucketInterval is the duration of the time buckets of a run
+// if Config.BucketInterval is not set.
+const defaultBucketInterval = time.Second
+
+// bucketSubBits is the precision of the durations histogram of a time
+// bucket: 4 bits keep a relative error under 7% while keeping the memory
+// of each bucket low.
+const bucketSubBits = 4
+
+// maxBuckets is the maximum number of time buckets of a series. Once
+// a record starts past the last of them, adjacent buckets are merged
+// and the interval doubled, so that the memory used by a series does
+// not grow with the duration of the run.
+const maxBuckets = 1000
+
+// bucketSummary accumulates the statistics of the records started
+// during an interval of the run.
+type bucketSummary struct {
+	groupSummary
+	statuses map[int]int // responses of each status code
+}
+
+func newBucketSummary() bucketSummary {
+	return bucketSummary{
+		groupSummary: groupSummary{times: histogram{subBits: bucketSubBits}},
+		statuses:     map[int]int{},
+	}
+}
+
+// merge adds the statistics of other to b.
+func (b *bucketSummary) merge(other *bucketSummary) {
+	b.times.merge(&other.times)
+	b.length += other.length
+	b.fail += other.fail
+	for code, n := range other.statuses {
+		b.statuses[code] += n
+	}
+}
+
+// series accumulates the statistics of records in buckets of interval,
+// the bucket of index i holding the records started from i*interval
+// to (i+1)*interval. Only the buckets from index first are kept:
+// buckets[j] is the bucket of index first+j. The interval is doubled
+// each time a record starts past maxBuckets buckets.
+type series struct {
+	interval time.Duration
+	first    int
+	buckets  []bucketSummary
+}
+
+// newSeries returns a series of buckets of interval, or of
+// defaultBucketInterval if interval is not positive.
+func newSeries(interval time.Duration) series {
+	if interval <= 0 {
+		interval = defaultBucketInterval
+	}
+	return series{interval: interval}
+}
+
+// bucket returns the bucket of the records started at start, adding it
+// and the buckets between it and the kept ones if needed.
+func (s *series) bucket(start time.Duration) *bucketSummary {
+	if start < 0 {
+		start = 0
+	}
+	for start/s.interval >= maxBuckets {
+		s.coarsen()
+	}
+	i := int(start / s.interval)
+
+	if len(s.buckets) == 0 {
+		s.first = i
+	}
+	if i < s.first {
+		buckets := make([]bucketSummary, 0, s.first-i+len(s.buckets))
+		for j := i; j < s.first; j++ {
+			buckets = append(buckets, newBucketSummary())
+		}
+		s.buckets = append(buckets, s.buckets...)
+		s.first = i
+	}
+	for len(s.buckets) <= i-s.first {
+		s.buckets = append(s.buckets, newBucketSummary())
+	}
+	return &s.buckets[i-s.first]
+}
+
+// add accumulates the statistics of rec in the bucket of its start.
+// Canceled records are ignored.
+func (s *series) add(rec Record) {
+	if rec.Canceled {
+		return
+	}
+	b := s.bucket(rec.Start)
+	b.times.record(rec.Time)
+	b.length++
+	if rec.failed() {
+		b.fail++
+	}
+	if rec.Code != 0 {
+		b.statuses[rec.Code]++
+	}
+}
+
+// merge adds the statistics of the buckets of other to the buckets
+// of s covering the same time, s being coarsened first if other has
+// a longer interval. The intervals of both must derive from the same
+// initial one.
+func (s *series) merge(other *series) {
+	for s.interval < other.interval {
+		s.coarsen()
+	}
+	for j := range other.buckets {
+		start := time.Duration(other.first+j) * other.interval
+		s.bucket(start).merge(&other.buckets[j])
+	}
+}
+
+// coarsen merges the buckets of s pairwise, doubling its interval.
+func (s *series) coarsen() {
+	first := s.first / 2
+	buckets := make([]bucketSummary, 0, len(s.buckets)/2+1)
+	for j := range s.buckets {
+		i := (s.first+j)/2 - first
+		if i == len(buckets) {
+			buckets = append(buckets, newBucketSummary())
+		}
+		buckets[i].merge(&s.buckets[j])
+	}
+	s.interval *= 2
+	s.first, s.buckets = first, buckets
+}
+
+// take removes the n first kept buckets of s and returns them
+// as a series.
+func (s *series) take(n int) series {
+	taken := series{interval: s.interval, first: s.first, buckets: s.buckets[:n:n]}
+	s.buckets = append([]bucketSummary(nil), s.buckets[n:]...)
+	s.first += n
+	return taken
+}
+
+// timeBuckets returns the stats of the buckets of s from index 0,
+// d being the duration of the run, which cuts the last bucket short.
+// Buckets that have no record are left empty.
+func (s *series) timeBuckets(d time.Duration) []TimeBucket {
+	if len(s.buckets) == 0 {
+		return nil
+	}
+	buckets := make([]TimeBucket, s.first+len(s.buckets))
+	for i := range buckets {
+		start := time.Duration(i) * s.interval
+		duration := s.interval
+		if d > start && d-start < duration {
+			duration = d - start
+		}
+		tb := TimeBucket{Start: start, Duration: duration, StatusCodes: map[int]int{}}
+		if j := i - s.first; j >= 0 {
+			b := &s.buckets[j]
+			tb.Stats = newStats(&b.times)
+			tb.Length, tb.Fail = b.length, b.fail
+			tb.RPS = float64(b.length) / duration.Seconds()
+			for code, n := range b.statuses {
+				tb.StatusCodes[code] = n
+			}
+		}
+		buckets[i] = tb
+	}
+	return buckets
+}
@@ -0,0 +1,102 @@
+package requester
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSeries(t *testing.T) {
+	records := []Record{
+		{Start: 2500 * time.Millisecond, Time: 30 * time.Millisecond, Code: 500, Failed: []string{"status"}},
+		{Start: 100 * time.Millisecond, Time: 10 * time.Millisecond, Code: 200},
+		{Start: 900 * time.Millisecond, Time: 20 * time.Millisecond, Code: 200},
+		{Start: 2600 * time.Millisecond, Canceled: true},
+	}
+
+	t.Run("accumulate records by start time", func(t *testing.T) {
+		s := newSeries(0)
+		for _, rec := range records {
+			s.add(rec)
+		}
+
+		buckets := s.timeBuckets(2800 * time.Millisecond)
+		if len(buckets) != 3 {
+			t.Fatalf("exp 3 buckets, got %d", len(buckets))
+		}
+
+		exp := []struct {
+			start, duration time.Duration
+			length, fail    int
+			rps             float64
+			statuses        map[int]int
+		}{
+			{0, time.Second, 2, 0, 2, map[int]int{200: 2}},
+			{time.Second, time.Second, 0, 0, 0, map[int]int{}},
+			{2 * time.Second, 800 * time.Millisecond, 1, 1, 1.25, map[int]int{500: 1}},
+		}
+		for i, e := range exp {
+			b := buckets[i]
+			if b.Start != e.start || b.Duration != e.duration {
+				t.Errorf("bucket %d: exp interval %v+%v, got %v+%v", i, e.start, e.duration, b.Start, b.Duration)
+			}
+			if b.Length != e.length || b.Fail != e.fail || b.RPS != e.rps {
+				t.Errorf("bucket %d: exp %d requests, %d fails at %v rps, got %d, %d at %v", i, e.length, e.fail, e.rps, b.Length, b.Fail, b.RPS)
+			}
+			if !reflect.DeepEqual(b.StatusCodes, e.statuses) {
+				t.Errorf("bucket %d: exp status codes %v, got %v", i, e.statuses, b.StatusCodes)
+			}
+		}
+		if buckets[0].Max != 20*time.Millisecond {
+			t.Errorf("exp max %v in first bucket, got %v", 20*time.Millisecond, buckets[0].Max)
+		}
+	})
+
+	t.Run("merge buckets taken from another series", func(t *testing.T) {
+		exp := newSeries(0)
+		for _, rec := range records {
+			exp.add(rec)
+		}
+
+		s, other := newSeries(0), newSeries(0)
+		for _, rec := range records {
+			other.add(rec)
+		}
+		taken := other.take(2)
+		if other.first != 2 || len(other.buckets) != 1 {
+			t.Fatalf("exp bucket 2 kept, got %d buckets from %d", len(other.buckets), other.first)
+		}
+		s.merge(&other)
+		s.merge(&taken)
+
+		if got, want := s.timeBuckets(0), exp.timeBuckets(0); !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected merged buckets:\nexp %+v\ngot %+v", want, got)
+		}
+	})
+	t.Run("merge buckets to keep their number bounded", func(t *testing.T) {
+		const numRecord = 10000
+
+		s, fine := newSeries(0), newSeries(0)
+		for i := 0; i < numRecord; i++ {
+			s.add(Record{Start: time.Duration(i) * time.Second, Code: 200})
+		}
+		fine.add(Record{Start: 5 * time.Second, Code: 200})
+
+		if len(s.buckets) > maxBuckets || s.interval != 16*time.Second {
+			t.Fatalf("exp at most %d buckets of 16s, got %d of %v", maxBuckets, len(s.buckets), s.interval)
+		}
+
+		fine.merge(&s)
+		buckets := fine.timeBuckets(numRecord * time.Second)
+		if len(buckets) != 625 || fine.interval != s.interval {
+			t.Errorf("exp merged series of 625 buckets of %v, got %d of %v", s.interval, len(buckets), fine.interval)
+		}
+		total := 0
+		for _, b := range buckets {
+			total += b.Length
+		}
+		if total != numRecord+1 || buckets[0].Length != 17 {
+			t.Errorf("exp %d records with 17 in the first bucket, got %d and %d", numRecord+1, total, buckets[0].Length)
+		}
+	})
+}
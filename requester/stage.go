@@ -0,0 +1,145 @@
+package requester
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchttp/runner/dispatcher"
+)
+
+// stageTick is the interval at which the load is updated
+// while following stages.
+const stageTick = 100 * time.Millisecond
+
+// Stage is a step of a load profile. During a Stage, the load is linearly
+// ramped from the target of the previous Stage to its own target, which
+// is reached at the end of its Duration. The target is Rate if it is set,
+// Concurrency otherwise.
+//
+// For a Rate Stage, Concurrency is the maximum number of concurrent
+// requests and defaults to Config.Concurrency if not set.
+type Stage struct {
+	Duration    time.Duration
+	Concurrency int
+	Rate        int
+}
+
+// isRate returns true if the Stage targets a rate rather than
+// a concurrency.
+func (s Stage) isRate() bool {
+	return s.Rate > 0
+}
+
+// target returns the concurrency and the rate the Stage ramps to,
+// maxConcurrency being the default concurrency of a rate Stage.
+func (s Stage) target(maxConcurrency int) (concurrency, rate int) {
+	if s.isRate() {
+		concurrency = s.Concurrency
+		if concurrency < 1 {
+			concurrency = maxConcurrency
+		}
+		return concurrency, s.Rate
+	}
+	return atLeastOne(s.Concurrency), 0
+}
+
+// stages is a load profile composed of successive Stages.
+type stages []Stage
+
+// duration returns the total duration of the stages.
+func (s stages) duration() time.Duration {
+	var d time.Duration
+	for _, stage := range s {
+		d += stage.Duration
+	}
+	return d
+}
+
+// maxConcurrency returns the maximum concurrency reached during the stages,
+// defaultConcurrency being the concurrency of rate Stages with none set.
+func (s stages) maxConcurrency(defaultConcurrency int) int {
+	max := defaultConcurrency
+	for _, stage := range s {
+		if stage.Concurrency > max {
+			max = stage.Concurrency
+		}
+	}
+	return max
+}
+
+// at returns the index of the Stage running after elapsed time, along with
+// the concurrency and the rate to apply at that time. The first Stage ramps
+// from 0, the next ones from the target of the previous Stage if it is of
+// the same kind (rate or concurrency), from 0 otherwise.
+// The returned concurrency and rate are never lower than 1, except rate
+// that is 0 for concurrency Stages. maxConcurrency is used as the default
+// concurrency for rate Stages.
+func (s stages) at(elapsed time.Duration, maxConcurrency int) (index, concurrency, rate int) {
+	var start time.Duration
+	for i, stage := range s {
+		end := start + stage.Duration
+		if elapsed >= end && i != len(s)-1 {
+			start = end
+			continue
+		}
+
+		progress := math.Min(1, float64(elapsed-start)/float64(stage.Duration))
+
+		var prev Stage
+		if i > 0 && s[i-1].isRate() == stage.isRate() {
+			prev = s[i-1]
+		}
+
+		if stage.isRate() {
+			concurrency = stage.Concurrency
+			if concurrency < 1 {
+				concurrency = maxConcurrency
+			}
+			return i, concurrency, atLeastOne(interpolate(prev.Rate, stage.Rate, progress))
+		}
+		return i, atLeastOne(interpolate(prev.Concurrency, stage.Concurrency, progress)), 0
+	}
+	return 0, maxConcurrency, 0
+}
+
+// followStages updates the number of workers and the rate of dsp according
+// to the stage running at the current time, every stageTick until ctx is done.
+func (r *Requester) followStages(ctx context.Context, dsp dispatcher.Dispatcher) {
+	ticker := time.NewTicker(stageTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			index, concurrency, rate := stages(r.config.Stages).at(
+				time.Since(r.start), r.config.Concurrency,
+			)
+			atomic.StoreInt32(&r.stage, int32(index))
+			dsp.SetNumWorker(concurrency)
+			dsp.SetRate(rate)
+		}
+	}
+}
+
+// currentStage returns the index of the stage currently running.
+func (r *Requester) currentStage() int {
+	return int(atomic.LoadInt32(&r.stage))
+}
+
+// interpolate returns the value at progress (from 0 to 1) of a linear
+// ramp from a to b.
+func interpolate(a, b int, progress float64) int {
+	return int(math.Round(float64(a) + float64(b-a)*progress))
+}
+
+// atLeastOne returns n if n >= 1, 1 otherwise.
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
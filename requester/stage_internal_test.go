@@ -0,0 +1,126 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStages_at(t *testing.T) {
+	profile := stages{
+		{Duration: 10 * time.Second, Concurrency: 50}, // ramp-up
+		{Duration: 10 * time.Second, Concurrency: 50}, // plateau
+		{Duration: 10 * time.Second, Concurrency: 0},  // ramp-down
+		{Duration: 10 * time.Second, Rate: 100},       // rate ramp-up
+		{Duration: 10 * time.Second, Rate: 200, Concurrency: 5},
+	}
+
+	const maxConcurrency = 20
+
+	testcases := []struct {
+		label          string
+		elapsed        time.Duration
+		expIndex       int
+		expConcurrency int
+		expRate        int
+	}{
+		{
+			label:          "start first stage at 1",
+			elapsed:        0,
+			expIndex:       0,
+			expConcurrency: 1,
+		},
+		{
+			label:          "ramp first stage from 0",
+			elapsed:        5 * time.Second,
+			expIndex:       0,
+			expConcurrency: 25,
+		},
+		{
+			label:          "keep plateau",
+			elapsed:        15 * time.Second,
+			expIndex:       1,
+			expConcurrency: 50,
+		},
+		{
+			label:          "ramp down from previous target",
+			elapsed:        26 * time.Second,
+			expIndex:       2,
+			expConcurrency: 20,
+		},
+		{
+			label:          "ramp rate from 0 after a concurrency stage",
+			elapsed:        35 * time.Second,
+			expIndex:       3,
+			expConcurrency: maxConcurrency,
+			expRate:        50,
+		},
+		{
+			label:          "ramp rate from previous rate",
+			elapsed:        45 * time.Second,
+			expIndex:       4,
+			expConcurrency: 5,
+			expRate:        150,
+		},
+		{
+			label:          "keep last target after the end",
+			elapsed:        time.Hour,
+			expIndex:       4,
+			expConcurrency: 5,
+			expRate:        200,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.label, func(t *testing.T) {
+			index, concurrency, rate := profile.at(tc.elapsed, maxConcurrency)
+
+			if index != tc.expIndex || concurrency != tc.expConcurrency || rate != tc.expRate {
+				t.Errorf(
+					"\nexp index %d, concurrency %d, rate %d\ngot index %d, concurrency %d, rate %d",
+					tc.expIndex, tc.expConcurrency, tc.expRate,
+					index, concurrency, rate,
+				)
+			}
+		})
+	}
+}
+
+func TestRun_stages(t *testing.T) {
+	t.Run("follow stages and tag records", func(t *testing.T) {
+		const stageDuration = 3 * stageTick
+
+		r := withCallbackTransport(New(Config{
+			Requests:    -1,
+			Concurrency: 10,
+			Stages: []Stage{
+				{Duration: stageDuration, Concurrency: 2},
+				{Duration: stageDuration, Rate: 50},
+			},
+			Interval:       Interval{Duration: 5 * time.Millisecond},
+			RequestTimeout: 1 * time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Silent:         true,
+		}), func() {})
+
+		start := time.Now()
+		rep, err := r.Run(context.Background(), validRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if elapsed, max := time.Since(start), 2*stageDuration+stageTick; elapsed > max {
+			t.Errorf("run duration: exp <= %v, got %v", max, elapsed)
+		}
+
+		var numByStage [2]int
+		for _, rec := range rep.Records {
+			numByStage[rec.Stage]++
+		}
+		for i, n := range numByStage {
+			if n == 0 {
+				t.Errorf("no records tagged with stage %d", i)
+			}
+		}
+	})
+}
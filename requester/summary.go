@@ -0,0 +1,247 @@
+package requester
+
+import "time"
+
+// summary accumulates the statistics of records as they are added,
+// so that they do not need to be kept to be reported: its memory only
+// depends on the number of groups and distinct values, not on the number
+// of records.
+type summary struct {
+	times  histogram // durations of the non canceled records
+	phases phasesSummary
+
+	fail, canceled int // number of failed and canceled records
+
+	stages, steps, endpoints []groupSummary
+
+	protocols map[string]int // responses of each protocol
+	statuses  map[int]int    // responses of each status code
+
+	checked map[stepKey]int // checked records of each step
+	failed  map[string]int  // failures of each check name
+
+	errorKinds map[string]int   // records of each error kind
+	errors     map[errorKey]int // records of each error
+
+	series series // records by start time
+}
+
+// phasesSummary accumulates the durations of the phases of the records.
+type phasesSummary struct {
+	dns, connect, proxy, tls, write, wait, transfer histogram
+	length, reused                                  int
+}
+
+// groupSummary accumulates the statistics of a group of records.
+type groupSummary struct {
+	times        histogram
+	length, fail int
+}
+
+// errorKey identifies the errors of the same kind and message,
+// see errorMessage.
+type errorKey struct {
+	kind, message string
+}
+
+// maxErrorMessages is the maximum number of distinct errors counted
+// by a summary, so that its memory stays bounded if the error messages
+// vary from a request to another.
+const maxErrorMessages = 100
+
+// stepKey identifies a step by the indexes of its endpoint and its own.
+type stepKey struct {
+	endpoint, step int
+}
+
+// newSummary returns an empty summary, accumulating the records
+// in time buckets of bucketInterval.
+func newSummary(bucketInterval time.Duration) *summary {
+	return &summary{
+		protocols: map[string]int{},
+		statuses:  map[int]int{},
+		checked:   map[stepKey]int{},
+		failed:    map[string]int{},
+		series:    newSeries(bucketInterval),
+
+		errorKinds: map[string]int{},
+		errors:     map[errorKey]int{},
+	}
+}
+
+// summarize returns the summary of records, in time buckets
+// of bucketInterval.
+func summarize(records []Record, bucketInterval time.Duration) *summary {
+	s := newSummary(bucketInterval)
+	for _, rec := range records {
+		s.add(rec)
+	}
+	return s
+}
+
+// add accumulates the statistics of rec. Canceled records are only
+// counted in canceled and in the errors, as ErrorKindCanceled.
+func (s *summary) add(rec Record) {
+	if rec.ErrorKind != "" {
+		s.errorKinds[rec.ErrorKind]++
+	}
+	if rec.Error != "" {
+		s.addError(errorKey{kind: rec.ErrorKind, message: errorMessage(rec.Error)}, 1)
+	}
+
+	if rec.Canceled {
+		s.canceled++
+		return
+	}
+	s.phases.add(rec.Timing)
+	s.series.add(rec)
+	if rec.Protocol != "" {
+		s.protocols[rec.Protocol]++
+	}
+	if rec.Code != 0 {
+		s.statuses[rec.Code]++
+	}
+	if rec.failed() {
+		s.fail++
+	}
+	s.times.record(rec.Time)
+	s.stages = addToGroup(s.stages, rec.Stage, rec)
+	s.steps = addToGroup(s.steps, rec.Step, rec)
+	s.endpoints = addToGroup(s.endpoints, rec.Endpoint, rec)
+
+	if rec.Error == "" {
+		s.checked[stepKey{rec.Endpoint, rec.Step}]++
+		for _, name := range rec.Failed {
+			s.failed[name]++
+		}
+	}
+}
+
+// merge adds the statistics accumulated in other to s.
+func (s *summary) merge(other *summary) {
+	s.times.merge(&other.times)
+	s.phases.merge(&other.phases)
+	s.fail += other.fail
+	s.canceled += other.canceled
+	s.series.merge(&other.series)
+
+	s.stages = mergeGroups(s.stages, other.stages)
+	s.steps = mergeGroups(s.steps, other.steps)
+	s.endpoints = mergeGroups(s.endpoints, other.endpoints)
+
+	for protocol, n := range other.protocols {
+		s.protocols[protocol] += n
+	}
+	for code, n := range other.statuses {
+		s.statuses[code] += n
+	}
+	for key, n := range other.checked {
+		s.checked[key] += n
+	}
+	for name, n := range other.failed {
+		s.failed[name] += n
+	}
+	for kind, n := range other.errorKinds {
+		s.errorKinds[kind] += n
+	}
+	for key, n := range other.errors {
+		s.addError(key, n)
+	}
+}
+
+// addError counts n records failed with the error key. It is ignored
+// if maxErrorMessages distinct errors are already counted.
+func (s *summary) addError(key errorKey, n int) {
+	if _, ok := s.errors[key]; !ok && len(s.errors) >= maxErrorMessages {
+		return
+	}
+	s.errors[key] += n
+}
+
+// addToGroup adds rec to the group i of groups, growing groups if needed,
+// and returns the updated groups. Negative indexes are ignored.
+func addToGroup(groups []groupSummary, i int, rec Record) []groupSummary {
+	if i < 0 {
+		return groups
+	}
+	for len(groups) <= i {
+		groups = append(groups, groupSummary{})
+	}
+	g := &groups[i]
+	g.times.record(rec.Time)
+	g.length++
+	if rec.failed() {
+		g.fail++
+	}
+	return groups
+}
+
+// mergeGroups adds the statistics of the groups of other to the groups
+// with the same index of groups, growing groups if needed, and returns
+// the updated groups.
+func mergeGroups(groups, other []groupSummary) []groupSummary {
+	for len(groups) < len(other) {
+		groups = append(groups, groupSummary{})
+	}
+	for i := range other {
+		groups[i].times.merge(&other[i].times)
+		groups[i].length += other[i].length
+		groups[i].fail += other[i].fail
+	}
+	return groups
+}
+
+// groupStats returns the stats of the numGroup first groups, indexed
+// by group. Groups that have no record are left empty.
+func groupStats(groups []groupSummary, numGroup int) []GroupStats {
+	stats := make([]GroupStats, numGroup)
+	for i := range stats {
+		if i >= len(groups) {
+			break
+		}
+		g := &groups[i]
+		stats[i] = GroupStats{Stats: newStats(&g.times), Length: g.length, Fail: g.fail}
+	}
+	return stats
+}
+
+// add accumulates the durations of the phases of t. Empty timings,
+// such as the ones of failed requests, are ignored. The DNS, Connect,
+// Proxy and TLS phases are only accumulated if they occurred.
+func (p *phasesSummary) add(t Timing) {
+	if t == (Timing{}) {
+		return
+	}
+	p.length++
+	if t.Reused {
+		p.reused++
+	}
+	if t.DNS > 0 {
+		p.dns.record(t.DNS)
+	}
+	if t.Connect > 0 {
+		p.connect.record(t.Connect)
+	}
+	if t.Proxy > 0 {
+		p.proxy.record(t.Proxy)
+	}
+	if t.TLS > 0 {
+		p.tls.record(t.TLS)
+	}
+	p.write.record(t.Write)
+	p.wait.record(t.Wait)
+	p.transfer.record(t.Transfer)
+}
+
+// merge adds the durations accumulated in other to p.
+func (p *phasesSummary) merge(other *phasesSummary) {
+	p.length += other.length
+	p.reused += other.reused
+	p.dns.merge(&other.dns)
+	p.connect.merge(&other.connect)
+	p.proxy.merge(&other.proxy)
+	p.tls.merge(&other.tls)
+	p.write.merge(&other.write)
+	p.wait.merge(&other.wait)
+	p.transfer.merge(&other.transfer)
+}
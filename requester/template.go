@@ -0,0 +1,344 @@
+package requester
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// template is a string that may contain placeholders in format "${name}".
+// It is parsed once and rendered for each request with the variables
+// of the current iteration.
+//
+// Besides the variables extracted by the previous steps of a scenario,
+// the following placeholders are available:
+//
+//	${iteration}        index of the current iteration, starting at 0
+//	${worker}           index of the worker running the iteration
+//	${randInt min max}  random integer in [min, max]
+//	${randString n}     random alphanumeric string of length n
+//	${uuid}             random UUID (version 4)
+//	${timestamp}        current Unix time in seconds
+//	${env.NAME}         value of the environment variable NAME
+//	${data.COLUMN}      value of the column COLUMN of the data row
+//
+// Random values are generated from the seed of the Requester and
+// the iteration index, so that a run can be reproduced.
+type template struct {
+	parts []templatePart
+}
+
+// templatePart is either a literal string, a variable or a function
+// computing a dynamic value.
+type templatePart struct {
+	literal string
+	name    string
+	fn      func(rc *renderContext) string
+}
+
+// isVar returns true if the part is a variable or a function.
+func (p templatePart) isVar() bool {
+	return p.name != "" || p.fn != nil
+}
+
+// renderContext holds the values used to render the templates
+// of a request.
+type renderContext struct {
+	vars      map[string]string
+	row       map[string]string
+	iteration int
+	worker    int
+	rand      *rng
+}
+
+// parseTemplate parses s as a template. An unclosed placeholder is
+// kept as a literal. It returns a non-nil error if a function has
+// invalid arguments.
+func parseTemplate(s string) (template, error) {
+	var t template
+	for {
+		start := strings.Index(s, "${")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end == -1 {
+			break
+		}
+		end += start
+
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{literal: s[:start]})
+		}
+		part, err := parsePlaceholder(s[start+2 : end])
+		if err != nil {
+			return template{}, err
+		}
+		t.parts = append(t.parts, part)
+		s = s[end+1:]
+	}
+	if s != "" {
+		t.parts = append(t.parts, templatePart{literal: s})
+	}
+	return t, nil
+}
+
+// parsePlaceholder parses the content of a placeholder as a function
+// if it matches one, as a variable otherwise.
+func parsePlaceholder(in string) (templatePart, error) {
+	fields := strings.Fields(in)
+	if len(fields) == 0 {
+		return templatePart{name: in}, nil
+	}
+	name, args := fields[0], fields[1:]
+
+	if strings.HasPrefix(name, "env.") && len(args) == 0 {
+		// environment variables do not change during a run
+		return templatePart{literal: os.Getenv(strings.TrimPrefix(name, "env."))}, nil
+	}
+
+	if strings.HasPrefix(name, "data.") && len(args) == 0 {
+		column := strings.TrimPrefix(name, "data.")
+		return templatePart{fn: func(rc *renderContext) string {
+			if v, ok := rc.row[column]; ok {
+				return v
+			}
+			return "${" + in + "}"
+		}}, nil
+	}
+
+	fn, err := templateFunc(name, args)
+	if err != nil {
+		return templatePart{}, fmt.Errorf("${%s}: %s", in, err)
+	}
+	if fn == nil {
+		return templatePart{name: in}, nil
+	}
+	return templatePart{fn: fn}, nil
+}
+
+// templateFunc returns the function named name called with args,
+// or nil if no function is named name.
+func templateFunc(name string, args []string) (func(*renderContext) string, error) {
+	want := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("want %d arguments, got %d", n, len(args))
+		}
+		return nil
+	}
+
+	switch name {
+	case "iteration":
+		return func(rc *renderContext) string {
+			return strconv.Itoa(rc.iteration)
+		}, want(0)
+
+	case "worker":
+		return func(rc *renderContext) string {
+			return strconv.Itoa(rc.worker)
+		}, want(0)
+
+	case "uuid":
+		return func(rc *renderContext) string {
+			return rc.rand.uuid()
+		}, want(0)
+
+	case "timestamp":
+		return func(*renderContext) string {
+			return strconv.FormatInt(time.Now().Unix(), 10)
+		}, want(0)
+
+	case "randInt":
+		if err := want(2); err != nil {
+			return nil, err
+		}
+		min, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, err
+		}
+		max, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if max < min {
+			return nil, fmt.Errorf("max (%d) < min (%d)", max, min)
+		}
+		return func(rc *renderContext) string {
+			return strconv.Itoa(min + rc.rand.intn(max-min+1))
+		}, nil
+
+	case "randString":
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("length (%d) < 1", n)
+		}
+		return func(rc *renderContext) string {
+			return rc.rand.alphanumeric(n)
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// isStatic returns true if the template has no placeholder.
+func (t template) isStatic() bool {
+	for _, p := range t.parts {
+		if p.isVar() {
+			return false
+		}
+	}
+	return true
+}
+
+// render returns the template with its placeholders replaced by their
+// value in rc. Variables with no matching value are kept as is.
+func (t template) render(rc *renderContext) string {
+	return t.renderEscaped(rc, nil)
+}
+
+// renderEscaped is like render, except that the values of the
+// placeholders are passed through escape if it is not nil, such as
+// url.QueryEscape for the values rendered in a URL query.
+func (t template) renderEscaped(rc *renderContext, escape func(string) string) string {
+	if escape == nil {
+		escape = func(s string) string { return s }
+	}
+	var b strings.Builder
+	for _, p := range t.parts {
+		switch {
+		case p.fn != nil:
+			b.WriteString(escape(p.fn(rc)))
+		case p.name == "":
+			b.WriteString(p.literal)
+		default:
+			if v, ok := rc.vars[p.name]; ok {
+				b.WriteString(escape(v))
+			} else {
+				b.WriteString("${" + p.name + "}")
+			}
+		}
+	}
+	return b.String()
+}
+
+// maxBodyTemplateSize is the maximum size of a request body that may
+// contain placeholders. Larger bodies, as well as bodies of unknown size,
+// are sent as is and streamed for each request.
+const maxBodyTemplateSize = 1 << 20
+
+// requestTemplate is a *http.Request whose URL path and query, header
+// values and body may contain placeholders.
+type requestTemplate struct {
+	base   *http.Request
+	path   template
+	query  template
+	header map[string][]template
+	body   template
+	static bool
+
+	// streamBody is true if the body is not a template and is read
+	// from base.GetBody for each request.
+	streamBody bool
+}
+
+// newRequestTemplate returns a requestTemplate for req, or the first non-nil
+// error occurring parsing its templates or reading its body. The body is
+// streamed as is if streamBody is true.
+func newRequestTemplate(req *http.Request, streamBody bool) (requestTemplate, error) {
+	var err error
+	t := requestTemplate{
+		base:   req,
+		header: make(map[string][]template, len(req.Header)),
+	}
+
+	if t.path, err = parseTemplate(req.URL.Path); err != nil {
+		return requestTemplate{}, err
+	}
+	if t.query, err = parseTemplate(req.URL.RawQuery); err != nil {
+		return requestTemplate{}, err
+	}
+	static := t.path.isStatic() && t.query.isStatic()
+
+	for key, values := range req.Header {
+		for _, v := range values {
+			tv, err := parseTemplate(v)
+			if err != nil {
+				return requestTemplate{}, err
+			}
+			static = static && tv.isStatic()
+			t.header[key] = append(t.header[key], tv)
+		}
+	}
+
+	t.streamBody = req.GetBody != nil &&
+		(streamBody || req.ContentLength < 0 || req.ContentLength > maxBodyTemplateSize)
+
+	if req.GetBody != nil && !t.streamBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return requestTemplate{}, err
+		}
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return requestTemplate{}, err
+		}
+		if t.body, err = parseTemplate(string(b)); err != nil {
+			return requestTemplate{}, err
+		}
+		static = static && t.body.isStatic()
+	}
+
+	t.static = static
+	return t, nil
+}
+
+// build returns a new *http.Request from the template, with its
+// placeholders replaced by their value in rc.
+func (t requestTemplate) build(rc *renderContext) *http.Request {
+	if t.static {
+		return cloneRequest(t.base)
+	}
+
+	req := t.base.Clone(t.base.Context())
+	req.URL.Path = t.path.render(rc)
+	req.URL.RawPath = ""
+	req.URL.RawQuery = t.query.renderEscaped(rc, url.QueryEscape)
+
+	for key, values := range t.header {
+		rendered := make([]string, len(values))
+		for i, v := range values {
+			rendered[i] = v.render(rc)
+		}
+		req.Header[key] = rendered
+	}
+
+	switch {
+	case t.streamBody:
+		// a GetBody error leaves a nil body, failing the request
+		req.Body, _ = t.base.GetBody()
+	case t.base.GetBody != nil:
+		body := []byte(t.body.render(rc))
+		req.ContentLength = int64(len(body))
+		req.Body = http.NoBody
+		if len(body) > 0 {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	return req
+}
@@ -0,0 +1,268 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplate(t *testing.T) {
+	vars := map[string]string{"id": "42", "token": "abc"}
+
+	testcases := []struct {
+		in, exp string
+	}{
+		{in: "", exp: ""},
+		{in: "/users", exp: "/users"},
+		{in: "/users/${id}", exp: "/users/42"},
+		{in: "${token}${id}", exp: "abc42"},
+		{in: "id=${id}&t=${token}!", exp: "id=42&t=abc!"},
+		{in: "${unknown}/${id}", exp: "${unknown}/42"},
+		{in: "unclosed ${id", exp: "unclosed ${id"},
+	}
+
+	for _, tc := range testcases {
+		tmpl, err := parseTemplate(tc.in)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.in, err)
+		}
+		if got := tmpl.render(&renderContext{vars: vars}); got != tc.exp {
+			t.Errorf("%q: exp %q, got %q", tc.in, tc.exp, got)
+		}
+	}
+
+	t.Run("render dynamic values", func(t *testing.T) {
+		t.Setenv("BENCHTTP_TEST_ENV", "env value")
+
+		testcases := []struct {
+			in      string
+			pattern string
+		}{
+			{in: "${iteration}", pattern: `^12$`},
+			{in: "${worker}", pattern: `^3$`},
+			{in: "${randInt 5 7}", pattern: `^[5-7]$`},
+			{in: "${randInt -2 -2}", pattern: `^-2$`},
+			{in: "${randString 8}", pattern: `^[a-zA-Z0-9]{8}$`},
+			{in: "${uuid}", pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+			{in: "${timestamp}", pattern: `^[0-9]{10}$`},
+			{in: "${env.BENCHTTP_TEST_ENV}", pattern: `^env value$`},
+		}
+
+		for _, tc := range testcases {
+			tmpl, err := parseTemplate(tc.in)
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %v", tc.in, err)
+			}
+			if tmpl.isStatic() != strings.HasPrefix(tc.in, "${env.") {
+				t.Errorf("%q: unexpected static template", tc.in)
+			}
+
+			for i := 0; i < 10; i++ {
+				rc := &renderContext{iteration: 12, worker: 3, rand: newRNG(1, i)}
+				if got := tmpl.render(rc); !regexp.MustCompile(tc.pattern).MatchString(got) {
+					t.Errorf("%q: exp match for %s, got %q", tc.in, tc.pattern, got)
+				}
+			}
+		}
+	})
+
+	t.Run("render the same random values for the same seed and iteration", func(t *testing.T) {
+		tmpl, _ := parseTemplate("${uuid} ${randString 16} ${randInt 0 1000000}")
+
+		render := func(seed int64, iteration int) string {
+			return tmpl.render(&renderContext{rand: newRNG(seed, iteration)})
+		}
+
+		if render(1, 0) != render(1, 0) {
+			t.Error("exp same values for same seed and iteration")
+		}
+		if render(1, 0) == render(1, 1) || render(1, 0) == render(2, 0) {
+			t.Error("exp different values for different seeds or iterations")
+		}
+	})
+
+	t.Run("return an error for invalid arguments", func(t *testing.T) {
+		for _, in := range []string{
+			"${randInt 1}",
+			"${randInt a 2}",
+			"${randInt 3 2}",
+			"${randString}",
+			"${randString 0}",
+			"${uuid 4}",
+		} {
+			if _, err := parseTemplate(in); err == nil {
+				t.Errorf("%q: exp non-nil error", in)
+			}
+		}
+	})
+}
+
+func TestRequestTemplate(t *testing.T) {
+	t.Run("render url, header values and body", func(t *testing.T) {
+		req, _ := http.NewRequest(
+			"POST",
+			"http://a.b/users/${id}?token=${token}",
+			bytes.NewReader([]byte(`{"id":"${id}"}`)),
+		)
+		req.Header.Set("Authorization", "Bearer ${token}")
+
+		tmpl, err := newRequestTemplate(req, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := tmpl.build(&renderContext{vars: map[string]string{"id": "42", "token": "abc"}})
+
+		if exp := "http://a.b/users/42?token=abc"; got.URL.String() != exp {
+			t.Errorf("url: exp %q, got %q", exp, got.URL.String())
+		}
+		if exp := "Bearer abc"; got.Header.Get("Authorization") != exp {
+			t.Errorf("header: exp %q, got %q", exp, got.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(got.Body)
+		if exp := `{"id":"42"}`; string(body) != exp || got.ContentLength != int64(len(exp)) {
+			t.Errorf("body: exp %q, got %q (length %d)", exp, body, got.ContentLength)
+		}
+
+		// the original request is left untouched
+		if exp := "Bearer ${token}"; req.Header.Get("Authorization") != exp {
+			t.Errorf("original header: exp %q, got %q", exp, req.Header.Get("Authorization"))
+		}
+	})
+
+	t.Run("escape the values rendered in the query", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "http://a.b/search?q=${q}&page=1", nil)
+
+		tmpl, err := newRequestTemplate(req, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := tmpl.build(&renderContext{vars: map[string]string{"q": "a b&c=d"}})
+		if exp := "q=a+b%26c%3Dd&page=1"; got.URL.RawQuery != exp {
+			t.Errorf("query: exp %q, got %q", exp, got.URL.RawQuery)
+		}
+	})
+
+	t.Run("clone static requests", func(t *testing.T) {
+		tmpl, err := newRequestTemplate(validRequestWithBody([]byte("abc")), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tmpl.static {
+			t.Fatal("exp static template")
+		}
+
+		got := tmpl.build(&renderContext{})
+		body, _ := io.ReadAll(got.Body)
+		if string(body) != "abc" {
+			t.Errorf("body: exp %q, got %q", "abc", body)
+		}
+	})
+	t.Run("stream bodies of unknown size as is", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "http://a.b/users/${iteration}", nil)
+		numOpen := 0
+		req.ContentLength = -1
+		req.GetBody = func() (io.ReadCloser, error) {
+			numOpen++
+			return io.NopCloser(strings.NewReader("${iteration}")), nil
+		}
+		req.Body, _ = req.GetBody()
+
+		tmpl, err := newRequestTemplate(req, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tmpl.streamBody {
+			t.Fatal("exp streamed body")
+		}
+
+		for i := 0; i < 2; i++ {
+			got := tmpl.build(&renderContext{iteration: i})
+			if exp := fmt.Sprintf("/users/%d", i); got.URL.Path != exp {
+				t.Errorf("path: exp %q, got %q", exp, got.URL.Path)
+			}
+			body, _ := io.ReadAll(got.Body)
+			if exp := "${iteration}"; string(body) != exp {
+				t.Errorf("body: exp %q, got %q", exp, body)
+			}
+		}
+		if numOpen != 3 {
+			t.Errorf("exp a new body for each request, got %d bodies", numOpen)
+		}
+	})
+
+	t.Run("stream bodies marked as streamed whatever their size", func(t *testing.T) {
+		const content = "\x89PNG${iteration}"
+		req := validRequestWithBody([]byte(content))
+
+		tmpl, err := newRequestTemplate(req, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tmpl.streamBody {
+			t.Fatal("exp streamed body")
+		}
+
+		got := tmpl.build(&renderContext{iteration: 1})
+		body, _ := io.ReadAll(got.Body)
+		if string(body) != content {
+			t.Errorf("body: exp %q, got %q", content, body)
+		}
+	})
+}
+
+func TestRun_dynamicValues(t *testing.T) {
+	const (
+		numRequest  = 50
+		concurrency = 5
+	)
+
+	transport := &routeTransport{}
+	r := New(Config{
+		Requests:       numRequest,
+		Concurrency:    concurrency,
+		RequestTimeout: 1 * time.Second,
+		GlobalTimeout:  3 * time.Second,
+		Seed:           42,
+		Silent:         true,
+	})
+	r.newTransport = func(int, connOptions) http.RoundTripper { return transport }
+
+	req, _ := http.NewRequest("GET", "http://a.b/items/${iteration}?worker=${worker}", nil)
+
+	bk, err := r.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if bk.Seed != 42 {
+		t.Errorf("exp seed 42, got %d", bk.Seed)
+	}
+
+	// first request is the ping
+	seen := map[string]bool{}
+	for _, uri := range transport.requests[1:] {
+		var iteration, worker int
+		if _, err := fmt.Sscanf(uri, "/items/%d?worker=%d", &iteration, &worker); err != nil {
+			t.Fatalf("unexpected request %q: %v", uri, err)
+		}
+		if worker < 0 || worker >= concurrency {
+			t.Errorf("exp worker in [0, %d), got %d", concurrency, worker)
+		}
+		seen[strconv.Itoa(iteration)] = true
+	}
+
+	for i := 0; i < numRequest; i++ {
+		if !seen[strconv.Itoa(i)] {
+			t.Errorf("iteration %d not rendered", i)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package requester
+
+import (
+	"crypto/tls"
+	"sort"
+)
+
+// tlsVersions maps the TLS versions to their names.
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "1.0",
+	tls.VersionTLS11: "1.1",
+	tls.VersionTLS12: "1.2",
+	tls.VersionTLS13: "1.3",
+}
+
+// TLSParams are parameters negotiated with the servers over TLS,
+// and the number of responses received with them.
+type TLSParams struct {
+	Version     string `json:"version"`
+	CipherSuite string `json:"cipherSuite"`
+	Responses   int    `json:"responses"`
+}
+
+// tlsCounter counts the responses received with each TLS parameters.
+// Its zero value is ready to use. Like the rest of the state of a worker,
+// it is not safe for concurrent use.
+type tlsCounter struct {
+	counts map[[2]uint16]int // version and cipher suite
+}
+
+// add counts a response received over a connection in the given state.
+// It does nothing if state is nil, i.e. the connection is not secured.
+func (c *tlsCounter) add(state *tls.ConnectionState) {
+	if state == nil {
+		return
+	}
+	if c.counts == nil {
+		c.counts = map[[2]uint16]int{}
+	}
+	c.counts[[2]uint16{state.Version, state.CipherSuite}]++
+}
+
+// merge adds the responses counted by other to c.
+func (c *tlsCounter) merge(other *tlsCounter) {
+	if c.counts == nil {
+		c.counts = map[[2]uint16]int{}
+	}
+	for key, n := range other.counts {
+		c.counts[key] += n
+	}
+}
+
+// params returns the counted TLS parameters, the most used first.
+func (c *tlsCounter) params() []TLSParams {
+	if len(c.counts) == 0 {
+		return nil
+	}
+
+	params := make([]TLSParams, 0, len(c.counts))
+	for key, n := range c.counts {
+		version, ok := tlsVersions[key[0]]
+		if !ok {
+			version = "unknown"
+		}
+		params = append(params, TLSParams{
+			Version:     version,
+			CipherSuite: tls.CipherSuiteName(key[1]),
+			Responses:   n,
+		})
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].Responses != params[j].Responses {
+			return params[i].Responses > params[j].Responses
+		}
+		return params[i].Version+params[i].CipherSuite < params[j].Version+params[j].CipherSuite
+	})
+	return params
+}
@@ -0,0 +1,46 @@
+package requester
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTLSCounter(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.EnableHTTP2 = true
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg := &tls.Config{
+		RootCAs:      srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+	}
+
+	tr := newTransports(Connections{Pool: ConnPoolShared}, 1)
+	defer tr.closeIdle()
+
+	var counter tlsCounter
+	for _, protocol := range []string{ProtocolAuto, ProtocolHTTP1, ProtocolHTTP2} {
+		client := newClient(tr.get(0, connOptions{protocol: protocol, tls: cfg}), 0)
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", protocol, err)
+		}
+		readClose(resp) //nolint:errcheck
+		counter.add(resp.TLS)
+	}
+	counter.add(nil) // cleartext response
+
+	exp := []TLSParams{{Version: "1.2", CipherSuite: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", Responses: 3}}
+	if got := counter.params(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("exp %+v, got %+v", exp, got)
+	}
+
+	if got := (&tlsCounter{}).params(); got != nil {
+		t.Errorf("exp nil params without TLS responses, got %+v", got)
+	}
+}
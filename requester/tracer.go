@@ -1,9 +1,11 @@
 package requester
 
 import (
+	"context"
 	"crypto/tls"
 	"net/http"
 	"net/http/httptrace"
+	"sync"
 	"time"
 )
 
@@ -13,22 +15,74 @@ type Event struct {
 	Time time.Duration `json:"time"`
 }
 
+// Timing is the duration of each phase of a request. Phases that did not
+// occur, such as DNS and Connect for a reused connection, are 0.
+type Timing struct {
+	// DNS is the duration of the DNS lookup.
+	DNS time.Duration `json:"dns"`
+	// Connect is the duration of the TCP connection.
+	Connect time.Duration `json:"connect"`
+	// Proxy is the duration of the handshake opening a tunnel through
+	// the proxy, once connected to it.
+	Proxy time.Duration `json:"proxy"`
+	// TLS is the duration of the TLS handshake.
+	TLS time.Duration `json:"tls"`
+	// Write is the duration from getting a connection to having written
+	// the request.
+	Write time.Duration `json:"write"`
+	// Wait is the duration from having written the request to getting
+	// the first byte of the response (time to first byte).
+	Wait time.Duration `json:"wait"`
+	// Transfer is the duration from getting the first byte of the response
+	// to having read its body.
+	Transfer time.Duration `json:"transfer"`
+
+	// Reused is true if the request was sent on a previously used
+	// connection, WasIdle if that connection was idle.
+	Reused  bool `json:"reused"`
+	WasIdle bool `json:"wasIdle"`
+	// RemoteAddr is the address of the server the request was sent to.
+	RemoteAddr string `json:"remoteAddr"`
+}
+
 // tracer is a http.RoundTripper to be used as a http.Transport
-// that records the events of an outgoing HTTP request.
+// that records the events of an outgoing HTTP request. It can be reused
+// for the next requests sent one at a time after a call to reset,
+// its client trace being built once.
 type tracer struct {
-	start     time.Time
-	events    []Event
-	transport http.RoundTripper
+	// mu protects the fields below, as trace hooks may be called
+	// from the goroutines of the transport.
+	mu         sync.Mutex
+	start      time.Time
+	events     []Event
+	reused     bool
+	wasIdle    bool
+	remoteAddr string
+
+	transport   http.RoundTripper
+	clientTrace *httptrace.ClientTrace
 }
 
 // RoundTrip implements http.RoundTripper. It attaches the client trace
 // to the request context and calls t.transport.RoundTrip with a client
-// trace attached to the request context.
+// trace attached to the request context. The tracer itself is attached
+// as well, for the events no client trace hook exists for.
 func (t *tracer) RoundTrip(r *http.Request) (*http.Response, error) {
-	ctx := httptrace.WithClientTrace(r.Context(), t.trace())
+	ctx := httptrace.WithClientTrace(r.Context(), t.clientTrace)
+	ctx = context.WithValue(ctx, tracerKey{}, t)
 	return t.transport.RoundTrip(r.WithContext(ctx))
 }
 
+// tracerKey is the context key of the tracer of a request.
+type tracerKey struct{}
+
+// addTraceEvent adds the event name to the tracer attached to ctx, if any.
+func addTraceEvent(ctx context.Context, name string) {
+	if t, ok := ctx.Value(tracerKey{}).(*tracer); ok {
+		t.addEvent(name)
+	}
+}
+
 // CloseIdleConnections closes any connections on its http.Transport
 // which are sitting idle in a "keep-alive" state.
 //
@@ -47,14 +101,37 @@ func (t *tracer) CloseIdleConnections() {
 func (t *tracer) trace() *httptrace.ClientTrace {
 	return &httptrace.ClientTrace{
 		GetConn: func(string) {
-			t.start = time.Now()
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// keep the start of the first request in case of redirects
+			if t.start.IsZero() {
+				t.start = time.Now()
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused, t.wasIdle = info.Reused, info.WasIdle
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mu.Unlock()
+			t.addEvent("GotConn")
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.addEvent("DNSStart")
 		},
 		DNSDone: func(httptrace.DNSDoneInfo) {
 			t.addEvent("DNSDone")
 		},
+		ConnectStart: func(string, string) {
+			t.addEvent("ConnectStart")
+		},
 		ConnectDone: func(string, string, error) {
 			t.addEvent("ConnectDone")
 		},
+		TLSHandshakeStart: func() {
+			t.addEvent("TLSHandshakeStart")
+		},
 		TLSHandshakeDone: func(tls.ConnectionState, error) {
 			t.addEvent("TLSHandshakeDone")
 		},
@@ -62,6 +139,9 @@ func (t *tracer) trace() *httptrace.ClientTrace {
 		WroteHeaders: func() {
 			t.addEvent("WroteHeaders")
 		},
+		Got100Continue: func() {
+			t.addEvent("Got100Continue")
+		},
 		WroteRequest: func(httptrace.WroteRequestInfo) {
 			t.addEvent("WroteRequest")
 		},
@@ -76,6 +156,8 @@ func (t *tracer) trace() *httptrace.ClientTrace {
 
 // addEvent appends a timestamped Event to the tracer's events slice.
 func (t *tracer) addEvent(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.events = append(t.events, Event{Name: name, Time: time.Since(t.start)})
 }
 
@@ -84,12 +166,67 @@ func (t *tracer) addEventBodyRead() {
 	t.addEvent("BodyRead")
 }
 
-// newTracer returns an initialized tracer.
-func newTracer() *tracer {
-	return &tracer{
+// results returns a copy of the recorded events and the Timing
+// computed from them.
+func (t *tracer) results() ([]Event, Timing) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := make([]Event, len(t.events))
+	copy(events, t.events)
+
+	return events, Timing{
+		DNS:        between(events, "DNSStart", "DNSDone"),
+		Connect:    between(events, "ConnectStart", "ConnectDone"),
+		Proxy:      between(events, "ProxyConnectStart", "ProxyConnectDone"),
+		TLS:        between(events, "TLSHandshakeStart", "TLSHandshakeDone"),
+		Write:      between(events, "GotConn", "WroteRequest"),
+		Wait:       between(events, "WroteRequest", "GotFirstResponseByte"),
+		Transfer:   between(events, "GotFirstResponseByte", "BodyRead"),
+		Reused:     t.reused,
+		WasIdle:    t.wasIdle,
+		RemoteAddr: t.remoteAddr,
+	}
+}
+
+// reset clears the events recorded by t, so that it can trace a new
+// request. The events of the previous request must not be recorded
+// anymore, which is the case once its response body is read.
+func (t *tracer) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start = time.Time{}
+	t.events = t.events[:0]
+	t.reused, t.wasIdle, t.remoteAddr = false, false, ""
+}
+
+// newTracer returns an initialized tracer wrapping transport.
+func newTracer(transport http.RoundTripper) *tracer {
+	t := &tracer{
 		events:    make([]Event, 0, 20),
-		transport: http.DefaultTransport,
+		transport: transport,
+	}
+	t.clientTrace = t.trace()
+	return t
+}
+
+// between returns the duration between the last event named to and the
+// last event named from preceding it, or 0 if any of them is missing.
+// Using the last events keeps the phases of the last request in case
+// of redirects.
+func between(events []Event, from, to string) time.Duration {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Name != to {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if events[j].Name == from {
+				return events[i].Time - events[j].Time
+			}
+		}
+		return 0
 	}
+	return 0
 }
 
 // eventsTotalTime returns the time of the last event, or 0
@@ -2,27 +2,35 @@ package requester
 
 import (
 	"crypto/tls"
+	"net/http"
 	"net/http/httptrace"
 	"testing"
+	"time"
 )
 
 func TestTracer(t *testing.T) {
 	t.Run("append events on trace hooks", func(t *testing.T) {
-		tracer := newTracer()
+		tracer := newTracer(http.DefaultTransport)
 		trace := tracer.trace()
 
 		trace.GetConn("")
+		trace.DNSStart(httptrace.DNSStartInfo{})
 		trace.DNSDone(httptrace.DNSDoneInfo{})
+		trace.ConnectStart("", "")
 		trace.ConnectDone("", "", nil)
+		trace.TLSHandshakeStart()
 		trace.TLSHandshakeDone(tls.ConnectionState{}, nil)
+		trace.GotConn(httptrace.GotConnInfo{})
 		trace.WroteHeaders()
+		trace.Got100Continue()
 		trace.WroteRequest(httptrace.WroteRequestInfo{})
 		trace.GotFirstResponseByte()
 		trace.PutIdleConn(nil)
 
 		expEventNames := []string{
-			"DNSDone", "ConnectDone", "TLSHandshakeDone", "WroteHeaders",
-			"WroteRequest", "GotFirstResponseByte", "PutIdleConn",
+			"DNSStart", "DNSDone", "ConnectStart", "ConnectDone",
+			"TLSHandshakeStart", "TLSHandshakeDone", "GotConn", "WroteHeaders",
+			"Got100Continue", "WroteRequest", "GotFirstResponseByte", "PutIdleConn",
 		}
 		gotEvents := tracer.events
 
@@ -51,4 +59,72 @@ func TestTracer(t *testing.T) {
 
 		t.Log(tracer.events)
 	})
+
+	t.Run("compute timing from events", func(t *testing.T) {
+		tracer := &tracer{
+			events: []Event{
+				{Name: "DNSStart", Time: 1 * time.Millisecond},
+				{Name: "DNSDone", Time: 3 * time.Millisecond},
+				{Name: "ConnectStart", Time: 3 * time.Millisecond},
+				{Name: "ConnectDone", Time: 7 * time.Millisecond},
+				{Name: "ProxyConnectStart", Time: 7 * time.Millisecond},
+				{Name: "ProxyConnectDone", Time: 8 * time.Millisecond},
+				{Name: "GotConn", Time: 8 * time.Millisecond},
+				{Name: "WroteRequest", Time: 10 * time.Millisecond},
+				{Name: "GotFirstResponseByte", Time: 20 * time.Millisecond},
+				{Name: "BodyRead", Time: 25 * time.Millisecond},
+			},
+			reused:     true,
+			wasIdle:    true,
+			remoteAddr: "127.0.0.1:8080",
+		}
+
+		events, timing := tracer.results()
+		if len(events) != len(tracer.events) {
+			t.Errorf("exp %d events, got %d", len(tracer.events), len(events))
+		}
+
+		exp := Timing{
+			DNS:        2 * time.Millisecond,
+			Connect:    4 * time.Millisecond,
+			Proxy:      1 * time.Millisecond,
+			TLS:        0, // no handshake
+			Write:      2 * time.Millisecond,
+			Wait:       10 * time.Millisecond,
+			Transfer:   5 * time.Millisecond,
+			Reused:     true,
+			WasIdle:    true,
+			RemoteAddr: "127.0.0.1:8080",
+		}
+		if timing != exp {
+			t.Errorf("\nexp %+v\ngot %+v", exp, timing)
+		}
+	})
+}
+
+func TestBenchmark_PhasesStats(t *testing.T) {
+	bk := Benchmark{
+		Records: []Record{
+			{Timing: Timing{DNS: 2 * time.Millisecond, Connect: 4 * time.Millisecond, Wait: 10 * time.Millisecond}},
+			{Timing: Timing{Wait: 20 * time.Millisecond, Reused: true}},
+			{Error: "connection refused"}, // no timing
+			{Canceled: true, Timing: Timing{Wait: time.Second}},
+		},
+	}
+
+	got := bk.PhasesStats()
+
+	if got.Length != 2 || got.Reused != 1 {
+		t.Errorf("exp 1/2 reused connections, got %d/%d", got.Reused, got.Length)
+	}
+	// DNS and Connect only account for the requests they occurred for
+	if got.DNS.Mean != 2*time.Millisecond || got.Connect.Mean != 4*time.Millisecond {
+		t.Errorf("exp DNS mean 2ms and Connect mean 4ms, got %s and %s", got.DNS.Mean, got.Connect.Mean)
+	}
+	if got.TLS.Max != 0 {
+		t.Errorf("exp no TLS, got max %s", got.TLS.Max)
+	}
+	if got.Wait.Min != 10*time.Millisecond || got.Wait.Max != 20*time.Millisecond {
+		t.Errorf("exp Wait in [10ms, 20ms], got [%s, %s]", got.Wait.Min, got.Wait.Max)
+	}
 }
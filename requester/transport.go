@@ -0,0 +1,212 @@
+package requester
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Protocols.
+const (
+	// ProtocolHTTP1 forces HTTP/1.1.
+	ProtocolHTTP1 = "http1"
+	// ProtocolHTTP2 forces HTTP/2 over TLS.
+	ProtocolHTTP2 = "http2"
+	// ProtocolH2C forces HTTP/2 over cleartext TCP, with prior knowledge.
+	ProtocolH2C = "h2c"
+	// ProtocolAuto uses HTTP/2 if the server supports it over TLS,
+	// HTTP/1.1 otherwise.
+	ProtocolAuto = "auto"
+)
+
+// Connection pools.
+const (
+	// ConnPoolShared shares a single connection pool between all workers.
+	ConnPoolShared = "shared"
+	// ConnPoolPerWorker gives each worker a dedicated connection pool,
+	// so that workers never reuse each other's connections.
+	ConnPoolPerWorker = "perWorker"
+)
+
+// Connections holds the options of the connections to the servers.
+// MaxIdle is the maximum number of idle connections kept per host
+// in each pool, 0 meaning as many as the workers using the pool.
+// MaxPerHost is the maximum number of connections per host in each
+// pool, 0 meaning no limit.
+// MaxStreams is the maximum number of concurrent streams of an HTTP/2
+// connection before a new one is opened, 0 meaning the limit set by
+// the server. It only applies to protocols ProtocolHTTP2 and ProtocolH2C.
+type Connections struct {
+	DisableKeepAlive bool
+	MaxIdle          int
+	MaxPerHost       int
+	MaxStreams       int
+	Pool             string
+}
+
+// dialer is the dialer of all connections, configured as the one
+// of http.DefaultTransport.
+var dialer = &net.Dialer{
+	Timeout:   30 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
+
+// transports holds the transport of each connection pool and counts
+// the connections they open.
+type transports struct {
+	conns     Connections
+	numWorker int
+	opened    int64 // number of connections opened, accessed atomically
+
+	mu        sync.Mutex
+	pool      map[poolKey]http.RoundTripper // transport of each pool
+	resolvers map[*DNS]*resolver            // resolver of each DNS options
+}
+
+// connOptions are the options of the connections of a request.
+// An empty protocol is read as ProtocolAuto, a nil tls as the default
+// TLS configuration, a nil proxy as the proxy set by the environment,
+// a nil dns as the system resolver.
+// If unixSocket is set, the connections are made to that Unix socket
+// whatever the address of the request, proxy and dns being ignored.
+type connOptions struct {
+	protocol   string
+	tls        *tls.Config
+	proxy      *Proxy
+	dns        *DNS
+	unixSocket string
+}
+
+// poolKey identifies a connection pool: a pool is dedicated to
+// connection options and, in mode ConnPoolPerWorker, to a worker.
+type poolKey struct {
+	options connOptions
+	worker  int
+}
+
+// newTransports returns transports for conns, numWorker being the maximum
+// number of concurrent workers.
+func newTransports(conns Connections, numWorker int) *transports {
+	if numWorker < 1 {
+		numWorker = 1
+	}
+	return &transports{
+		conns:     conns,
+		numWorker: numWorker,
+		pool:      map[poolKey]http.RoundTripper{},
+		resolvers: map[*DNS]*resolver{},
+	}
+}
+
+// get returns the transport of the pool of the given worker for opts,
+// creating it if needed.
+func (t *transports) get(worker int, opts connOptions) http.RoundTripper {
+	if opts.protocol == "" {
+		opts.protocol = ProtocolAuto
+	}
+	key := poolKey{options: opts}
+	if t.conns.Pool == ConnPoolPerWorker {
+		key.worker = worker
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tr, ok := t.pool[key]
+	if !ok {
+		tr = t.newTransport(opts)
+		t.pool[key] = tr
+	}
+	return tr
+}
+
+// newTransport returns a transport for opts configured with the options
+// of t.conns, which connections are counted in t.opened.
+// The resolvers are shared by the pools with the same DNS options,
+// so that their host names are resolved once in mode DNSOnce.
+// t.mu must be held.
+func (t *transports) newTransport(opts connOptions) http.RoundTripper {
+	dial := dialFunc(t.dial)
+	if opts.unixSocket != "" {
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return t.dial(ctx, "unix", opts.unixSocket)
+		}
+	} else {
+		if opts.dns != nil {
+			r, ok := t.resolvers[opts.dns]
+			if !ok {
+				r = newResolver(*opts.dns)
+				t.resolvers[opts.dns] = r
+			}
+			dial = r.dialer(dial)
+		}
+		if opts.proxy != nil {
+			dial = opts.proxy.dialer(dial, opts.tls)
+		}
+	}
+
+	if opts.protocol == ProtocolHTTP2 || opts.protocol == ProtocolH2C {
+		return newH2Transport(t.conns, opts, dial)
+	}
+
+	maxIdle := t.conns.MaxIdle
+	if maxIdle == 0 {
+		maxIdle = t.numWorker
+		if t.conns.Pool == ConnPoolPerWorker {
+			maxIdle = 1
+		}
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.DialContext = dial
+	switch {
+	case opts.unixSocket != "":
+		tr.Proxy = nil
+	case opts.proxy != nil:
+		// the requests not forwarded to the proxy are tunneled by dial
+		tr.Proxy = opts.proxy.forward
+	}
+	if opts.tls != nil {
+		tr.TLSClientConfig = opts.tls.Clone()
+	}
+	if opts.protocol == ProtocolHTTP1 {
+		// a non-nil empty map disables HTTP/2
+		tr.ForceAttemptHTTP2 = false
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	tr.DisableKeepAlives = t.conns.DisableKeepAlive
+	tr.MaxIdleConns = 0 // no global limit, only the per host one
+	tr.MaxIdleConnsPerHost = maxIdle
+	tr.MaxConnsPerHost = t.conns.MaxPerHost
+	return tr
+}
+
+// dial connects to addr on the named network and counts the connection
+// in t.opened.
+func (t *transports) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err == nil {
+		atomic.AddInt64(&t.opened, 1)
+	}
+	return conn, err
+}
+
+// numOpened returns the number of connections opened so far.
+func (t *transports) numOpened() int {
+	return int(atomic.LoadInt64(&t.opened))
+}
+
+// closeIdle closes the idle connections of all pools.
+func (t *transports) closeIdle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	type closeIdler interface{ CloseIdleConnections() }
+	for _, tr := range t.pool {
+		if tr, ok := tr.(closeIdler); ok {
+			tr.CloseIdleConnections()
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package requester
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestTransports(t *testing.T) {
+	t.Run("share a single pool between workers", func(t *testing.T) {
+		tr := newTransports(Connections{Pool: ConnPoolShared}, 4)
+		if tr.get(0, connOptions{protocol: ProtocolAuto}) != tr.get(3, connOptions{protocol: ProtocolAuto}) {
+			t.Error("exp same transport for all workers")
+		}
+		if got := tr.get(0, connOptions{protocol: ProtocolAuto}).(*http.Transport).MaxIdleConnsPerHost; got != 4 {
+			t.Errorf("exp %d max idle connections per host, got %d", 4, got)
+		}
+	})
+
+	t.Run("dedicate a pool to each protocol", func(t *testing.T) {
+		tr := newTransports(Connections{Pool: ConnPoolShared}, 4)
+		if tr.get(0, connOptions{}) != tr.get(0, connOptions{protocol: ProtocolAuto}) {
+			t.Error("exp same transport for empty protocol and auto")
+		}
+		if tr.get(0, connOptions{protocol: ProtocolHTTP1}) == tr.get(0, connOptions{protocol: ProtocolAuto}) {
+			t.Error("exp distinct transports for distinct protocols")
+		}
+		if _, ok := tr.get(0, connOptions{protocol: ProtocolH2C}).(*h2Transport); !ok {
+			t.Errorf("exp *h2Transport for h2c, got %T", tr.get(0, connOptions{protocol: ProtocolH2C}))
+		}
+		if got := tr.get(0, connOptions{protocol: ProtocolHTTP1}).(*http.Transport).TLSNextProto; got == nil || len(got) != 0 {
+			t.Errorf("exp HTTP/2 disabled for http1, got TLSNextProto %v", got)
+		}
+	})
+
+	t.Run("dedicate a pool to each tls config", func(t *testing.T) {
+		tr := newTransports(Connections{Pool: ConnPoolShared}, 4)
+		cfg := &tls.Config{ServerName: "a.b"}
+		if tr.get(0, connOptions{tls: cfg}) != tr.get(1, connOptions{tls: cfg}) {
+			t.Error("exp same transport for the same tls config")
+		}
+		if tr.get(0, connOptions{tls: cfg}) == tr.get(0, connOptions{}) {
+			t.Error("exp distinct transports for distinct tls configs")
+		}
+		if got := tr.get(0, connOptions{tls: cfg}).(*http.Transport).TLSClientConfig; got.ServerName != "a.b" {
+			t.Errorf("exp tls server name %q, got %q", "a.b", got.ServerName)
+		}
+	})
+
+	t.Run("give each worker a dedicated pool", func(t *testing.T) {
+		tr := newTransports(Connections{Pool: ConnPoolPerWorker}, 4)
+		if tr.get(0, connOptions{protocol: ProtocolAuto}) == tr.get(3, connOptions{protocol: ProtocolAuto}) {
+			t.Error("exp distinct transports for distinct workers")
+		}
+		if tr.get(3, connOptions{protocol: ProtocolAuto}) != tr.get(3, connOptions{protocol: ProtocolAuto}) {
+			t.Error("exp same transport for the same worker")
+		}
+		if got := tr.get(0, connOptions{protocol: ProtocolAuto}).(*http.Transport).MaxIdleConnsPerHost; got != 1 {
+			t.Errorf("exp %d max idle connections per host, got %d", 1, got)
+		}
+	})
+
+	t.Run("apply connections options", func(t *testing.T) {
+		tr := newTransports(Connections{
+			DisableKeepAlive: true,
+			MaxIdle:          3,
+			MaxPerHost:       5,
+			Pool:             ConnPoolShared,
+		}, 4).get(0, connOptions{protocol: ProtocolAuto}).(*http.Transport)
+
+		if !tr.DisableKeepAlives {
+			t.Error("exp keep-alive disabled")
+		}
+		if tr.MaxIdleConnsPerHost != 3 {
+			t.Errorf("exp %d max idle connections per host, got %d", 3, tr.MaxIdleConnsPerHost)
+		}
+		if tr.MaxConnsPerHost != 5 {
+			t.Errorf("exp %d max connections per host, got %d", 5, tr.MaxConnsPerHost)
+		}
+	})
+
+	t.Run("connect to unix socket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "app.sock")
+		ln, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Host)) //nolint:errcheck
+		})
+		srv := &http.Server{Handler: h2c.NewHandler(handler, &http2.Server{})}
+		go srv.Serve(ln) //nolint:errcheck
+		defer srv.Close()
+
+		tr := newTransports(Connections{Pool: ConnPoolShared}, 1)
+		defer tr.closeIdle()
+		for _, protocol := range []string{ProtocolAuto, ProtocolH2C} {
+			tracer := newTracer(tr.get(0, connOptions{protocol: protocol, unixSocket: socket}))
+			resp, err := newClient(tracer, 0).Get("http://app.test/ping")
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", protocol, err)
+			}
+			body, _ := readClose(resp)
+			tracer.addEventBodyRead()
+
+			if string(body) != "app.test" {
+				t.Errorf("%s: exp Host %q, got %q", protocol, "app.test", body)
+			}
+			if _, timing := tracer.results(); timing.DNS != 0 || timing.Connect == 0 {
+				t.Errorf("%s: exp connect phase only, got %+v", protocol, timing)
+			}
+		}
+	})
+
+	t.Run("count opened connections", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		testcases := []struct {
+			label string
+			conns Connections
+			exp   int
+		}{
+			{
+				label: "reuse connections",
+				conns: Connections{Pool: ConnPoolShared},
+				exp:   1,
+			},
+			{
+				label: "open a connection per request without keep-alive",
+				conns: Connections{DisableKeepAlive: true, Pool: ConnPoolShared},
+				exp:   3,
+			},
+		}
+
+		for _, tc := range testcases {
+			tr := newTransports(tc.conns, 1)
+			client := newClient(tr.get(0, connOptions{protocol: ProtocolAuto}), 0)
+			for i := 0; i < 3; i++ {
+				resp, err := client.Get(srv.URL)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", tc.label, err)
+				}
+				readClose(resp) //nolint:errcheck
+			}
+			tr.closeIdle()
+
+			if got := tr.numOpened(); got != tc.exp {
+				t.Errorf("%s: exp %d opened connections, got %d", tc.label, tc.exp, got)
+			}
+		}
+	})
+}
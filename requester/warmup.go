@@ -0,0 +1,94 @@
+package requester
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/benchttp/runner/dispatcher"
+)
+
+// Warmup holds the options of the warmup phase, run before the measured
+// run with the same concurrency and rate, or at the target of the first
+// Stage if stages are set, so that it starts with warm connections
+// and server. It lasts Requests iterations or Duration,
+// none of them being set disabling it. Its records are not stored nor
+// counted in the Benchmark, its stats being reported in Benchmark.Warmup
+// if Report is true.
+type Warmup struct {
+	Requests int
+	Duration time.Duration
+	Report   bool
+}
+
+// enabled returns true if the Warmup has requests or a duration.
+func (w Warmup) enabled() bool {
+	return w.Requests > 0 || w.Duration > 0
+}
+
+// discardSink is a recordSink discarding the records, such as the ones
+// of the warmup.
+type discardSink struct{}
+
+func (discardSink) add(Record) error { return nil }
+
+func (discardSink) close() error { return nil }
+
+// warmup runs the warmup phase with numWorker workers and rate, and
+// returns its report, or a non-nil error if ctx is done before its end.
+// The connections it opens are kept for the measured run, whose workers,
+// time buckets, progress, iteration indexes and data rows start anew.
+func (r *Requester) warmup(ctx context.Context, numWorker, rate int) (Benchmark, error) {
+	opts := r.config.Warmup
+	maxIter, timeout := -1, r.timeout()
+	if opts.Requests > 0 {
+		maxIter = opts.Requests
+		if maxIter < numWorker {
+			numWorker = maxIter
+		}
+	}
+	if opts.Duration > 0 {
+		timeout = opts.Duration
+	}
+
+	records := r.records
+	r.records = discardSink{}
+	defer func() { r.records = records }()
+	numOpened := r.transports.numOpened()
+
+	warmupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	warmupCtx, stop := context.WithCancel(warmupCtx)
+	defer stop()
+	r.stop = stop
+
+	dsp := dispatcher.New(numWorker)
+	dsp.SetRate(rate)
+
+	r.start = time.Now()
+	// in-flight requests are not canceled once the warmup is done,
+	// so that their connections are kept
+	err := dsp.Do(warmupCtx, maxIter, r.iterate(warmupCtx, ctx))
+	duration := time.Since(r.start)
+	if ctx.Err() != nil {
+		return Benchmark{}, ctx.Err()
+	}
+	if err != nil && err != context.DeadlineExceeded && err != context.Canceled {
+		return Benchmark{}, err
+	}
+
+	summary := newSummary(r.config.BucketInterval)
+	for _, w := range r.workers.list() {
+		summary.merge(w.summary)
+	}
+	summary.series.merge(&r.series)
+	r.workers = newWorkers(r.numWorker(), r.config.BucketInterval)
+	r.series = newSeries(r.config.BucketInterval)
+	r.feeder = newFeeder(r.config.Data, r.numWorker())
+	atomic.StoreInt64(&r.numIter, 0)
+	atomic.StoreInt64(&r.iter, 0)
+
+	bk := newReport(summary, nil, dsp.Dropped(), dsp.Late(), duration)
+	bk.Connections = r.transports.numOpened() - numOpened
+	return bk, nil
+}
@@ -0,0 +1,123 @@
+package requester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_warmup(t *testing.T) {
+	var numReq int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&numReq, 1)
+	}))
+	defer srv.Close()
+
+	config := Config{
+		Requests:       10,
+		Concurrency:    2,
+		RequestTimeout: time.Second,
+		GlobalTimeout:  5 * time.Second,
+		Silent:         true,
+	}
+
+	t.Run("exclude warmup requests from results", func(t *testing.T) {
+		atomic.StoreInt64(&numReq, 0)
+		config := config
+		config.Warmup = Warmup{Requests: 6, Report: true}
+
+		bk, err := New(config).Run(context.Background(), mustRequest(t, srv.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 1 ping, 6 warmup requests and 10 measured requests
+		if got := atomic.LoadInt64(&numReq); got != 17 {
+			t.Errorf("exp 17 requests received, got %d", got)
+		}
+		if bk.Length != 10 || len(bk.Records) != 10 {
+			t.Errorf("exp 10 requests reported, got %d and %d records", bk.Length, len(bk.Records))
+		}
+		if bk.Warmup == nil || bk.Warmup.Length != 6 || len(bk.Warmup.Records) != 0 {
+			t.Fatalf("exp warmup report of 6 requests without records, got %+v", bk.Warmup)
+		}
+		if bk.Warmup.Connections != 2 || bk.Connections != 0 {
+			t.Errorf("exp warmup connections reused, got %d opened by warmup and %d by run", bk.Warmup.Connections, bk.Connections)
+		}
+	})
+
+	t.Run("run warmup for a duration without reporting it", func(t *testing.T) {
+		atomic.StoreInt64(&numReq, 0)
+		config := config
+		config.Warmup = Warmup{Duration: 50 * time.Millisecond}
+
+		bk, err := New(config).Run(context.Background(), mustRequest(t, srv.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := atomic.LoadInt64(&numReq); got <= 11 {
+			t.Errorf("exp warmup requests received, got %d requests", got)
+		}
+		if bk.Length != 10 || bk.Warmup != nil {
+			t.Errorf("exp 10 requests reported without warmup, got %d and %+v", bk.Length, bk.Warmup)
+		}
+	})
+	t.Run("start the measured run from the first iteration and data row", func(t *testing.T) {
+		transport := &routeTransport{}
+		r := New(Config{
+			Requests:       2,
+			Concurrency:    1,
+			RequestTimeout: time.Second,
+			GlobalTimeout:  3 * time.Second,
+			Warmup:         Warmup{Requests: 2},
+			Data: Data{
+				Rows:  []map[string]string{{"id": "a"}, {"id": "b"}},
+				Mode:  DataSequential,
+				OnEnd: DataStop,
+			},
+			Silent: true,
+		})
+		r.newTransport = func(int, connOptions) http.RoundTripper { return transport }
+
+		req, _ := http.NewRequest("GET", "http://a.b/users/${data.id}?i=${iteration}", nil)
+		bk, err := r.Run(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bk.Length != 2 {
+			t.Errorf("exp 2 requests reported, got %d", bk.Length)
+		}
+		// first request is the ping
+		exp := []string{"/users/a?i=0", "/users/b?i=1", "/users/a?i=0", "/users/b?i=1"}
+		if !reflect.DeepEqual(transport.requests[1:], exp) {
+			t.Errorf("unexpected requests:\nexp %q\ngot %q", exp, transport.requests[1:])
+		}
+	})
+	t.Run("run warmup at the target of the first stage", func(t *testing.T) {
+		slowSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(20 * time.Millisecond)
+		}))
+		defer slowSrv.Close()
+
+		config := config
+		config.Requests = -1
+		config.Concurrency = 1
+		config.Stages = []Stage{{Duration: 100 * time.Millisecond, Concurrency: 4}}
+		config.Warmup = Warmup{Duration: 100 * time.Millisecond, Report: true}
+
+		bk, err := New(config).Run(context.Background(), mustRequest(t, slowSrv.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bk.Warmup == nil || bk.Warmup.Connections != 4 {
+			t.Fatalf("exp warmup with 4 concurrent connections, got %+v", bk.Warmup)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package requester
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// recordsBufferSize is the number of records a worker buffers before
+// flushing them to the records sink, so that the sink lock is taken once
+// per batch rather than once per record.
+const recordsBufferSize = 256
+
+// worker holds the state dedicated to a worker: the clients of its requests
+// and the records and statistics of its iterations, merged with the other
+// workers' at the end of the run. A worker runs a single iteration at a time,
+// so its state is accessed without locking.
+type worker struct {
+	id      int
+	clients map[connOptions]*http.Client // client of each connection options
+	records []Record                     // records not yet flushed to the sink
+	summary *summary
+	tls     tlsCounter
+}
+
+// newWorker returns a worker with index id, accumulating its records
+// in time buckets of bucketInterval.
+func newWorker(id int, bucketInterval time.Duration) *worker {
+	return &worker{
+		id:      id,
+		clients: map[connOptions]*http.Client{},
+		records: make([]Record, 0, recordsBufferSize),
+		summary: newSummary(bucketInterval),
+	}
+}
+
+// workers hands out the workers of a run: a worker is acquired at the start
+// of an iteration and released at its end, so that concurrent iterations
+// never share the same worker, nor its index. Released workers are reused
+// before new ones are created.
+type workers struct {
+	free           chan *worker
+	bucketInterval time.Duration
+
+	mu  sync.Mutex
+	all []*worker // all workers created, indexed by id
+}
+
+// newWorkers returns workers for at most numWorker concurrent iterations,
+// accumulating their records in time buckets of bucketInterval.
+func newWorkers(numWorker int, bucketInterval time.Duration) *workers {
+	if numWorker < 1 {
+		numWorker = 1
+	}
+	return &workers{free: make(chan *worker, numWorker), bucketInterval: bucketInterval}
+}
+
+// acquire returns an available worker, creating it if none is.
+func (ws *workers) acquire() *worker {
+	select {
+	case w := <-ws.free:
+		return w
+	default:
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	w := newWorker(len(ws.all), ws.bucketInterval)
+	ws.all = append(ws.all, w)
+	return w
+}
+
+// release makes w available again. It is dropped if more workers than
+// the maximum number of concurrent iterations are available, which does
+// not occur as long as the maximum is respected.
+func (ws *workers) release(w *worker) {
+	select {
+	case ws.free <- w:
+	default:
+	}
+}
+
+// list returns all workers created, ordered by index. It must not be
+// called while iterations are running.
+func (ws *workers) list() []*worker {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.all
+}